@@ -0,0 +1,79 @@
+package citygraph
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph/geojson"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+// AnnouncementType identifies announcement vertices: short, time-limited
+// notices such as service alerts that the live feed carries ahead of other
+// content.
+var AnnouncementType = &pb.Identifier{Value: "announcement"}
+
+// Severity is how urgent an announcement is.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+func (s Severity) valid() bool {
+	switch s {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return true
+	}
+	return false
+}
+
+// Announcement is a notice carried by the live feed between Start and End.
+type Announcement struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"display_name"`
+	BodyHTML string   `json:"body_html"`
+	Link     string   `json:"link,omitempty"`
+	Severity Severity `json:"severity"`
+	// Start is when the announcement goes out. If it's zero, it goes out as
+	// soon as it's written.
+	Start time.Time `json:"start"`
+	// End, if set, is when the announcement expires.
+	End time.Time `json:"end"`
+	// Geometry, if set, is the area the announcement concerns.
+	Geometry *geojson.Geometry `json:"geometry,omitempty"`
+}
+
+func (a *Announcement) UUID() (uuid.UUID, error) {
+	id, err := uuid.Parse(a.ID)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return id, nil
+}
+
+func (a *Announcement) VertexQuery() (*pb.VertexQuery, error) {
+	id, err := a.UUID()
+	if err != nil {
+		return nil, err
+	}
+	return NewSpecificVertexQuery(UUID(id)), nil
+}
+
+// Started reports whether the announcement has gone out by now.
+func (a *Announcement) Started(now time.Time) bool {
+	return !now.Before(a.Start)
+}
+
+// Expired reports whether the announcement has ended by now.
+func (a *Announcement) Expired(now time.Time) bool {
+	return !a.End.IsZero() && !now.Before(a.End)
+}
+
+// Active reports whether the announcement should be in the feed at now.
+func (a *Announcement) Active(now time.Time) bool {
+	return a.Started(now) && !a.Expired(now)
+}
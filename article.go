@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,6 +13,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/geomodulus/citygraph/pb"
+	"github.com/geomodulus/citygraph/slug"
 )
 
 var (
@@ -51,33 +51,39 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 }
 
 type Article struct {
-	LoadedFrom   string                 `json:"-"`
-	ID           string                 `json:"id"`
-	Name         string                 `json:"display_name"`
-	PastNames    []string               `json:"past_names"`
-	Headline     string                 `json:"headline_html,omitempty"`
-	Description  string                 `json:"h2"`
-	FeatureImage string                 `json:"img_url"`
-	IsLive       bool                   `json:"is_live"`
-	Categories   []string               `json:"categories"`
-	Authors      []string               `json:"authors"`
-	Pitch        float64                `json:"pitch,omitempty"`
-	Camera       map[string]interface{} `json:"camera,omitempty"`
-	Promo        string                 `json:"promo,omitempty"`
-	PubDate      string                 `json:"pub_date"`
-	LastUpdated  string                 `json:"last_updated,omitempty"`
-	CodeCredit   string                 `json:"code_credit"`
-	PromoAt      time.Time              `json:"promo_start"`
-	PromoUntil   time.Time              `json:"promo_until"`
-	PromoWait    Duration               `json:"promo_wait"`
-	PromoExpires time.Time              `json:"promo_expires,omitempty"`
-	Slug         string                 `json:"slug,omitempty"`
+	LoadedFrom   string    `json:"-"`
+	ID           string    `json:"id"`
+	Name         string    `json:"display_name"`
+	PastNames    []string  `json:"past_names"`
+	Headline     string    `json:"headline_html,omitempty"`
+	Description  string    `json:"h2"`
+	FeatureImage string    `json:"img_url"`
+	IsLive       bool      `json:"is_live"`
+	Categories   []string  `json:"categories"`
+	Authors      []string  `json:"authors"`
+	Pitch        float64   `json:"pitch,omitempty"`
+	Camera       *Camera   `json:"camera,omitempty"`
+	Promo        string    `json:"promo,omitempty"`
+	PubDate      Date      `json:"pub_date"`
+	LastUpdated  Date      `json:"last_updated,omitempty"`
+	CodeCredit   string    `json:"code_credit"`
+	PromoAt      time.Time `json:"promo_start"`
+	PromoUntil   time.Time `json:"promo_until"`
+	PromoWait    Duration  `json:"promo_wait"`
+	PromoExpires time.Time `json:"promo_expires,omitempty"`
+	Slug         string    `json:"slug,omitempty"`
 	//	Loc             citygraph.LngLat       `json:"loc,omitempty"`
 	//	Zoom            float64                `json:"zoom,omitempty"`
 	Related         []string               `json:"related,omitempty"`
 	GeoJSONDatasets []*GeoJSONDataset      `json:"geojson_datasets,omitempty"`
 	Teaser          map[string]interface{} `json:"teaser,omitempty"`
 	Format          string                 `json:"format,omitempty"`
+
+	// Body, JSFunc and TeaserJSFunc are read from separate files when an
+	// article bundle is loaded with LoadArticle.
+	Body         string `json:"-"`
+	JSFunc       string `json:"-"`
+	TeaserJSFunc string `json:"-"`
 }
 
 func (a *Article) UUID() (uuid.UUID, error) {
@@ -112,29 +118,21 @@ func (a *Article) LoadBodyText() (string, error) {
 	return string(body), nil
 }
 
-func makeSlugTitle(title string) string {
-	titleParts := strings.Split(title, " ")
-	if len(titleParts) > 5 {
-		titleParts = titleParts[:5]
-	}
-	cutAndMashed := strings.ToLower(strings.Join(titleParts, "-"))
-	sanitized := strings.TrimRight(
-		url.PathEscape(punctRE.ReplaceAllString(cutAndMashed, "")),
-		".",
-	)
-
-	return sanitized
-}
-
 func (a *Article) SlugTitle() string {
-	return makeSlugTitle(a.Name)
+	return slug.Title(a.Name)
 }
 
 // AllSlugTitles includes past slug titles form former headlines that may still be out there as
-// valid links.
+// valid links, along with the slugs the older ASCII-only rules made from each headline.
 func (a *Article) AllSlugTitles() (slugs []string) {
+	seen := map[string]bool{}
 	for _, name := range append(a.PastNames, a.Name) {
-		slugs = append(slugs, makeSlugTitle(name))
+		for _, s := range []string{slug.Legacy(name), slug.Title(name)} {
+			if !seen[s] {
+				seen[s] = true
+				slugs = append(slugs, s)
+			}
+		}
 	}
 	return
 }
@@ -147,6 +145,52 @@ func (a *Article) Path() (string, error) {
 	return fmt.Sprintf("/articles/%s/%s", slugID, a.SlugTitle()), nil
 }
 
+// ArticlePathPrefix begins the path of every article page.
+const ArticlePathPrefix = "/articles/"
+
+// ParseArticlePath splits an article path built by Path into its slug ID and
+// slug title. The slug title may be empty if the path ends after the slug ID.
+func ParseArticlePath(path string) (slugID, slugTitle string, err error) {
+	rest, ok := strings.CutPrefix(path, ArticlePathPrefix)
+	if !ok {
+		return "", "", fmt.Errorf("%q is not an article path", path)
+	}
+	parts := strings.Split(strings.TrimSuffix(rest, "/"), "/")
+	if len(parts) > 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("%q is not an article path", path)
+	}
+	if len(parts) == 2 {
+		slugTitle = parts[1]
+	}
+	return parts[0], slugTitle, nil
+}
+
+// ParseSlugID decodes a slug ID made by SlugID back into the UUID it encodes.
+func ParseSlugID(slugID string) (uuid.UUID, error) {
+	if len(slugID) != 22 {
+		return uuid.UUID{}, fmt.Errorf("slug id %q: want 22 characters", slugID)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(slugID)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("slug id %q: %v", slugID, err)
+	}
+	return uuid.FromBytes(b)
+}
+
+// CameraAt returns the camera options for a breakpoint, falling back to the
+// article's Pitch when the camera doesn't set one.
+func (a *Article) CameraAt(breakpoint string) CameraOptions {
+	var opts CameraOptions
+	if a.Camera != nil {
+		opts = a.Camera.At(breakpoint)
+	}
+	if opts.Pitch == nil && a.Pitch != 0 {
+		pitch := a.Pitch
+		opts.Pitch = &pitch
+	}
+	return opts
+}
+
 func (a *Article) VertexQuery() (*pb.VertexQuery, error) {
 	id, err := a.UUID()
 	if err != nil {
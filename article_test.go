@@ -0,0 +1,60 @@
+package citygraph
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseArticlePath(t *testing.T) {
+	article := &Article{ID: NewID().String(), Name: "Bike lanes come to Bloor"}
+	path, err := article.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slugID, slugTitle, err := ParseArticlePath(path)
+	if err != nil {
+		t.Fatalf("ParseArticlePath(%q) returned err: %v", path, err)
+	}
+	if slugTitle != article.SlugTitle() {
+		t.Errorf("ParseArticlePath(%q) slug title = %q, want %q", path, slugTitle, article.SlugTitle())
+	}
+	id, err := ParseSlugID(slugID)
+	if err != nil {
+		t.Fatalf("ParseSlugID(%q) returned err: %v", slugID, err)
+	}
+	if id.String() != article.ID {
+		t.Errorf("ParseSlugID(%q) = %s, want %s", slugID, id, article.ID)
+	}
+
+	for _, bad := range []string{"/modules/abc/def", "/articles/", "/articles/a/b/c"} {
+		if _, _, err := ParseArticlePath(bad); err == nil {
+			t.Errorf("ParseArticlePath(%q) returned no error", bad)
+		}
+	}
+	if _, err := ParseSlugID("too-short"); err == nil {
+		t.Errorf("ParseSlugID() accepted a short slug id")
+	}
+}
+
+func TestAllSlugTitles(t *testing.T) {
+	article := &Article{Name: "Café Landwer opens", PastNames: []string{"Landwer opens"}}
+	want := []string{"landwer-opens", "caf-landwer-opens", "cafe-landwer-opens"}
+	if diff := cmp.Diff(want, article.AllSlugTitles()); diff != "" {
+		t.Errorf("AllSlugTitles() diff:\n%s\n", diff)
+	}
+}
+
+func TestModuleAndPlaceAllSlugTitles(t *testing.T) {
+	module := &Module{Name: "Café Landwer map"}
+	if diff := cmp.Diff([]string{"caf-landwer-map", "cafe-landwer-map"}, module.AllSlugTitles()); diff != "" {
+		t.Errorf("Module.AllSlugTitles() diff:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]string{"ward-map"}, (&Module{Name: "Ward map"}).AllSlugTitles()); diff != "" {
+		t.Errorf("Module.AllSlugTitles() of an ASCII name diff:\n%s\n", diff)
+	}
+	place := &Place{Name: "Café Landwer Queen West", ShortName: "Café Landwer Inc."}
+	if diff := cmp.Diff([]string{"caf-landwer-inc.", "cafe-landwer-inc"}, place.AllSlugTitles()); diff != "" {
+		t.Errorf("Place.AllSlugTitles() diff:\n%s\n", diff)
+	}
+}
@@ -0,0 +1,216 @@
+package citygraph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Files making up an article bundle directory. Only the metadata and body
+// are required.
+const (
+	ArticleMetadataFile = "article.json"
+	ArticleBodyFile     = "article.html"
+	ArticleJSFile       = "article.js"
+)
+
+// Files making up a module bundle directory. Only the metadata and body are
+// required.
+const (
+	ModuleMetadataFile = "module.json"
+	ModuleBodyFile     = "module.html"
+	ModuleJSFile       = "module.js"
+)
+
+// Optional files shared by article and module bundles. GeoJSON datasets are
+// read from a file named after the dataset's ID with the GeoJSON extension.
+const (
+	TeaserGeoJSONFile = "teaser.geojson"
+	TeaserJSFile      = "teaser.js"
+	GeoJSONExtension  = ".geojson"
+)
+
+// LoadArticle reads the article bundle in dir: its JSON metadata, HTML body,
+// optional JavaScript function, teaser files and GeoJSON dataset files.
+func LoadArticle(dir string) (*Article, error) {
+	article := &Article{}
+	if err := readJSONFile(filepath.Join(dir, ArticleMetadataFile), article); err != nil {
+		return nil, err
+	}
+	article.LoadedFrom = dir
+
+	var err error
+	if article.Body, err = readFile(filepath.Join(dir, ArticleBodyFile)); err != nil {
+		return nil, err
+	}
+	if article.JSFunc, err = readOptionalFile(filepath.Join(dir, ArticleJSFile)); err != nil {
+		return nil, err
+	}
+	if article.TeaserJSFunc, err = readOptionalFile(filepath.Join(dir, TeaserJSFile)); err != nil {
+		return nil, err
+	}
+	if err := readOptionalJSONFile(filepath.Join(dir, TeaserGeoJSONFile), &article.Teaser); err != nil {
+		return nil, err
+	}
+	for _, dataset := range article.GeoJSONDatasets {
+		if err := loadDatasetData(dir, dataset); err != nil {
+			return nil, err
+		}
+	}
+	return article, nil
+}
+
+// LoadArticles loads every article bundle in the immediate subdirectories of
+// root, in directory name order. Subdirectories without article metadata are
+// skipped. Bundles that fail to load are reported together in the returned
+// error, alongside the articles that loaded.
+func LoadArticles(root string) ([]*Article, error) {
+	dirs, err := bundleDirs(root, ArticleMetadataFile)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		articles []*Article
+		errs     []error
+	)
+	for _, dir := range dirs {
+		article, err := LoadArticle(dir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		articles = append(articles, article)
+	}
+	return articles, errors.Join(errs...)
+}
+
+// LoadModule reads the module bundle in dir: its JSON metadata, HTML body,
+// optional JavaScript function and teaser files.
+func LoadModule(dir string) (*Module, error) {
+	module := &Module{}
+	if err := readJSONFile(filepath.Join(dir, ModuleMetadataFile), module); err != nil {
+		return nil, err
+	}
+	module.LoadedFrom = dir
+
+	var err error
+	if module.Body, err = readFile(filepath.Join(dir, ModuleBodyFile)); err != nil {
+		return nil, err
+	}
+	if module.JSFunc, err = readOptionalFile(filepath.Join(dir, ModuleJSFile)); err != nil {
+		return nil, err
+	}
+	if module.TeaserJSFunc, err = readOptionalFile(filepath.Join(dir, TeaserJSFile)); err != nil {
+		return nil, err
+	}
+	if err := readOptionalJSONFile(filepath.Join(dir, TeaserGeoJSONFile), &module.Teaser); err != nil {
+		return nil, err
+	}
+	return module, nil
+}
+
+// LoadModules loads every module bundle in the immediate subdirectories of
+// root, in the same way as LoadArticles.
+func LoadModules(root string) ([]*Module, error) {
+	dirs, err := bundleDirs(root, ModuleMetadataFile)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		modules []*Module
+		errs    []error
+	)
+	for _, dir := range dirs {
+		module, err := LoadModule(dir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		modules = append(modules, module)
+	}
+	return modules, errors.Join(errs...)
+}
+
+func loadDatasetData(dir string, dataset *GeoJSONDataset) error {
+	if dataset.ID == "" {
+		return nil
+	}
+	path := filepath.Join(dir, dataset.ID+GeoJSONExtension)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("%s: invalid JSON", path)
+	}
+	dataset.Data = data
+	return nil
+}
+
+// bundleDirs returns the subdirectories of root that contain metadataFile.
+func bundleDirs(root, metadataFile string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, metadataFile)); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+func readFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// readOptionalFile returns the contents of path, or "" if it doesn't exist.
+func readOptionalFile(path string) (string, error) {
+	s, err := readFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	return s, err
+}
+
+func readJSONFile(path string, v interface{}) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// readOptionalJSONFile decodes path into v, leaving v untouched if the file
+// doesn't exist.
+func readOptionalJSONFile(path string, v interface{}) error {
+	err := readJSONFile(path, v)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package citygraph
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeBundle(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadArticle(t *testing.T) {
+	datasetID := NewID().String()
+	dir := filepath.Join(t.TempDir(), "some-article")
+	writeBundle(t, dir, map[string]string{
+		ArticleMetadataFile: `{
+			"id": "` + NewID().String() + `",
+			"display_name": "Some headline",
+			"geojson_datasets": [{"id": "` + datasetID + `", "name": "Bike lanes"}]
+		}`,
+		ArticleBodyFile:              "<p>Body</p>",
+		ArticleJSFile:                "function() {}",
+		TeaserGeoJSONFile:            `{"type": "FeatureCollection", "features": []}`,
+		datasetID + GeoJSONExtension: `{"type": "FeatureCollection", "features": []}`,
+	})
+
+	article, err := LoadArticle(dir)
+	if err != nil {
+		t.Fatalf("LoadArticle() returned err: %v", err)
+	}
+	if article.LoadedFrom != dir || article.Name != "Some headline" {
+		t.Errorf("LoadArticle() metadata = %q, %q", article.LoadedFrom, article.Name)
+	}
+	if article.Body != "<p>Body</p>" || article.JSFunc != "function() {}" || article.TeaserJSFunc != "" {
+		t.Errorf("LoadArticle() files = %q, %q, %q", article.Body, article.JSFunc, article.TeaserJSFunc)
+	}
+	wantTeaser := map[string]interface{}{"type": "FeatureCollection", "features": []interface{}{}}
+	if diff := cmp.Diff(wantTeaser, article.Teaser); diff != "" {
+		t.Errorf("LoadArticle() teaser diff:\n%s", diff)
+	}
+	if got := article.GeoJSONDatasets[0].Data; !json.Valid(got) || len(got) == 0 {
+		t.Errorf("LoadArticle() dataset data = %q", got)
+	}
+}
+
+func TestLoadArticlesReportsFile(t *testing.T) {
+	root := t.TempDir()
+	writeBundle(t, filepath.Join(root, "a-good"), map[string]string{
+		ArticleMetadataFile: `{"id": "` + NewID().String() + `"}`,
+		ArticleBodyFile:     "<p>Body</p>",
+	})
+	writeBundle(t, filepath.Join(root, "b-bad-json"), map[string]string{
+		ArticleMetadataFile: `{"id": `,
+		ArticleBodyFile:     "<p>Body</p>",
+	})
+	writeBundle(t, filepath.Join(root, "c-no-body"), map[string]string{
+		ArticleMetadataFile: `{}`,
+	})
+	writeBundle(t, filepath.Join(root, "d-not-an-article"), map[string]string{
+		"notes.txt": "",
+	})
+
+	articles, err := LoadArticles(root)
+	if len(articles) != 1 {
+		t.Errorf("LoadArticles() loaded %d articles, want 1", len(articles))
+	}
+	if err == nil {
+		t.Fatal("LoadArticles() returned no error")
+	}
+	for _, want := range []string{
+		filepath.Join(root, "b-bad-json", ArticleMetadataFile),
+		filepath.Join(root, "c-no-body", ArticleBodyFile),
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadArticles() error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestLoadModule(t *testing.T) {
+	dir := t.TempDir()
+	writeBundle(t, dir, map[string]string{
+		ModuleMetadataFile: `{"id": "` + NewID().String() + `", "display_name": "Some module"}`,
+		ModuleBodyFile:     "<p>Body</p>",
+		TeaserJSFile:       "function teaser() {}",
+	})
+	module, err := LoadModule(dir)
+	if err != nil {
+		t.Fatalf("LoadModule() returned err: %v", err)
+	}
+	if module.Name != "Some module" || module.Body != "<p>Body</p>" || module.TeaserJSFunc != "function teaser() {}" {
+		t.Errorf("LoadModule() = %+v", module)
+	}
+}
@@ -0,0 +1,329 @@
+package citygraph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Camera ranges accepted by Mapbox GL.
+const (
+	MinZoom  = 0
+	MaxZoom  = 24
+	MaxPitch = 85
+)
+
+// tileSize is the width in pixels of the world at zoom 0 in Mapbox GL.
+const tileSize = 512
+
+// Padding is the space in pixels kept clear around the map's focus. In JSON
+// it may be a single number applied to every side.
+type Padding struct {
+	Top    float64 `json:"top"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+	Right  float64 `json:"right"`
+}
+
+func (p *Padding) UnmarshalJSON(b []byte) error {
+	var all float64
+	if err := json.Unmarshal(b, &all); err == nil {
+		*p = Padding{Top: all, Bottom: all, Left: all, Right: all}
+		return nil
+	}
+	type padding Padding
+	return json.Unmarshal(b, (*padding)(p))
+}
+
+// CameraBreakpoints are the screen size breakpoints a Camera may override
+// its base options at. Other object-valued keys in camera JSON are kept as
+// Extra.
+var CameraBreakpoints = []string{"sm", "md", "lg", "xl", "2xl"}
+
+func isCameraBreakpoint(name string) bool {
+	for _, bp := range CameraBreakpoints {
+		if bp == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CameraOptions positions the map. Unset options are left to the map's
+// defaults, or to the base camera when used as a breakpoint override.
+type CameraOptions struct {
+	Center  *LngLat
+	Zoom    *float64
+	Pitch   *float64
+	Bearing *float64
+	Padding *Padding
+	// Extra holds any other values, such as Mapbox's speed or around, so
+	// they survive a round trip.
+	Extra map[string]json.RawMessage
+}
+
+func (o CameraOptions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.fields())
+}
+
+// fields returns the options keyed by their JSON names.
+func (o *CameraOptions) fields() map[string]interface{} {
+	fields := map[string]interface{}{}
+	for k, raw := range o.Extra {
+		fields[k] = raw
+	}
+	if o.Center != nil {
+		fields["center"] = o.Center
+	}
+	if o.Zoom != nil {
+		fields["zoom"] = o.Zoom
+	}
+	if o.Pitch != nil {
+		fields["pitch"] = o.Pitch
+	}
+	if o.Bearing != nil {
+		fields["bearing"] = o.Bearing
+	}
+	if o.Padding != nil {
+		fields["padding"] = o.Padding
+	}
+	return fields
+}
+
+func (o *CameraOptions) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	*o = CameraOptions{}
+	return o.setFields(fields)
+}
+
+func (o *CameraOptions) setFields(fields map[string]json.RawMessage) error {
+	for k, raw := range fields {
+		var err error
+		switch k {
+		case "center":
+			o.Center = &LngLat{}
+			err = o.Center.unmarshalCenter(raw)
+		case "zoom":
+			err = json.Unmarshal(raw, &o.Zoom)
+		case "pitch":
+			err = json.Unmarshal(raw, &o.Pitch)
+		case "bearing":
+			err = json.Unmarshal(raw, &o.Bearing)
+		case "padding":
+			err = json.Unmarshal(raw, &o.Padding)
+		default:
+			if o.Extra == nil {
+				o.Extra = map[string]json.RawMessage{}
+			}
+			o.Extra[k] = raw
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// merge returns o with any options set in override replacing its own.
+func (o CameraOptions) merge(override *CameraOptions) CameraOptions {
+	if override == nil {
+		return o
+	}
+	if override.Center != nil {
+		o.Center = override.Center
+	}
+	if override.Zoom != nil {
+		o.Zoom = override.Zoom
+	}
+	if override.Pitch != nil {
+		o.Pitch = override.Pitch
+	}
+	if override.Bearing != nil {
+		o.Bearing = override.Bearing
+	}
+	if override.Padding != nil {
+		o.Padding = override.Padding
+	}
+	if len(override.Extra) > 0 {
+		extra := make(map[string]json.RawMessage, len(o.Extra)+len(override.Extra))
+		for k, raw := range o.Extra {
+			extra[k] = raw
+		}
+		for k, raw := range override.Extra {
+			extra[k] = raw
+		}
+		o.Extra = extra
+	}
+	return o
+}
+
+func (o *CameraOptions) validate(v *validator, prefix string) {
+	if o.Center != nil {
+		v.lngLat(prefix+"center", *o.Center)
+	}
+	if o.Zoom != nil && (*o.Zoom < MinZoom || *o.Zoom > MaxZoom) {
+		v.addf(prefix+"zoom", "%g is out of range [%d, %d]", *o.Zoom, MinZoom, MaxZoom)
+	}
+	if o.Pitch != nil && (*o.Pitch < 0 || *o.Pitch > MaxPitch) {
+		v.addf(prefix+"pitch", "%g is out of range [0, %d]", *o.Pitch, MaxPitch)
+	}
+	if o.Bearing != nil && (*o.Bearing < -360 || *o.Bearing > 360) {
+		v.addf(prefix+"bearing", "%g is out of range [-360, 360]", *o.Bearing)
+	}
+	if p := o.Padding; p != nil && (p.Top < 0 || p.Bottom < 0 || p.Left < 0 || p.Right < 0) {
+		v.addf(prefix+"padding", "must not be negative")
+	}
+}
+
+// Camera is the Mapbox camera for an article or module. In JSON the base
+// options sit at the top level alongside per-breakpoint overrides keyed by
+// breakpoint name, eg. {"zoom": 12, "sm": {"zoom": 10.5}}.
+type Camera struct {
+	CameraOptions
+	// Breakpoints overrides the base options at the CameraBreakpoints.
+	Breakpoints map[string]*CameraOptions
+}
+
+// At returns the options used at a breakpoint: the base options with that
+// breakpoint's overrides applied.
+func (c *Camera) At(breakpoint string) CameraOptions {
+	return c.CameraOptions.merge(c.Breakpoints[breakpoint])
+}
+
+// Validate checks the base options and every breakpoint override, returning
+// a *ValidationError listing every problem found.
+func (c *Camera) Validate() error {
+	v := &validator{}
+	c.CameraOptions.validate(v, "")
+	for _, name := range sortedCameraKeys(c.Breakpoints) {
+		if o := c.Breakpoints[name]; o != nil {
+			o.validate(v, name+".")
+		}
+	}
+	return v.err("camera", "")
+}
+
+func (c Camera) MarshalJSON() ([]byte, error) {
+	fields := c.CameraOptions.fields()
+	for name, o := range c.Breakpoints {
+		fields[name] = o
+	}
+	return json.Marshal(fields)
+}
+
+func (c *Camera) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	*c = Camera{}
+	for k, raw := range fields {
+		if !isCameraBreakpoint(k) || len(raw) == 0 || raw[0] != '{' {
+			continue
+		}
+		o := &CameraOptions{}
+		if err := json.Unmarshal(raw, o); err != nil {
+			return fmt.Errorf("camera %s: %w", k, err)
+		}
+		if c.Breakpoints == nil {
+			c.Breakpoints = map[string]*CameraOptions{}
+		}
+		c.Breakpoints[k] = o
+		delete(fields, k)
+	}
+	if err := c.CameraOptions.setFields(fields); err != nil {
+		return fmt.Errorf("camera %w", err)
+	}
+	return nil
+}
+
+// unmarshalCenter accepts a center as either a {"lng", "lat"} object or a
+// [lng, lat] array, as Mapbox does.
+func (l *LngLat) unmarshalCenter(b []byte) error {
+	var pair []float64
+	if err := json.Unmarshal(b, &pair); err == nil {
+		if len(pair) != 2 {
+			return errors.New("want [lng, lat]")
+		}
+		*l = LngLat{Lng: pair[0], Lat: pair[1]}
+		return nil
+	}
+	return json.Unmarshal(b, l)
+}
+
+func sortedCameraKeys(m map[string]*CameraOptions) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FitBBox returns the camera options that fit a GeoJSON bbox, in
+// [west, south, east, north] order with optional altitudes, into a map
+// viewport of width by height pixels with padding kept clear. Zoom is
+// clamped to MaxZoom for a bbox around a single point.
+func FitBBox(bbox []float64, width, height float64, padding Padding) (CameraOptions, error) {
+	var west, south, east, north float64
+	switch len(bbox) {
+	case 4:
+		west, south, east, north = bbox[0], bbox[1], bbox[2], bbox[3]
+	case 6:
+		west, south, east, north = bbox[0], bbox[1], bbox[3], bbox[4]
+	default:
+		return CameraOptions{}, fmt.Errorf("bbox has %d values, want 4 or 6", len(bbox))
+	}
+	if south > north {
+		return CameraOptions{}, fmt.Errorf("bbox south %g is north of %g", south, north)
+	}
+	if east < west {
+		// The bbox crosses the antimeridian.
+		east += 360
+	}
+	innerWidth := width - padding.Left - padding.Right
+	innerHeight := height - padding.Top - padding.Bottom
+	if innerWidth <= 0 || innerHeight <= 0 {
+		return CameraOptions{}, errors.New("padding leaves no room in the viewport")
+	}
+
+	x0, x1 := mercatorX(west), mercatorX(east)
+	y0, y1 := mercatorY(north), mercatorY(south)
+	zoom := float64(MaxZoom)
+	if dx, dy := x1-x0, y1-y0; dx > 0 || dy > 0 {
+		scale := math.Inf(1)
+		if dx > 0 {
+			scale = innerWidth / (dx * tileSize)
+		}
+		if dy > 0 {
+			scale = math.Min(scale, innerHeight/(dy*tileSize))
+		}
+		zoom = math.Max(MinZoom, math.Min(MaxZoom, math.Log2(scale)))
+	}
+
+	lng := unmercatorX((x0 + x1) / 2)
+	if lng > 180 {
+		lng -= 360
+	}
+	center := LngLat{Lng: lng, Lat: unmercatorY((y0 + y1) / 2)}
+	return CameraOptions{Center: &center, Zoom: &zoom, Padding: &padding}, nil
+}
+
+// Web Mercator projection of longitude and latitude onto [0, 1].
+func mercatorX(lng float64) float64 { return (lng + 180) / 360 }
+
+func mercatorY(lat float64) float64 {
+	phi := lat * math.Pi / 180
+	return (1 - math.Log(math.Tan(math.Pi/4+phi/2))/math.Pi) / 2
+}
+
+func unmercatorX(x float64) float64 { return x*360 - 180 }
+
+func unmercatorY(y float64) float64 {
+	return math.Atan(math.Sinh(math.Pi*(1-2*y))) * 180 / math.Pi
+}
@@ -0,0 +1,111 @@
+package citygraph
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCameraJSON(t *testing.T) {
+	in := `{"center": [-79.38, 43.65], "zoom": 12, "pitch": 45, "speed": 0.8, "sm": {"zoom": 10.5, "padding": 20}}`
+	var c Camera
+	if err := json.Unmarshal([]byte(in), &c); err != nil {
+		t.Fatalf("json.Unmarshal() returned err: %v", err)
+	}
+	sm := c.At("sm")
+	if *sm.Zoom != 10.5 || *sm.Pitch != 45 || sm.Center.Lat != 43.65 || sm.Padding.Left != 20 {
+		t.Errorf("Camera.At(sm) = %+v", sm)
+	}
+	if lg := c.At("lg"); *lg.Zoom != 12 || lg.Padding != nil {
+		t.Errorf("Camera.At(lg) = %+v", lg)
+	}
+
+	out, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned err: %v", err)
+	}
+	want := `{"center":{"lng":-79.38,"lat":43.65},"pitch":45,"sm":{"padding":{"top":20,"bottom":20,"left":20,"right":20},"zoom":10.5},"speed":0.8,"zoom":12}`
+	if string(out) != want {
+		t.Errorf("json.Marshal() = %s, want %s", out, want)
+	}
+}
+
+func TestCameraJSONRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name, in, want string
+	}{
+		{
+			name: "array centers in breakpoints",
+			in:   `{"center":[-79.3832,43.6532],"zoom":14.2,"pitch":60,"bearing":-17.6,"sm":{"center":[-79.39,43.65],"zoom":13}}`,
+			want: `{"bearing":-17.6,"center":{"lng":-79.3832,"lat":43.6532},"pitch":60,"sm":{"center":{"lng":-79.39,"lat":43.65},"zoom":13},"zoom":14.2}`,
+		},
+		{
+			name: "unknown options in breakpoints",
+			in:   `{"zoom":12,"speed":0.6,"md":{"zoom":11,"speed":0.4,"curve":1.2}}`,
+			want: `{"md":{"curve":1.2,"speed":0.4,"zoom":11},"speed":0.6,"zoom":12}`,
+		},
+		{
+			name: "objects that aren't breakpoints",
+			in:   `{"center":{"lng":-79.4,"lat":43.7},"around":{"lng":-79.41,"lat":43.71},"padding":{"top":10,"bottom":0,"left":0,"right":0}}`,
+			want: `{"around":{"lng":-79.41,"lat":43.71},"center":{"lng":-79.4,"lat":43.7},"padding":{"top":10,"bottom":0,"left":0,"right":0}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var c Camera
+			if err := json.Unmarshal([]byte(tc.in), &c); err != nil {
+				t.Fatalf("json.Unmarshal() returned err: %v", err)
+			}
+			out, err := json.Marshal(c)
+			if err != nil {
+				t.Fatalf("json.Marshal() returned err: %v", err)
+			}
+			if string(out) != tc.want {
+				t.Errorf("json.Marshal() = %s, want %s", out, tc.want)
+			}
+			var again Camera
+			if err := json.Unmarshal(out, &again); err != nil {
+				t.Fatalf("json.Unmarshal() of marshalled camera returned err: %v", err)
+			}
+			if out2, _ := json.Marshal(again); string(out2) != tc.want {
+				t.Errorf("second round trip = %s, want %s", out2, tc.want)
+			}
+		})
+	}
+}
+
+func TestCameraValidate(t *testing.T) {
+	zoom, pitch := 30.0, 60.0
+	c := &Camera{
+		CameraOptions: CameraOptions{Pitch: &pitch},
+		Breakpoints:   map[string]*CameraOptions{"sm": {Zoom: &zoom}},
+	}
+	article := &Article{ID: NewID().String(), Name: "Some headline", Headline: "<p>Some headline</p>", Camera: c}
+	var verr *ValidationError
+	if err := article.Validate(); !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != "camera.sm.zoom" {
+		t.Errorf("Validate() = %v, want a single camera.sm.zoom error", err)
+	}
+}
+
+func TestFitBBox(t *testing.T) {
+	// Toronto, roughly.
+	bbox := []float64{-79.64, 43.58, -79.11, 43.86}
+	opts, err := FitBBox(bbox, 1024, 768, Padding{Top: 20, Bottom: 20, Left: 20, Right: 20})
+	if err != nil {
+		t.Fatalf("FitBBox() returned err: %v", err)
+	}
+	if math.Abs(opts.Center.Lng+79.375) > 1e-9 || math.Abs(opts.Center.Lat-43.72) > 0.01 {
+		t.Errorf("FitBBox() center = %+v", *opts.Center)
+	}
+	if *opts.Zoom < 10 || *opts.Zoom > 11 {
+		t.Errorf("FitBBox() zoom = %g, want between 10 and 11", *opts.Zoom)
+	}
+
+	point, err := FitBBox([]float64{-79.38, 43.65, -79.38, 43.65}, 1024, 768, Padding{})
+	if err != nil {
+		t.Fatalf("FitBBox() returned err: %v", err)
+	}
+	if *point.Zoom != MaxZoom {
+		t.Errorf("FitBBox() of a point zoom = %g, want %d", *point.Zoom, MaxZoom)
+	}
+}
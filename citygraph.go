@@ -4,7 +4,6 @@ package citygraph
 import (
 	"encoding/json"
 	"fmt"
-	"regexp"
 
 	"github.com/google/uuid"
 
@@ -125,6 +124,3 @@ func MustParseUUIDBytes(id string) []byte {
 	}
 	return b
 }
-
-// punctRE is a regular expression used to remove punctution from slug titles.
-var punctRE = regexp.MustCompile(`([^\w\s-_\.~])`)
@@ -3,6 +3,8 @@ package citygraph
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"math"
 
@@ -31,6 +33,29 @@ type GraphClient interface {
 	NewBulkSender(ctx context.Context) (BulkSender, error)
 }
 
+// ErrCannotDeleteProperties is returned by DeleteVertexProperties for a
+// GraphClient that doesn't implement VertexPropertyDeleter.
+var ErrCannotDeleteProperties = errors.New("graph client can't delete vertex properties")
+
+// VertexPropertyDeleter is implemented by GraphClients that can delete vertex
+// properties. It isn't part of GraphClient so that implementations written
+// before it existed still satisfy GraphClient; Client and the graphtest
+// clients implement it.
+type VertexPropertyDeleter interface {
+	DeleteVertexProperties(context.Context, *pb.VertexQuery, string) error
+}
+
+// DeleteVertexProperties deletes the named property from the vertices matched
+// by query, or returns ErrCannotDeleteProperties if graph doesn't implement
+// VertexPropertyDeleter.
+func DeleteVertexProperties(ctx context.Context, graph GraphClient, query *pb.VertexQuery, name string) error {
+	d, ok := graph.(VertexPropertyDeleter)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrCannotDeleteProperties, graph)
+	}
+	return d.DeleteVertexProperties(ctx, query, name)
+}
+
 // NewSpecificVertexQuery returns a new query for the provided UUIDs.
 func NewSpecificVertexQuery(ids ...*pb.Uuid) *pb.VertexQuery {
 	return &pb.VertexQuery{
@@ -108,6 +133,18 @@ func NewPipeEdgeQueryLimitOffset(inner *pb.VertexQuery, dir pb.EdgeDirection, t
 	}
 }
 
+// NewRangeVertexQuery returns a new query for every vertex of type t.
+func NewRangeVertexQuery(t *pb.Identifier) *pb.VertexQuery {
+	return &pb.VertexQuery{
+		Query: &pb.VertexQuery_Range{
+			Range: &pb.RangeVertexQuery{
+				Limit: math.MaxInt32,
+				T:     t,
+			},
+		},
+	}
+}
+
 // NewPipeVertexQuery returns a new query for the provided edge keys.
 func NewPipeVertexQuery(inner *pb.EdgeQuery, dir pb.EdgeDirection, t *pb.Identifier) *pb.VertexQuery {
 	return &pb.VertexQuery{
@@ -226,6 +263,11 @@ func (c *Client) SetVertexProperties(ctx context.Context, query *pb.VertexQuery,
 	return err
 }
 
+func (c *Client) DeleteVertexProperties(ctx context.Context, query *pb.VertexQuery, name string) error {
+	_, err := c.graph.DeleteVertexProperties(ctx, NewVertexPropertyQuery(query, name))
+	return err
+}
+
 func (c *Client) GetAllVertexProperties(ctx context.Context, query *pb.VertexQuery) ([]*pb.VertexProperties, error) {
 	stream, err := c.graph.GetAllVertexProperties(ctx, query)
 	if err != nil {
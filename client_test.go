@@ -2,6 +2,7 @@ package citygraph
 
 import (
 	"context"
+	"errors"
 	//	"fmt"
 	//	"io"
 	"log"
@@ -34,6 +35,7 @@ type fakeGraphServer struct {
 	getVertexPropertiesNames    []string
 	getVertexPropertiesResps    [][]*pb.VertexProperty
 	setVertexPropertiesReqs     []*pb.SetVertexPropertiesRequest
+	deleteVertexPropertiesReqs  []*pb.VertexPropertyQuery
 	getAllVertexPropertiesReqs  []*pb.VertexQuery
 	getAllVertexPropertiesResps [][]*pb.VertexProperties
 
@@ -97,6 +99,11 @@ func (f *fakeGraphServer) SetVertexProperties(ctx context.Context, req *pb.SetVe
 	return &emptypb.Empty{}, nil
 }
 
+func (f *fakeGraphServer) DeleteVertexProperties(ctx context.Context, q *pb.VertexPropertyQuery) (*emptypb.Empty, error) {
+	f.deleteVertexPropertiesReqs = append(f.deleteVertexPropertiesReqs, q)
+	return &emptypb.Empty{}, nil
+}
+
 func (f *fakeGraphServer) GetAllVertexProperties(q *pb.VertexQuery, stream pb.IndraDB_GetAllVertexPropertiesServer) error {
 	f.getAllVertexPropertiesReqs = append(f.getAllVertexPropertiesReqs, q)
 	vertexProperties, remaining := f.getAllVertexPropertiesResps[0], f.getAllVertexPropertiesResps[1:]
@@ -366,6 +373,41 @@ func TestSetVertexProperties(t *testing.T) {
 	}
 }
 
+func TestDeleteVertexProperties(t *testing.T) {
+	fakeServer := &fakeGraphServer{
+		UnimplementedIndraDBServer: pb.UnimplementedIndraDBServer{},
+	}
+
+	lis := bufconn.Listen(bufSize)
+	s := newServer(lis, fakeServer)
+	defer s.Stop()
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(bufDialer(lis)), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := NewClient(conn)
+
+	query := NewSpecificVertexQuery(&pb.Uuid{Value: []byte("vertex-a")})
+	if err := DeleteVertexProperties(ctx, client, query, "some-property"); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(
+		[]*pb.VertexPropertyQuery{NewVertexPropertyQuery(query, "some-property")},
+		fakeServer.deleteVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("DeleteVertexProperties() sent req diff:\n%s\n", diff)
+	}
+
+	// GraphClients from before VertexPropertyDeleter still work, but can't
+	// delete properties.
+	var older struct{ GraphClient }
+	if err := DeleteVertexProperties(ctx, older, query, "some-property"); !errors.Is(err, ErrCannotDeleteProperties) {
+		t.Errorf("DeleteVertexProperties() on a client without it returned %v, want ErrCannotDeleteProperties", err)
+	}
+}
+
 func TestGetAllVertexProperties(t *testing.T) {
 	wantVertexProperties := []*pb.VertexProperties{{
 		Vertex: &pb.Vertex{
@@ -0,0 +1,82 @@
+package citygraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	_ "time/tzdata" // Toronto must load on hosts without a zoneinfo database.
+)
+
+// TorontoTime is the time zone dates without an explicit offset are read in.
+var TorontoTime = mustLoadLocation("America/Toronto")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// DateLayouts lists the formats accepted for publication and update dates.
+// Layouts without a UTC offset are read in Toronto time, so a bare date is
+// midnight in Toronto.
+var DateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Date is a publication or update time. It's read from JSON in any of the
+// DateLayouts and always written as RFC 3339, or as "" when unset.
+type Date struct {
+	time.Time
+}
+
+// ParseDate parses s in the first of the DateLayouts it matches.
+func ParseDate(s string) (Date, error) {
+	for _, layout := range DateLayouts {
+		if t, err := time.ParseInLocation(layout, s, TorontoTime); err == nil {
+			return Date{t}, nil
+		}
+	}
+	return Date{}, fmt.Errorf("%q is not a recognized date", s)
+}
+
+// MustParseDate is like ParseDate but panics if s can't be parsed.
+func MustParseDate(s string) Date {
+	d, err := ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// String returns the date in RFC 3339 format, or "" if it's unset.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(time.RFC3339)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s *string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == nil || *s == "" {
+		*d = Date{}
+		return nil
+	}
+	var err error
+	*d, err = ParseDate(*s)
+	return err
+}
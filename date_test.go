@@ -0,0 +1,37 @@
+package citygraph
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDateJSON(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{`"2022-06-14"`, `"2022-06-14T00:00:00-04:00"`},
+		{`"2022-01-14"`, `"2022-01-14T00:00:00-05:00"`},
+		{`"2022-06-14 09:30"`, `"2022-06-14T09:30:00-04:00"`},
+		{`"2022-09-01T10:30:00-05:00"`, `"2022-09-01T10:30:00-05:00"`},
+		{`""`, `""`},
+		{`null`, `""`},
+	} {
+		var d Date
+		if err := json.Unmarshal([]byte(tc.in), &d); err != nil {
+			t.Errorf("json.Unmarshal(%s) returned err: %v", tc.in, err)
+			continue
+		}
+		got, err := json.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("json.Marshal(json.Unmarshal(%s)) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+
+	var d Date
+	if err := json.Unmarshal([]byte(`"June 14th"`), &d); err == nil {
+		t.Errorf("json.Unmarshal() accepted an unrecognized date")
+	}
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+// AnnouncementFeed is the part of feedproducer.FeedClient used to carry
+// announcements in the live feed.
+type AnnouncementFeed interface {
+	AddAnnouncement(ctx context.Context, id uuid.UUID) error
+	RemoveContent(ctx context.Context, id uuid.UUID) error
+}
+
+// WriteAnnouncement writes the announcement's vertex and properties.
+func (s *Store) WriteAnnouncement(ctx context.Context, a *citygraph.Announcement) error {
+	if err := s.validate(a); err != nil {
+		return err
+	}
+	id, err := a.UUID()
+	if err != nil {
+		return err
+	}
+	if err := s.CreateVertex(ctx, citygraph.UUID(id), citygraph.AnnouncementType); err != nil {
+		return err
+	}
+	q, err := a.VertexQuery()
+	if err != nil {
+		return err
+	}
+	props := []vertexProperty{
+		{citygraph.PropertyNameDisplayName, a.Title},
+		{"body_html", a.BodyHTML},
+		{"link", a.Link},
+		{"severity", a.Severity},
+		{"start", a.Start},
+		{"end", a.End},
+	}
+	if a.Geometry != nil {
+		props = append(props, vertexProperty{"geometry", a.Geometry})
+	}
+	for _, prop := range props {
+		if err := s.SetVertexProperties(ctx, q, prop.name, prop.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishAnnouncement writes the announcement and, if it's active at now,
+// adds it to the feed. Announcements that start later are added by
+// SyncAnnouncements.
+func (s *Store) PublishAnnouncement(ctx context.Context, a *citygraph.Announcement, feed AnnouncementFeed, now time.Time) error {
+	if err := s.WriteAnnouncement(ctx, a); err != nil {
+		return err
+	}
+	if !a.Active(now) {
+		return nil
+	}
+	id, err := a.UUID()
+	if err != nil {
+		return err
+	}
+	if err := feed.AddAnnouncement(ctx, id); err != nil {
+		return fmt.Errorf("add announcement %s: %w", id, err)
+	}
+	return nil
+}
+
+// ReadAnnouncements returns every announcement in the graph.
+func (s *Store) ReadAnnouncements(ctx context.Context) ([]*citygraph.Announcement, error) {
+	all, err := s.GetAllVertexProperties(ctx, citygraph.NewRangeVertexQuery(citygraph.AnnouncementType))
+	if err != nil {
+		return nil, err
+	}
+	var announcements []*citygraph.Announcement
+	for _, v := range all {
+		id, err := uuid.FromBytes(v.GetVertex().GetId().GetValue())
+		if err != nil {
+			return nil, err
+		}
+		a := &citygraph.Announcement{ID: id.String()}
+		fields := map[string]interface{}{
+			citygraph.PropertyNameDisplayName: &a.Title,
+			"body_html":                       &a.BodyHTML,
+			"link":                            &a.Link,
+			"severity":                        &a.Severity,
+			"start":                           &a.Start,
+			"end":                             &a.End,
+			"geometry":                        &a.Geometry,
+		}
+		for _, prop := range v.Props {
+			field, ok := fields[prop.Name.GetValue()]
+			if !ok {
+				continue
+			}
+			if err := json.Unmarshal([]byte(prop.Value.GetValue()), field); err != nil {
+				return nil, fmt.Errorf("announcement %s: %s: %w", id, prop.Name.GetValue(), err)
+			}
+		}
+		announcements = append(announcements, a)
+	}
+	return announcements, nil
+}
+
+// AnnouncementReport lists what SyncAnnouncements did.
+type AnnouncementReport struct {
+	// Added holds the announcements added to the feed.
+	Added []uuid.UUID
+	// Pending holds the announcements that haven't started yet.
+	Pending []uuid.UUID
+	// Expired holds the announcements removed from the feed and the graph.
+	Expired []uuid.UUID
+}
+
+// SyncAnnouncements brings the feed in line with the announcements in the
+// graph at now: active announcements are added to the feed, which ignores
+// ones it already has, and expired announcements are removed from the feed
+// and their vertices deleted. Run it periodically so announcements start and
+// end on time.
+func (s *Store) SyncAnnouncements(ctx context.Context, feed AnnouncementFeed, now time.Time) (*AnnouncementReport, error) {
+	announcements, err := s.ReadAnnouncements(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &AnnouncementReport{}
+	var expired []*pb.Uuid
+	for _, a := range announcements {
+		id, err := a.UUID()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case a.Expired(now):
+			if err := feed.RemoveContent(ctx, id); err != nil {
+				return nil, fmt.Errorf("remove announcement %s: %w", id, err)
+			}
+			expired = append(expired, citygraph.UUID(id))
+			report.Expired = append(report.Expired, id)
+		case a.Started(now):
+			if err := feed.AddAnnouncement(ctx, id); err != nil {
+				return nil, fmt.Errorf("add announcement %s: %w", id, err)
+			}
+			report.Added = append(report.Added, id)
+		default:
+			report.Pending = append(report.Pending, id)
+		}
+	}
+	if len(expired) > 0 {
+		if err := s.DeleteVertices(ctx, citygraph.NewSpecificVertexQuery(expired...)); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
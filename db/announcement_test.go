@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/geomodulus/citygraph"
+	feedproducer "github.com/geomodulus/citygraph/feed_producer"
+	"github.com/geomodulus/citygraph/graphtest"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+func announcementVertex(t *testing.T, a *citygraph.Announcement) *pb.VertexProperties {
+	t.Helper()
+	id, err := a.UUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &pb.VertexProperties{Vertex: &pb.Vertex{Id: citygraph.UUID(id), T: citygraph.AnnouncementType}}
+	for name, value := range map[string]interface{}{
+		citygraph.PropertyNameDisplayName: a.Title,
+		"body_html":                       a.BodyHTML,
+		"severity":                        a.Severity,
+		"start":                           a.Start,
+		"end":                             a.End,
+	} {
+		b, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v.Props = append(v.Props, &pb.NamedProperty{Name: &pb.Identifier{Value: name}, Value: citygraph.Json(b)})
+	}
+	return v
+}
+
+func TestPublishAnnouncement(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	id := citygraph.NewID()
+	a := &citygraph.Announcement{
+		ID:       id.String(),
+		Title:    "Line 1 closed",
+		BodyHTML: "<p>Shuttle buses are running.</p>",
+		Severity: citygraph.SeverityWarning,
+		Start:    now,
+		End:      now.Add(time.Hour),
+	}
+	fakeGraph := &graphtest.FakeGraphClient{}
+	store := &Store{GraphClient: fakeGraph}
+	feed := &feedproducer.FakeFeedClient{}
+
+	if err := store.PublishAnnouncement(context.Background(), a, feed, now); err != nil {
+		t.Fatalf("store.PublishAnnouncement() returned err: %v", err)
+	}
+
+	wantCreateVertexReqs := []*pb.Vertex{{Id: citygraph.UUID(id), T: citygraph.AnnouncementType}}
+	if diff := cmp.Diff(wantCreateVertexReqs, fakeGraph.CreateVertexReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.PublishAnnouncement() sent create vertex req diff:\n%s\n", diff)
+	}
+	var gotProps []string
+	for _, req := range fakeGraph.SetVertexPropertiesReqs {
+		gotProps = append(gotProps, req.GetQ().GetName().GetValue())
+	}
+	wantProps := []string{citygraph.PropertyNameDisplayName, "body_html", "link", "severity", "start", "end"}
+	if diff := cmp.Diff(wantProps, gotProps); diff != "" {
+		t.Errorf("store.PublishAnnouncement() set properties diff:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]uuid.UUID{id}, feed.AddAnnouncementReqs); diff != "" {
+		t.Errorf("store.PublishAnnouncement() added announcements diff:\n%s\n", diff)
+	}
+}
+
+func TestPublishAnnouncementNotStarted(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := &citygraph.Announcement{
+		ID:       citygraph.NewID().String(),
+		Title:    "Line 1 closing this weekend",
+		BodyHTML: "<p>Plan ahead.</p>",
+		Severity: citygraph.SeverityInfo,
+		Start:    now.Add(24 * time.Hour),
+	}
+	store := &Store{GraphClient: &graphtest.FakeGraphClient{}}
+	feed := &feedproducer.FakeFeedClient{}
+
+	if err := store.PublishAnnouncement(context.Background(), a, feed, now); err != nil {
+		t.Fatalf("store.PublishAnnouncement() returned err: %v", err)
+	}
+	if len(feed.AddAnnouncementReqs) != 0 {
+		t.Errorf("store.PublishAnnouncement() added %v before the announcement started", feed.AddAnnouncementReqs)
+	}
+}
+
+func TestWriteAnnouncementRefusesInvalid(t *testing.T) {
+	fakeGraph := &graphtest.FakeGraphClient{}
+	store := &Store{GraphClient: fakeGraph}
+	a := &citygraph.Announcement{ID: citygraph.NewID().String(), Severity: citygraph.SeverityInfo}
+	if err := store.WriteAnnouncement(context.Background(), a); err == nil {
+		t.Fatal("store.WriteAnnouncement() with no title returned nil err")
+	}
+	if len(fakeGraph.CreateVertexReqs) != 0 {
+		t.Errorf("store.WriteAnnouncement() wrote an invalid announcement")
+	}
+}
+
+func TestSyncAnnouncements(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	active := &citygraph.Announcement{
+		ID:       citygraph.NewID().String(),
+		Title:    "Line 1 closed",
+		BodyHTML: "<p>Shuttle buses are running.</p>",
+		Severity: citygraph.SeverityWarning,
+		Start:    now.Add(-time.Hour),
+		End:      now.Add(time.Hour),
+	}
+	pending := &citygraph.Announcement{
+		ID:       citygraph.NewID().String(),
+		Title:    "Line 2 closing",
+		BodyHTML: "<p>Plan ahead.</p>",
+		Severity: citygraph.SeverityInfo,
+		Start:    now.Add(time.Hour),
+	}
+	expired := &citygraph.Announcement{
+		ID:       citygraph.NewID().String(),
+		Title:    "Line 3 delays",
+		BodyHTML: "<p>Service has resumed.</p>",
+		Severity: citygraph.SeverityCritical,
+		Start:    now.Add(-2 * time.Hour),
+		End:      now,
+	}
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetAllVertexPropertiesResps: [][]*pb.VertexProperties{{
+			announcementVertex(t, active),
+			announcementVertex(t, pending),
+			announcementVertex(t, expired),
+		}},
+	}
+	store := &Store{GraphClient: fakeGraph}
+	feed := &feedproducer.FakeFeedClient{}
+
+	report, err := store.SyncAnnouncements(context.Background(), feed, now)
+	if err != nil {
+		t.Fatalf("store.SyncAnnouncements() returned err: %v", err)
+	}
+
+	activeID, _ := active.UUID()
+	pendingID, _ := pending.UUID()
+	expiredID, _ := expired.UUID()
+	wantReport := &AnnouncementReport{
+		Added:   []uuid.UUID{activeID},
+		Pending: []uuid.UUID{pendingID},
+		Expired: []uuid.UUID{expiredID},
+	}
+	if diff := cmp.Diff(wantReport, report); diff != "" {
+		t.Errorf("store.SyncAnnouncements() report diff:\n%s\n", diff)
+	}
+	wantAllPropsReqs := []*pb.VertexQuery{citygraph.NewRangeVertexQuery(citygraph.AnnouncementType)}
+	if diff := cmp.Diff(wantAllPropsReqs, fakeGraph.GetAllVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.SyncAnnouncements() sent get all vertex properties req diff:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]uuid.UUID{activeID}, feed.AddAnnouncementReqs); diff != "" {
+		t.Errorf("store.SyncAnnouncements() added announcements diff:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]uuid.UUID{expiredID}, feed.RemoveContentReqs); diff != "" {
+		t.Errorf("store.SyncAnnouncements() removed content diff:\n%s\n", diff)
+	}
+	wantDeleteVerticesReqs := []*pb.VertexQuery{citygraph.NewSpecificVertexQuery(citygraph.UUID(expiredID))}
+	if diff := cmp.Diff(wantDeleteVerticesReqs, fakeGraph.DeleteVerticesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.SyncAnnouncements() sent delete vertices req diff:\n%s\n", diff)
+	}
+}
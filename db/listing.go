@@ -0,0 +1,397 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/slug"
+)
+
+// PropertyNameLatestArticles identifies the property on the Torontoverse
+// vertex holding the public listing of live articles.
+const PropertyNameLatestArticles = "latest_articles_public"
+
+// DefaultListingPageSize is the number of entries in each page of a listing
+// unless a ListingMaintainer is configured otherwise.
+const DefaultListingPageSize = 20
+
+// NewArticleListing returns the listing entry for an article, or nil if the
+// article should not be listed because it isn't live or has no publication
+// date.
+func NewArticleListing(article *citygraph.Article) (*ArticleListing, error) {
+	if article.PubDate.IsZero() || !article.IsLive {
+		return nil, nil
+	}
+	articlePath, err := article.Path()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get article path: %w", err)
+	}
+	return &ArticleListing{
+		ID:         article.ID,
+		Name:       article.Name,
+		Headline:   article.Headline,
+		Subhead:    article.Description,
+		SlugPath:   articlePath,
+		PubDate:    article.PubDate,
+		UpdatedAt:  article.LastUpdated,
+		Authors:    article.Authors,
+		Categories: article.Categories,
+		ImgURL:     article.FeatureImage,
+	}, nil
+}
+
+// sortKey is the time a listing entry is ordered by: when it was last
+// updated, or when it was published if it never was.
+func (l *ArticleListing) sortKey() time.Time {
+	if !l.UpdatedAt.IsZero() {
+		return l.UpdatedAt.Time
+	}
+	return l.PubDate.Time
+}
+
+// moreRecent reports whether a belongs before b in a listing. This is the
+// only place the listing order is decided.
+func moreRecent(a, b *ArticleListing) bool {
+	if ka, kb := a.sortKey(), b.sortKey(); !ka.Equal(kb) {
+		return ka.After(kb)
+	}
+	return a.ID < b.ID
+}
+
+func sortListings(listings []*ArticleListing) {
+	sort.Slice(listings, func(i, j int) bool { return moreRecent(listings[i], listings[j]) })
+}
+
+const (
+	categoryListingPrefix = "latest_articles_category_"
+	authorListingPrefix   = "latest_articles_author_"
+)
+
+// CategoryListingName returns the name of the Torontoverse property holding
+// the listing for a category.
+func CategoryListingName(category string) string {
+	return categoryListingPrefix + listingKey(category)
+}
+
+// AuthorListingName returns the name of the Torontoverse property holding
+// the listing for an author.
+func AuthorListingName(author string) string {
+	return authorListingPrefix + listingKey(author)
+}
+
+// ListingPageName returns the name of the property holding the given
+// one-based page of a listing.
+func ListingPageName(listing string, page int) string {
+	return fmt.Sprintf("%s_page_%d", listing, page)
+}
+
+// ListingPageCountName returns the name of the property holding the number
+// of pages in a listing. Page properties past this count are stale.
+func ListingPageCountName(listing string) string {
+	return listing + "_pages"
+}
+
+func listingKey(name string) string {
+	return slug.Key(name)
+}
+
+// ListingMaintainer keeps the article listings on the Torontoverse vertex up
+// to date one article at a time: the public listing of all live articles, a
+// listing per category and per author, and page-sized chunks of each.
+type ListingMaintainer struct {
+	store *Store
+	// PageSize is the number of entries in each page of a listing.
+	PageSize int
+}
+
+func NewListingMaintainer(s *Store) *ListingMaintainer {
+	return &ListingMaintainer{store: s, PageSize: DefaultListingPageSize}
+}
+
+// UpdateArticle adds or refreshes the article's entries in every listing it
+// belongs to, or removes them if it should no longer be listed.
+func (m *ListingMaintainer) UpdateArticle(ctx context.Context, article *citygraph.Article) error {
+	listing, err := NewArticleListing(article)
+	if err != nil {
+		return err
+	}
+	if listing == nil {
+		return m.Remove(ctx, article.ID)
+	}
+	return m.Upsert(ctx, listing)
+}
+
+// Upsert inserts or replaces one entry in the public listing and in the
+// listings for its categories and authors, dropping it from any category or
+// author listing it no longer belongs to.
+func (m *ListingMaintainer) Upsert(ctx context.Context, listing *ArticleListing) error {
+	latest, err := m.store.readListings(ctx, PropertyNameLatestArticles)
+	if err != nil {
+		return err
+	}
+	old, latest := withoutListing(latest, listing.ID)
+	if err := m.write(ctx, PropertyNameLatestArticles, append(latest, listing)); err != nil {
+		return err
+	}
+
+	member, affected := map[string]bool{}, map[string]bool{}
+	for _, name := range subListingNames(listing) {
+		member[name] = true
+		affected[name] = true
+	}
+	if old != nil {
+		for _, name := range subListingNames(old) {
+			affected[name] = true
+		}
+	}
+	for _, name := range sortedKeys(affected) {
+		entries, err := m.store.readListings(ctx, name)
+		if err != nil {
+			return err
+		}
+		_, entries = withoutListing(entries, listing.ID)
+		if member[name] {
+			entries = append(entries, listing)
+		}
+		if err := m.write(ctx, name, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove drops an article from every listing it appears in.
+func (m *ListingMaintainer) Remove(ctx context.Context, id string) error {
+	_, err := m.remove(ctx, id, false)
+	return err
+}
+
+// remove drops an article from every listing and returns the names of the
+// listings it was dropped from. On a dry run nothing is written.
+func (m *ListingMaintainer) remove(ctx context.Context, id string, dryRun bool) ([]string, error) {
+	latest, err := m.store.readListings(ctx, PropertyNameLatestArticles)
+	if err != nil {
+		return nil, err
+	}
+	old, latest := withoutListing(latest, id)
+	if old == nil {
+		return nil, nil
+	}
+	removedFrom := []string{PropertyNameLatestArticles}
+	if !dryRun {
+		if err := m.write(ctx, PropertyNameLatestArticles, latest); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range subListingNames(old) {
+		entries, err := m.store.readListings(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		found, entries := withoutListing(entries, id)
+		if found == nil {
+			continue
+		}
+		removedFrom = append(removedFrom, name)
+		if !dryRun {
+			if err := m.write(ctx, name, entries); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return removedFrom, nil
+}
+
+// Rebuild replaces every listing with ones built from the supplied articles.
+// Category and author listings, and any pages, that the articles no longer
+// fill are deleted.
+func (m *ListingMaintainer) Rebuild(ctx context.Context, articles []*citygraph.Article) error {
+	tvq := citygraph.NewSpecificVertexQuery(citygraph.Torontoverse.Id)
+	all, err := m.store.GetAllVertexProperties(ctx, tvq)
+	if err != nil {
+		return err
+	}
+	stale := map[string]bool{}
+	for _, vp := range all {
+		for _, prop := range vp.Props {
+			if name := prop.GetName().GetValue(); isListingProperty(name) {
+				stale[name] = true
+			}
+		}
+	}
+
+	listings := map[string][]*ArticleListing{PropertyNameLatestArticles: {}}
+	for _, article := range articles {
+		listing, err := NewArticleListing(article)
+		if err != nil {
+			return err
+		}
+		if listing == nil {
+			continue
+		}
+		listings[PropertyNameLatestArticles] = append(listings[PropertyNameLatestArticles], listing)
+		for _, name := range subListingNames(listing) {
+			listings[name] = append(listings[name], listing)
+		}
+	}
+	for _, name := range sortedKeys(listings) {
+		pages, err := m.set(ctx, name, listings[name])
+		if err != nil {
+			return err
+		}
+		delete(stale, name)
+		delete(stale, ListingPageCountName(name))
+		for page := 1; page <= pages; page++ {
+			delete(stale, ListingPageName(name, page))
+		}
+	}
+	for _, name := range sortedKeys(stale) {
+		if err := citygraph.DeleteVertexProperties(ctx, m.store.GraphClient, tvq, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write sorts and stores a listing along with its pages and page count,
+// deleting any pages left over from when the listing was longer.
+func (m *ListingMaintainer) write(ctx context.Context, name string, entries []*ArticleListing) error {
+	oldPages, err := m.store.readListingPageCount(ctx, name)
+	if err != nil {
+		return err
+	}
+	pages, err := m.set(ctx, name, entries)
+	if err != nil {
+		return err
+	}
+	tvq := citygraph.NewSpecificVertexQuery(citygraph.Torontoverse.Id)
+	for page := pages + 1; page <= oldPages; page++ {
+		if err := citygraph.DeleteVertexProperties(ctx, m.store.GraphClient, tvq, ListingPageName(name, page)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set sorts and stores a listing along with its pages and page count, and
+// returns the number of pages.
+func (m *ListingMaintainer) set(ctx context.Context, name string, entries []*ArticleListing) (int, error) {
+	if entries == nil {
+		entries = []*ArticleListing{}
+	}
+	sortListings(entries)
+	tvq := citygraph.NewSpecificVertexQuery(citygraph.Torontoverse.Id)
+	if err := m.store.SetVertexProperties(ctx, tvq, name, entries); err != nil {
+		return 0, err
+	}
+	pageSize := m.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultListingPageSize
+	}
+	pages := 0
+	for start := 0; start < len(entries); start += pageSize {
+		end := start + pageSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		pages++
+		if err := m.store.SetVertexProperties(ctx, tvq, ListingPageName(name, pages), entries[start:end]); err != nil {
+			return 0, err
+		}
+	}
+	if err := m.store.SetVertexProperties(ctx, tvq, ListingPageCountName(name), pages); err != nil {
+		return 0, err
+	}
+	return pages, nil
+}
+
+// isListingProperty reports whether a Torontoverse property holds a listing,
+// one of its pages or its page count.
+func isListingProperty(name string) bool {
+	return strings.HasPrefix(name, PropertyNameLatestArticles) ||
+		strings.HasPrefix(name, categoryListingPrefix) ||
+		strings.HasPrefix(name, authorListingPrefix)
+}
+
+// subListingNames returns the category and author listings an entry belongs
+// in, once each: names that differ only in case or accents share a listing.
+func subListingNames(listing *ArticleListing) []string {
+	var names []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, category := range listing.Categories {
+		add(CategoryListingName(category))
+	}
+	for _, author := range listing.Authors {
+		add(AuthorListingName(author))
+	}
+	return names
+}
+
+// withoutListing returns the entry with the given ID, if any, and the
+// remaining entries.
+func withoutListing(listings []*ArticleListing, id string) (*ArticleListing, []*ArticleListing) {
+	var found *ArticleListing
+	remaining := make([]*ArticleListing, 0, len(listings))
+	for _, listing := range listings {
+		if listing.ID == id {
+			found = listing
+			continue
+		}
+		remaining = append(remaining, listing)
+	}
+	return found, remaining
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readListings returns the article listing stored under name on the
+// Torontoverse vertex, or nil if there isn't one.
+func (s *Store) readListings(ctx context.Context, name string) ([]*ArticleListing, error) {
+	props, err := s.GetVertexProperties(ctx, citygraph.NewSpecificVertexQuery(citygraph.Torontoverse.Id), name)
+	if err != nil {
+		return nil, err
+	}
+	if len(props) == 0 {
+		return nil, nil
+	}
+	var listings []*ArticleListing
+	if err := json.Unmarshal([]byte(props[0].Value.GetValue()), &listings); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", name, err)
+	}
+	return listings, nil
+}
+
+// readListingPageCount returns the number of pages stored for the named
+// listing on the Torontoverse vertex, or zero if there are none.
+func (s *Store) readListingPageCount(ctx context.Context, name string) (int, error) {
+	props, err := s.GetVertexProperties(ctx, citygraph.NewSpecificVertexQuery(citygraph.Torontoverse.Id), ListingPageCountName(name))
+	if err != nil {
+		return 0, err
+	}
+	if len(props) == 0 {
+		return 0, nil
+	}
+	var pages int
+	if err := json.Unmarshal([]byte(props[0].Value.GetValue()), &pages); err != nil {
+		return 0, fmt.Errorf("decode %s: %w", ListingPageCountName(name), err)
+	}
+	return pages, nil
+}
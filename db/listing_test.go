@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/graphtest"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+// writtenListings decodes the listings written to the fake graph, keyed by
+// property name. Later writes to the same property replace earlier ones.
+func writtenListings(t *testing.T, fake *graphtest.FakeGraphClient) map[string][]string {
+	t.Helper()
+	got := map[string][]string{}
+	for _, req := range fake.SetVertexPropertiesReqs {
+		var listings []*ArticleListing
+		if err := json.Unmarshal([]byte(req.Value.Value), &listings); err != nil {
+			continue
+		}
+		ids := []string{}
+		for _, l := range listings {
+			ids = append(ids, l.ID)
+		}
+		got[req.Q.Name.Value] = ids
+	}
+	return got
+}
+
+func TestMoreRecent(t *testing.T) {
+	listings := []*ArticleListing{
+		{ID: "published-early", PubDate: citygraph.MustParseDate("2022-01-01")},
+		{ID: "updated-late", PubDate: citygraph.MustParseDate("2021-06-01"), UpdatedAt: citygraph.MustParseDate("2022-09-01T10:30:00-05:00")},
+		{ID: "published-mid", PubDate: citygraph.MustParseDate("2022-03-01")},
+		// Lexically earlier than 2022-03-01, but 07:30 UTC is after midnight
+		// in Toronto.
+		{ID: "published-pacific", PubDate: citygraph.MustParseDate("2022-02-28T23:30:00-08:00")},
+	}
+	sortListings(listings)
+	var got []string
+	for _, l := range listings {
+		got = append(got, l.ID)
+	}
+	want := []string{"updated-late", "published-pacific", "published-mid", "published-early"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("sortListings() order diff:\n%s\n", diff)
+	}
+}
+
+func TestListingMaintainerUpsert(t *testing.T) {
+	existing := &ArticleListing{ID: "b", PubDate: citygraph.MustParseDate("2022-01-01"), Categories: []string{"Transit"}}
+	moved := &ArticleListing{ID: "a", PubDate: citygraph.MustParseDate("2022-02-01"), Categories: []string{"Open Data"}}
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetVertexPropertiesResps: [][]*pb.VertexProperty{
+			listingProps(t, moved, existing),
+			pageCountProps(2),
+			// Listings are read in name order: Open Data, then Transit.
+			listingProps(t, moved),
+			pageCountProps(1),
+			listingProps(t, existing),
+			pageCountProps(1),
+		},
+	}
+	m := NewListingMaintainer(&Store{GraphClient: fakeGraph})
+	m.PageSize = 1
+
+	updated := &ArticleListing{ID: "a", PubDate: citygraph.MustParseDate("2022-02-01"), UpdatedAt: citygraph.MustParseDate("2022-03-01"), Categories: []string{"Transit"}}
+	if err := m.Upsert(context.Background(), updated); err != nil {
+		t.Fatalf("Upsert() returned err: %v", err)
+	}
+
+	openData, transit := CategoryListingName("Open Data"), CategoryListingName("Transit")
+	want := map[string][]string{
+		PropertyNameLatestArticles:                     {"a", "b"},
+		ListingPageName(PropertyNameLatestArticles, 1): {"a"},
+		ListingPageName(PropertyNameLatestArticles, 2): {"b"},
+		openData:                    {},
+		transit:                     {"a", "b"},
+		ListingPageName(transit, 1): {"a"},
+		ListingPageName(transit, 2): {"b"},
+	}
+	if diff := cmp.Diff(want, writtenListings(t, fakeGraph)); diff != "" {
+		t.Errorf("Upsert() wrote listings diff:\n%s\n", diff)
+	}
+	if openData != "latest_articles_category_open-data" {
+		t.Errorf("CategoryListingName() = %q", openData)
+	}
+
+	tvq := citygraph.NewSpecificVertexQuery(citygraph.Torontoverse.Id)
+	wantDeletes := []*pb.VertexPropertyQuery{citygraph.NewVertexPropertyQuery(tvq, ListingPageName(openData, 1))}
+	if diff := cmp.Diff(wantDeletes, fakeGraph.DeleteVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("Upsert() sent delete vertex properties req diff:\n%s\n", diff)
+	}
+}
+
+func TestListingMaintainerRebuild(t *testing.T) {
+	aID, bID := citygraph.NewID(), citygraph.NewID()
+	gone := CategoryListingName("Gone")
+	var existing []*pb.NamedProperty
+	for _, name := range []string{
+		"name",
+		PropertyNameLatestArticles,
+		ListingPageName(PropertyNameLatestArticles, 1),
+		ListingPageName(PropertyNameLatestArticles, 2),
+		ListingPageCountName(PropertyNameLatestArticles),
+		gone,
+		ListingPageName(gone, 1),
+		ListingPageCountName(gone),
+	} {
+		existing = append(existing, &pb.NamedProperty{Name: &pb.Identifier{Value: name}, Value: citygraph.Json([]byte("[]"))})
+	}
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetAllVertexPropertiesResps: [][]*pb.VertexProperties{{{Vertex: citygraph.Torontoverse, Props: existing}}},
+	}
+	store := &Store{GraphClient: fakeGraph}
+	articles := []*citygraph.Article{{
+		ID:      aID.String(),
+		IsLive:  true,
+		PubDate: citygraph.MustParseDate("2022-06-14"),
+		// Both spellings share one listing.
+		Authors: []string{"Raoul Duke", "raoul duke"},
+	}, {
+		ID:      bID.String(),
+		IsLive:  false,
+		PubDate: citygraph.MustParseDate("2022-06-15"),
+	}}
+	if err := store.WriteArticleListings(context.Background(), nil, articles); err != nil {
+		t.Fatalf("store.WriteArticleListings() returned err: %v", err)
+	}
+	author := AuthorListingName("Raoul Duke")
+	want := map[string][]string{
+		PropertyNameLatestArticles:                     {aID.String()},
+		ListingPageName(PropertyNameLatestArticles, 1): {aID.String()},
+		author:                     {aID.String()},
+		ListingPageName(author, 1): {aID.String()},
+	}
+	if diff := cmp.Diff(want, writtenListings(t, fakeGraph)); diff != "" {
+		t.Errorf("store.WriteArticleListings() wrote listings diff:\n%s\n", diff)
+	}
+
+	tvq := citygraph.NewSpecificVertexQuery(citygraph.Torontoverse.Id)
+	var wantDeletes []*pb.VertexPropertyQuery
+	for _, name := range []string{
+		gone,
+		ListingPageName(gone, 1),
+		ListingPageCountName(gone),
+		ListingPageName(PropertyNameLatestArticles, 2),
+	} {
+		wantDeletes = append(wantDeletes, citygraph.NewVertexPropertyQuery(tvq, name))
+	}
+	if diff := cmp.Diff(wantDeletes, fakeGraph.DeleteVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.WriteArticleListings() sent delete vertex properties req diff:\n%s\n", diff)
+	}
+}
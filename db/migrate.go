@@ -0,0 +1,670 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+const (
+	// PropertyNameSchemaVersion is the anchor vertex property holding the
+	// version of the last migration applied.
+	PropertyNameSchemaVersion = "schema_version"
+	// PropertyNameMigrationCheckpoint is the anchor vertex property holding
+	// the progress of a migration that hasn't finished.
+	PropertyNameMigrationCheckpoint = "schema_migration_checkpoint"
+
+	// DefaultMigrationBatchSize is the number of vertices a Migrator reads
+	// at a time, and visits between checkpoints.
+	DefaultMigrationBatchSize = 100
+)
+
+// Migration moves the graph from the previous schema version to Version by
+// applying each of its steps, in order, to every vertex of the step's type.
+// Steps must be safe to apply twice to the same vertex, since a migration
+// resumed from a checkpoint revisits the vertices after it.
+type Migration struct {
+	Version     int
+	Description string
+	Steps       []MigrationStep
+}
+
+// MigrationStep changes one vertex of a given type at a time.
+type MigrationStep interface {
+	// VertexType is the type of the vertices the step visits.
+	VertexType() *pb.Identifier
+	// Migrate returns the changes the step makes to v, making them unless
+	// dryRun is set.
+	Migrate(ctx context.Context, graph citygraph.GraphClient, v *pb.VertexProperties, dryRun bool) ([]*Change, error)
+	String() string
+}
+
+// RecoverableStep is a MigrationStep whose change to a vertex can't be
+// redone from the vertex alone if it is interrupted partway, such as deleting
+// and recreating it. The Migrator saves the record Prepare returns in its
+// checkpoint before calling Recover to make the change, and on resume calls
+// Recover again with any record it finds there. Recover must be safe to call
+// twice with the same record.
+type RecoverableStep interface {
+	MigrationStep
+	Prepare(ctx context.Context, graph citygraph.GraphClient, v *pb.VertexProperties) (json.RawMessage, error)
+	Recover(ctx context.Context, graph citygraph.GraphClient, record json.RawMessage) error
+}
+
+// RenameProperty moves the value of From to To on vertices of Type,
+// replacing any value To already has.
+type RenameProperty struct {
+	Type     *pb.Identifier
+	From, To string
+}
+
+func (r *RenameProperty) VertexType() *pb.Identifier { return r.Type }
+
+func (r *RenameProperty) String() string {
+	return fmt.Sprintf("rename %s property %s to %s", r.Type.GetValue(), r.From, r.To)
+}
+
+func (r *RenameProperty) Migrate(ctx context.Context, graph citygraph.GraphClient, v *pb.VertexProperties, dryRun bool) ([]*Change, error) {
+	value, ok := property(v, r.From)
+	if !ok {
+		return nil, nil
+	}
+	id := v.GetVertex().GetId()
+	changes := []*Change{{Action: ActionRemove, Target: propertyTarget(r.From, id), Before: value}}
+	switch existing, ok := property(v, r.To); {
+	case !ok:
+		changes = append(changes, &Change{Action: ActionAdd, Target: propertyTarget(r.To, id), After: value})
+	case !jsonEqual(existing, value):
+		changes = append(changes, &Change{Action: ActionChange, Target: propertyTarget(r.To, id), Before: existing, After: value})
+	}
+	if dryRun {
+		return changes, nil
+	}
+	q := citygraph.NewSpecificVertexQuery(id)
+	if err := graph.SetVertexProperties(ctx, q, r.To, value); err != nil {
+		return nil, err
+	}
+	if err := citygraph.DeleteVertexProperties(ctx, graph, q, r.From); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// RewriteProperty replaces the value of Name on vertices of Type with the
+// result of Rewrite. Vertices without the property are skipped.
+type RewriteProperty struct {
+	Type *pb.Identifier
+	Name string
+	// Rewrite returns the new JSON value for the old one.
+	Rewrite func(json.RawMessage) (json.RawMessage, error)
+}
+
+func (r *RewriteProperty) VertexType() *pb.Identifier { return r.Type }
+
+func (r *RewriteProperty) String() string {
+	return fmt.Sprintf("rewrite %s property %s", r.Type.GetValue(), r.Name)
+}
+
+func (r *RewriteProperty) Migrate(ctx context.Context, graph citygraph.GraphClient, v *pb.VertexProperties, dryRun bool) ([]*Change, error) {
+	before, ok := property(v, r.Name)
+	if !ok {
+		return nil, nil
+	}
+	id := v.GetVertex().GetId()
+	after, err := r.Rewrite(before)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", propertyTarget(r.Name, id), err)
+	}
+	if jsonEqual(before, after) {
+		return nil, nil
+	}
+	changes := []*Change{{Action: ActionChange, Target: propertyTarget(r.Name, id), Before: before, After: after}}
+	if dryRun {
+		return changes, nil
+	}
+	if err := graph.SetVertexProperties(ctx, citygraph.NewSpecificVertexQuery(id), r.Name, after); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// RetypeVertex changes vertices of type From to type To. IndraDB can't change
+// a vertex's type, so each vertex is deleted and created again with the same
+// ID, properties and edges. It is a RecoverableStep, so a Migrator that is
+// interrupted between the delete and the recreate finishes the job on resume.
+type RetypeVertex struct {
+	From, To *pb.Identifier
+}
+
+func (r *RetypeVertex) VertexType() *pb.Identifier { return r.From }
+
+func (r *RetypeVertex) String() string {
+	return fmt.Sprintf("retype %s vertices to %s", r.From.GetValue(), r.To.GetValue())
+}
+
+func (r *RetypeVertex) Migrate(ctx context.Context, graph citygraph.GraphClient, v *pb.VertexProperties, dryRun bool) ([]*Change, error) {
+	id := v.GetVertex().GetId()
+	changes := []*Change{
+		{Action: ActionRemove, Target: describeVertex(v.GetVertex())},
+		{Action: ActionAdd, Target: describeVertex(&pb.Vertex{Id: id, T: r.To})},
+	}
+	if dryRun {
+		return changes, nil
+	}
+	record, err := r.Prepare(ctx, graph, v)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Recover(ctx, graph, record); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// retypeRecord is everything RetypeVertex needs to recreate a vertex.
+type retypeRecord struct {
+	ID    uuid.UUID          `json:"id"`
+	Props []recordedProperty `json:"props,omitempty"`
+	Edges []recordedEdge     `json:"edges,omitempty"`
+}
+
+type recordedProperty struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+type recordedEdge struct {
+	Outbound uuid.UUID          `json:"outbound"`
+	Type     string             `json:"type"`
+	Inbound  uuid.UUID          `json:"inbound"`
+	Props    []recordedProperty `json:"props,omitempty"`
+}
+
+func recordProperties(props []*pb.NamedProperty) []recordedProperty {
+	var recorded []recordedProperty
+	for _, prop := range props {
+		recorded = append(recorded, recordedProperty{prop.GetName().GetValue(), json.RawMessage(prop.GetValue().GetValue())})
+	}
+	return recorded
+}
+
+// Prepare records v's properties and edges.
+func (r *RetypeVertex) Prepare(ctx context.Context, graph citygraph.GraphClient, v *pb.VertexProperties) (json.RawMessage, error) {
+	id, err := uuid.FromBytes(v.GetVertex().GetId().GetValue())
+	if err != nil {
+		return nil, err
+	}
+	record := &retypeRecord{ID: id, Props: recordProperties(v.GetProps())}
+	vq := citygraph.NewSpecificVertexQuery(v.GetVertex().GetId())
+	for _, dir := range []pb.EdgeDirection{pb.EdgeDirection_OUTBOUND, pb.EdgeDirection_INBOUND} {
+		found, err := graph.GetAllEdgeProperties(ctx, citygraph.NewPipeEdgeQuery(vq, dir, nil))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range found {
+			key := e.GetEdge().GetKey()
+			out, err := uuid.FromBytes(key.GetOutboundId().GetValue())
+			if err != nil {
+				return nil, err
+			}
+			in, err := uuid.FromBytes(key.GetInboundId().GetValue())
+			if err != nil {
+				return nil, err
+			}
+			record.Edges = append(record.Edges, recordedEdge{out, key.GetT().GetValue(), in, recordProperties(e.GetProps())})
+		}
+	}
+	return json.Marshal(record)
+}
+
+// Recover deletes the recorded vertex, if it still exists, and creates it
+// again as a To vertex with the recorded properties and edges.
+func (r *RetypeVertex) Recover(ctx context.Context, graph citygraph.GraphClient, data json.RawMessage) error {
+	var record retypeRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("retype record: %w", err)
+	}
+	id := citygraph.UUID(record.ID)
+	vq := citygraph.NewSpecificVertexQuery(id)
+	if err := graph.DeleteVertices(ctx, vq); err != nil {
+		return err
+	}
+	if err := graph.CreateVertex(ctx, id, r.To); err != nil {
+		return err
+	}
+	for _, prop := range record.Props {
+		if err := graph.SetVertexProperties(ctx, vq, prop.Name, prop.Value); err != nil {
+			return err
+		}
+	}
+	for _, e := range record.Edges {
+		key := &pb.EdgeKey{OutboundId: citygraph.UUID(e.Outbound), T: &pb.Identifier{Value: e.Type}, InboundId: citygraph.UUID(e.Inbound)}
+		if err := graph.CreateEdge(ctx, key.GetOutboundId(), key.GetT(), key.GetInboundId()); err != nil {
+			return err
+		}
+		for _, prop := range e.Props {
+			if err := graph.SetEdgeProperties(ctx, citygraph.NewSpecificEdgeQuery(key), prop.Name, prop.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RewireEdges replaces Edge edges leaving vertices of Type with edges of type
+// To, which defaults to Edge, running the other way if Reverse is set. Edge
+// properties are kept.
+//
+// An edge the step has already rewired is left alone when the step reaches
+// the vertex it now leaves, so reversing an Edge edge between two Type
+// vertices in place flips it once. The step only remembers this for one run:
+// a run resumed from a checkpoint can flip such an edge back, so prefer a
+// distinct To when both ends can be of Type.
+type RewireEdges struct {
+	Type    *pb.Identifier
+	Edge    *pb.Identifier
+	To      *pb.Identifier
+	Reverse bool
+
+	// rewired holds the edges the step has created, by describeEdge.
+	rewired map[string]bool
+}
+
+func (r *RewireEdges) VertexType() *pb.Identifier { return r.Type }
+
+func (r *RewireEdges) String() string {
+	s := fmt.Sprintf("rewire %s edges from %s vertices to %s", r.Edge.GetValue(), r.Type.GetValue(), r.to().GetValue())
+	if r.Reverse {
+		s += ", reversed"
+	}
+	return s
+}
+
+func (r *RewireEdges) to() *pb.Identifier {
+	if r.To != nil {
+		return r.To
+	}
+	return r.Edge
+}
+
+func (r *RewireEdges) Migrate(ctx context.Context, graph citygraph.GraphClient, v *pb.VertexProperties, dryRun bool) ([]*Change, error) {
+	vq := citygraph.NewSpecificVertexQuery(v.GetVertex().GetId())
+	edges, err := graph.GetAllEdgeProperties(ctx, citygraph.NewPipeEdgeQuery(vq, pb.EdgeDirection_OUTBOUND, r.Edge))
+	if err != nil {
+		return nil, err
+	}
+	var changes []*Change
+	for _, e := range edges {
+		old := e.GetEdge().GetKey()
+		key := &pb.EdgeKey{OutboundId: old.GetOutboundId(), T: r.to(), InboundId: old.GetInboundId()}
+		if r.Reverse {
+			key.OutboundId, key.InboundId = old.GetInboundId(), old.GetOutboundId()
+		}
+		if describeEdge(key) == describeEdge(old) || r.rewired[describeEdge(old)] {
+			continue
+		}
+		if r.rewired == nil {
+			r.rewired = map[string]bool{}
+		}
+		r.rewired[describeEdge(key)] = true
+		changes = append(changes,
+			&Change{Action: ActionRemove, Target: describeEdge(old)},
+			&Change{Action: ActionAdd, Target: describeEdge(key)},
+		)
+		if dryRun {
+			continue
+		}
+		if err := restoreEdge(ctx, graph, key, e); err != nil {
+			return nil, err
+		}
+		if err := graph.DeleteEdges(ctx, citygraph.NewSpecificEdgeQuery(old)); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// restoreEdge creates the edge with the given key and copies e's properties
+// to it.
+func restoreEdge(ctx context.Context, graph citygraph.GraphClient, key *pb.EdgeKey, e *pb.EdgeProperties) error {
+	if err := graph.CreateEdge(ctx, key.GetOutboundId(), key.GetT(), key.GetInboundId()); err != nil {
+		return err
+	}
+	for _, prop := range e.GetProps() {
+		if err := graph.SetEdgeProperties(ctx, citygraph.NewSpecificEdgeQuery(key), prop.GetName().GetValue(), json.RawMessage(prop.GetValue().GetValue())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrationReport describes what a Migrator did, or would do in a dry run.
+type MigrationReport struct {
+	DryRun bool
+	// From and To are the schema versions before and after.
+	From, To int
+	// Applied lists the versions of the migrations run.
+	Applied []int
+	Changes []*Change
+}
+
+// String renders the report for humans, one change per line.
+func (r *MigrationReport) String() string {
+	var b strings.Builder
+	if len(r.Applied) == 0 {
+		fmt.Fprintf(&b, "Schema is at version %d, no migrations to run.\n", r.From)
+		return b.String()
+	}
+	counts := writeChanges(&b, r.Changes)
+	verb := "Migrated"
+	if r.DryRun {
+		verb = "Dry run: would migrate"
+	}
+	fmt.Fprintf(&b, "%s schema from version %d to %d: %d to add, %d to change, %d to remove.\n",
+		verb, r.From, r.To, counts[ActionAdd], counts[ActionChange], counts[ActionRemove])
+	return b.String()
+}
+
+// migrationCheckpoint records how far an unfinished migration got.
+type migrationCheckpoint struct {
+	Version int `json:"version"`
+	Step    int `json:"step"`
+	// After is the ID of the last vertex the step finished with.
+	After uuid.UUID `json:"after"`
+	// Recovery, if set, is a RecoverableStep's record of the vertex after
+	// After, which may be partly changed.
+	Recovery json.RawMessage `json:"recovery,omitempty"`
+}
+
+// Migrator brings the graph's schema up to date by running the migrations
+// newer than the version stored on the anchor vertex. Progress is saved on
+// the anchor after every batch of vertices, so an interrupted run resumes
+// where it stopped.
+type Migrator struct {
+	Graph      citygraph.GraphClient
+	Migrations []*Migration
+	// Anchor holds the schema version and checkpoint. It defaults to
+	// citygraph.Torontoverse.
+	Anchor *pb.Vertex
+	// BatchSize defaults to DefaultMigrationBatchSize.
+	BatchSize int
+	// DryRun reports the changes the migrations would make without making
+	// them. Every step reads the graph as it is, so a step that depends on
+	// an earlier step's or migration's changes is reported as if they hadn't
+	// been made.
+	DryRun bool
+	// Log, if set, receives a line as each migration and step starts.
+	Log io.Writer
+}
+
+func NewMigrator(graph citygraph.GraphClient, migrations ...*Migration) *Migrator {
+	return &Migrator{Graph: graph, Migrations: migrations}
+}
+
+func (m *Migrator) anchorID() *pb.Uuid {
+	if m.Anchor != nil {
+		return m.Anchor.GetId()
+	}
+	return citygraph.Torontoverse.GetId()
+}
+
+func (m *Migrator) anchor() *pb.VertexQuery {
+	return citygraph.NewSpecificVertexQuery(m.anchorID())
+}
+
+func (m *Migrator) logf(format string, args ...interface{}) {
+	if m.Log != nil {
+		fmt.Fprintf(m.Log, format+"\n", args...)
+	}
+}
+
+// Version returns the schema version stored on the anchor vertex, or 0 if
+// no migration has been run.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	var version int
+	_, err := m.readAnchor(ctx, PropertyNameSchemaVersion, &version)
+	return version, err
+}
+
+func (m *Migrator) readAnchor(ctx context.Context, name string, v interface{}) (bool, error) {
+	props, err := m.Graph.GetVertexProperties(ctx, m.anchor(), name)
+	if err != nil {
+		return false, err
+	}
+	if len(props) == 0 {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(props[0].GetValue().GetValue()), v); err != nil {
+		return false, fmt.Errorf("anchor %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// Pending returns the migrations newer than the stored schema version, in
+// version order.
+func (m *Migrator) Pending(ctx context.Context) ([]*Migration, error) {
+	migrations, err := m.sorted()
+	if err != nil {
+		return nil, err
+	}
+	version, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newerThan(migrations, version), nil
+}
+
+// sorted returns the migrations in version order, checking that versions are
+// positive and unique.
+func (m *Migrator) sorted() ([]*Migration, error) {
+	migrations := append([]*Migration(nil), m.Migrations...)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, mig := range migrations {
+		if mig.Version <= 0 {
+			return nil, fmt.Errorf("migration %q has version %d, want a positive version", mig.Description, mig.Version)
+		}
+		if i > 0 && migrations[i-1].Version == mig.Version {
+			return nil, fmt.Errorf("two migrations have version %d", mig.Version)
+		}
+	}
+	return migrations, nil
+}
+
+func newerThan(migrations []*Migration, version int) []*Migration {
+	var newer []*Migration
+	for _, mig := range migrations {
+		if mig.Version > version {
+			newer = append(newer, mig)
+		}
+	}
+	return newer
+}
+
+// Run runs the pending migrations in version order, recording the new schema
+// version on the anchor vertex after each one.
+func (m *Migrator) Run(ctx context.Context) (*MigrationReport, error) {
+	migrations, err := m.sorted()
+	if err != nil {
+		return nil, err
+	}
+	version, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending := newerThan(migrations, version)
+	report := &MigrationReport{DryRun: m.DryRun, From: version, To: version}
+	var checkpoint migrationCheckpoint
+	resume, err := m.readAnchor(ctx, PropertyNameMigrationCheckpoint, &checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	for _, mig := range pending {
+		m.logf("migration %d: %s", mig.Version, mig.Description)
+		start := migrationCheckpoint{Version: mig.Version}
+		if resume && checkpoint.Version == mig.Version && !m.DryRun {
+			start = checkpoint
+			m.logf("resuming at step %d after vertex %s", start.Step+1, start.After)
+			if err := m.recover(ctx, mig, start); err != nil {
+				return report, fmt.Errorf("migration %d: %w", mig.Version, err)
+			}
+		}
+		if err := m.migrate(ctx, mig, start, report); err != nil {
+			return report, fmt.Errorf("migration %d: %w", mig.Version, err)
+		}
+		report.Changes = append(report.Changes, &Change{
+			Action: ActionChange,
+			Target: propertyTarget(PropertyNameSchemaVersion, m.anchorID()),
+			Before: json.RawMessage(fmt.Sprint(report.To)),
+			After:  json.RawMessage(fmt.Sprint(mig.Version)),
+		})
+		report.Applied = append(report.Applied, mig.Version)
+		report.To = mig.Version
+		if m.DryRun {
+			continue
+		}
+		if err := m.Graph.SetVertexProperties(ctx, m.anchor(), PropertyNameSchemaVersion, mig.Version); err != nil {
+			return report, err
+		}
+		if err := citygraph.DeleteVertexProperties(ctx, m.Graph, m.anchor(), PropertyNameMigrationCheckpoint); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// recover finishes the change recorded in the checkpoint, if any.
+func (m *Migrator) recover(ctx context.Context, mig *Migration, checkpoint migrationCheckpoint) error {
+	if len(checkpoint.Recovery) == 0 {
+		return nil
+	}
+	if checkpoint.Step < 0 || checkpoint.Step >= len(mig.Steps) {
+		return fmt.Errorf("checkpoint step %d out of range", checkpoint.Step+1)
+	}
+	step, ok := mig.Steps[checkpoint.Step].(RecoverableStep)
+	if !ok {
+		return fmt.Errorf("step %d: %s can't recover from a checkpoint", checkpoint.Step+1, mig.Steps[checkpoint.Step])
+	}
+	m.logf("  step %d: recovering interrupted change", checkpoint.Step+1)
+	if err := step.Recover(ctx, m.Graph, checkpoint.Recovery); err != nil {
+		return fmt.Errorf("step %d: recover: %w", checkpoint.Step+1, err)
+	}
+	return nil
+}
+
+// migrateVertex applies step to v. A RecoverableStep's record is saved in
+// the checkpoint first, along with the last vertex finished before v.
+func (m *Migrator) migrateVertex(ctx context.Context, step MigrationStep, v *pb.VertexProperties, checkpoint *migrationCheckpoint) ([]*Change, error) {
+	recoverable, ok := step.(RecoverableStep)
+	if !ok || m.DryRun {
+		return step.Migrate(ctx, m.Graph, v, m.DryRun)
+	}
+	changes, err := step.Migrate(ctx, m.Graph, v, true)
+	if err != nil || len(changes) == 0 {
+		return changes, err
+	}
+	record, err := recoverable.Prepare(ctx, m.Graph, v)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint.Recovery = record
+	if err := m.Graph.SetVertexProperties(ctx, m.anchor(), PropertyNameMigrationCheckpoint, checkpoint); err != nil {
+		return nil, fmt.Errorf("save checkpoint: %w", err)
+	}
+	if err := recoverable.Recover(ctx, m.Graph, record); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// migrate runs mig's steps from the checkpoint onwards.
+func (m *Migrator) migrate(ctx context.Context, mig *Migration, from migrationCheckpoint, report *MigrationReport) error {
+	batchSize := m.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultMigrationBatchSize
+	}
+	for i := from.Step; i < len(mig.Steps); i++ {
+		step := mig.Steps[i]
+		m.logf("  step %d: %s", i+1, step)
+		var after *uuid.UUID
+		if i == from.Step && from.After != uuid.Nil {
+			after = &from.After
+		}
+		for {
+			q := &pb.VertexQuery{Query: &pb.VertexQuery_Range{Range: &pb.RangeVertexQuery{
+				Limit: uint32(batchSize) + 1,
+				T:     step.VertexType(),
+			}}}
+			if after != nil {
+				q.GetRange().StartId = citygraph.UUID(*after)
+			}
+			batch, err := m.Graph.GetAllVertexProperties(ctx, q)
+			if err != nil {
+				return err
+			}
+			// The range starts at the last vertex visited, which is skipped.
+			if after != nil && len(batch) > 0 && bytes.Equal(batch[0].GetVertex().GetId().GetValue(), after[:]) {
+				batch = batch[1:]
+			}
+			if len(batch) > batchSize {
+				batch = batch[:batchSize]
+			}
+			if len(batch) == 0 {
+				break
+			}
+			for j, v := range batch {
+				checkpoint := &migrationCheckpoint{Version: mig.Version, Step: i}
+				if j > 0 {
+					prev, err := uuid.FromBytes(batch[j-1].GetVertex().GetId().GetValue())
+					if err != nil {
+						return err
+					}
+					checkpoint.After = prev
+				} else if after != nil {
+					checkpoint.After = *after
+				}
+				changes, err := m.migrateVertex(ctx, step, v, checkpoint)
+				if err != nil {
+					return fmt.Errorf("step %d: vertex %s: %w", i+1, uuidString(v.GetVertex().GetId()), err)
+				}
+				report.Changes = append(report.Changes, changes...)
+			}
+			last, err := uuid.FromBytes(batch[len(batch)-1].GetVertex().GetId().GetValue())
+			if err != nil {
+				return err
+			}
+			after = &last
+			if !m.DryRun {
+				if err := m.Graph.SetVertexProperties(ctx, m.anchor(), PropertyNameMigrationCheckpoint, &migrationCheckpoint{Version: mig.Version, Step: i, After: last}); err != nil {
+					return fmt.Errorf("save checkpoint: %w", err)
+				}
+			}
+			if len(batch) < batchSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// property returns the JSON value of the named property of v.
+func property(v *pb.VertexProperties, name string) (json.RawMessage, bool) {
+	for _, prop := range v.GetProps() {
+		if prop.GetName().GetValue() == name {
+			return json.RawMessage(prop.GetValue().GetValue()), true
+		}
+	}
+	return nil, false
+}
+
+func propertyTarget(name string, id *pb.Uuid) string {
+	return fmt.Sprintf("property %s on vertex %s", name, uuidString(id))
+}
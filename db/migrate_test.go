@@ -0,0 +1,377 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/graphtest"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+func migrationVertex(id uuid.UUID, t *pb.Identifier, props map[string]string) *pb.VertexProperties {
+	v := &pb.VertexProperties{Vertex: &pb.Vertex{Id: citygraph.UUID(id), T: t}}
+	for name, value := range props {
+		v.Props = append(v.Props, &pb.NamedProperty{Name: &pb.Identifier{Value: name}, Value: citygraph.Json([]byte(value))})
+	}
+	return v
+}
+
+func anchorProperty(t *testing.T, value interface{}) []*pb.VertexProperty {
+	t.Helper()
+	b, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []*pb.VertexProperty{{Id: citygraph.Torontoverse.GetId(), Value: citygraph.Json(b)}}
+}
+
+type setProperty struct {
+	ID    uuid.UUID
+	Name  string
+	Value string
+}
+
+func setProperties(t *testing.T, reqs []*pb.SetVertexPropertiesRequest) []setProperty {
+	t.Helper()
+	var got []setProperty
+	for _, req := range reqs {
+		id, err := uuid.FromBytes(req.GetQ().GetInner().GetSpecific().GetIds()[0].GetValue())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, setProperty{ID: id, Name: req.GetQ().GetName().GetValue(), Value: req.GetValue().GetValue()})
+	}
+	return got
+}
+
+func TestMigratorRun(t *testing.T) {
+	anchorID, err := uuid.FromBytes(citygraph.Torontoverse.GetId().GetValue())
+	if err != nil {
+		t.Fatal(err)
+	}
+	aID, bID, cID := citygraph.NewID(), citygraph.NewID(), citygraph.NewID()
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetVertexPropertiesResps: [][]*pb.VertexProperty{
+			anchorProperty(t, 1), // schema version
+			nil,                  // checkpoint
+		},
+		GetAllVertexPropertiesResps: [][]*pb.VertexProperties{
+			{
+				migrationVertex(aID, citygraph.ArticleType, map[string]string{"body": `"a"`}),
+				migrationVertex(bID, citygraph.ArticleType, map[string]string{"teaser_js": `"b"`}),
+			},
+			{
+				migrationVertex(bID, citygraph.ArticleType, map[string]string{"teaser_js": `"b"`}),
+				migrationVertex(cID, citygraph.ArticleType, map[string]string{"body": `"c"`, "body_text": `"old"`}),
+			},
+		},
+	}
+	m := NewMigrator(fakeGraph,
+		&Migration{Version: 1, Description: "already applied"},
+		&Migration{
+			Version:     2,
+			Description: "rename body",
+			Steps:       []MigrationStep{&RenameProperty{Type: citygraph.ArticleType, From: "body", To: "body_text"}},
+		},
+	)
+	m.BatchSize = 2
+
+	report, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("m.Run() returned err: %v", err)
+	}
+
+	if diff := cmp.Diff([]int{2}, report.Applied); diff != "" {
+		t.Errorf("m.Run() applied migrations diff:\n%s\n", diff)
+	}
+	// The second page starts at the last vertex of the first, which is
+	// skipped.
+	wantRanges := []*pb.VertexQuery{
+		{Query: &pb.VertexQuery_Range{Range: &pb.RangeVertexQuery{Limit: 3, T: citygraph.ArticleType}}},
+		{Query: &pb.VertexQuery_Range{Range: &pb.RangeVertexQuery{Limit: 3, T: citygraph.ArticleType, StartId: citygraph.UUID(bID)}}},
+	}
+	if diff := cmp.Diff(wantRanges, fakeGraph.GetAllVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("m.Run() range queries diff:\n%s\n", diff)
+	}
+	checkpoint := func(after uuid.UUID) string {
+		return `{"version":2,"step":0,"after":"` + after.String() + `"}`
+	}
+	wantSet := []setProperty{
+		{aID, "body_text", `"a"`},
+		{anchorID, PropertyNameMigrationCheckpoint, checkpoint(bID)},
+		{cID, "body_text", `"c"`},
+		{anchorID, PropertyNameMigrationCheckpoint, checkpoint(cID)},
+		{anchorID, PropertyNameSchemaVersion, "2"},
+	}
+	if diff := cmp.Diff(wantSet, setProperties(t, fakeGraph.SetVertexPropertiesReqs)); diff != "" {
+		t.Errorf("m.Run() set properties diff:\n%s\n", diff)
+	}
+	wantDeleted := []*pb.VertexPropertyQuery{
+		{Inner: citygraph.NewSpecificVertexQuery(citygraph.UUID(aID)), Name: &pb.Identifier{Value: "body"}},
+		{Inner: citygraph.NewSpecificVertexQuery(citygraph.UUID(cID)), Name: &pb.Identifier{Value: "body"}},
+		{Inner: citygraph.NewSpecificVertexQuery(citygraph.Torontoverse.GetId()), Name: &pb.Identifier{Value: PropertyNameMigrationCheckpoint}},
+	}
+	if diff := cmp.Diff(wantDeleted, fakeGraph.DeleteVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("m.Run() deleted properties diff:\n%s\n", diff)
+	}
+}
+
+func TestMigratorDryRun(t *testing.T) {
+	aID := citygraph.NewID()
+	anchor := citygraph.Torontoverse.GetId()
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetVertexPropertiesResps: [][]*pb.VertexProperty{nil, nil},
+		GetAllVertexPropertiesResps: [][]*pb.VertexProperties{
+			{migrationVertex(aID, &citygraph.NewsRevision, map[string]string{"created_at": `"2023-03-01"`})},
+			{migrationVertex(aID, &citygraph.NewsRevision, map[string]string{"created_at": `"2023-03-01"`})},
+		},
+	}
+	m := NewMigrator(fakeGraph,
+		&Migration{
+			Version:     2,
+			Description: "retype revisions",
+			Steps:       []MigrationStep{&RetypeVertex{From: &citygraph.NewsRevision, To: &pb.Identifier{Value: "revision"}}},
+		},
+		&Migration{
+			Version:     1,
+			Description: "add times to revision dates",
+			Steps: []MigrationStep{&RewriteProperty{
+				Type: &citygraph.NewsRevision,
+				Name: "created_at",
+				Rewrite: func(v json.RawMessage) (json.RawMessage, error) {
+					return json.RawMessage(strings.TrimSuffix(string(v), `"`) + `T00:00:00Z"`), nil
+				},
+			}},
+		},
+	)
+	m.DryRun = true
+
+	report, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("m.Run() returned err: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"~ property created_at on vertex " + aID.String(),
+		`    before: "2023-03-01"`,
+		`    after:  "2023-03-01T00:00:00Z"`,
+		"~ property schema_version on vertex " + uuidString(anchor),
+		"    before: 0",
+		"    after:  1",
+		"- vertex " + aID.String() + " (news-revision)",
+		"+ vertex " + aID.String() + " (revision)",
+		"~ property schema_version on vertex " + uuidString(anchor),
+		"    before: 1",
+		"    after:  2",
+		"Dry run: would migrate schema from version 0 to 2: 1 to add, 3 to change, 1 to remove.",
+		"",
+	}, "\n")
+	if diff := cmp.Diff(want, report.String()); diff != "" {
+		t.Errorf("report.String() diff:\n%s\n", diff)
+	}
+	if len(fakeGraph.SetVertexPropertiesReqs) > 0 || len(fakeGraph.DeleteVerticesReqs) > 0 || len(fakeGraph.CreateVertexReqs) > 0 {
+		t.Errorf("m.Run() wrote to the graph in a dry run")
+	}
+}
+
+func TestMigratorResume(t *testing.T) {
+	aID, bID := citygraph.NewID(), citygraph.NewID()
+	category := citygraph.NewID()
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetVertexPropertiesResps: [][]*pb.VertexProperty{
+			nil,
+			anchorProperty(t, &migrationCheckpoint{Version: 1, Step: 1, After: aID}),
+		},
+		GetAllVertexPropertiesResps: [][]*pb.VertexProperties{
+			{
+				migrationVertex(aID, &citygraph.NewsPublisher, nil),
+				migrationVertex(bID, &citygraph.NewsPublisher, nil),
+			},
+		},
+		GetAllEdgePropertiesResps: [][]*pb.EdgeProperties{{{
+			Edge: &pb.Edge{Key: &pb.EdgeKey{OutboundId: citygraph.UUID(bID), T: &citygraph.CoversTopic, InboundId: citygraph.UUID(category)}},
+			Props: []*pb.NamedProperty{
+				{Name: &pb.Identifier{Value: "weight"}, Value: citygraph.Json([]byte("2"))},
+			},
+		}}},
+	}
+	m := NewMigrator(fakeGraph, &Migration{
+		Version:     1,
+		Description: "publishers",
+		Steps: []MigrationStep{
+			&RenameProperty{Type: &citygraph.NewsPublisher, From: "name", To: citygraph.PropertyNameDisplayName},
+			&RewireEdges{Type: &citygraph.NewsPublisher, Edge: &citygraph.CoversTopic, To: &citygraph.PublishesAbout},
+		},
+	})
+
+	report, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("m.Run() returned err: %v", err)
+	}
+
+	if diff := cmp.Diff([]int{1}, report.Applied); diff != "" {
+		t.Errorf("m.Run() applied migrations diff:\n%s\n", diff)
+	}
+	// The first step finished before the run was interrupted, and the second
+	// got as far as aID.
+	wantRanges := []*pb.VertexQuery{
+		{Query: &pb.VertexQuery_Range{Range: &pb.RangeVertexQuery{Limit: DefaultMigrationBatchSize + 1, T: &citygraph.NewsPublisher, StartId: citygraph.UUID(aID)}}},
+	}
+	if diff := cmp.Diff(wantRanges, fakeGraph.GetAllVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("m.Run() range queries diff:\n%s\n", diff)
+	}
+	newKey := &pb.EdgeKey{OutboundId: citygraph.UUID(bID), T: &citygraph.PublishesAbout, InboundId: citygraph.UUID(category)}
+	if diff := cmp.Diff([]*pb.EdgeKey{newKey}, fakeGraph.CreateEdgeReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("m.Run() created edges diff:\n%s\n", diff)
+	}
+	wantSetEdge := []*pb.SetEdgePropertiesRequest{{
+		Q:     &pb.EdgePropertyQuery{Inner: citygraph.NewSpecificEdgeQuery(newKey), Name: &pb.Identifier{Value: "weight"}},
+		Value: citygraph.Json([]byte("2")),
+	}}
+	if diff := cmp.Diff(wantSetEdge, fakeGraph.SetEdgePropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("m.Run() set edge properties diff:\n%s\n", diff)
+	}
+	wantDeletedEdges := []*pb.EdgeQuery{
+		citygraph.NewSpecificEdgeQuery(&pb.EdgeKey{OutboundId: citygraph.UUID(bID), T: &citygraph.CoversTopic, InboundId: citygraph.UUID(category)}),
+	}
+	if diff := cmp.Diff(wantDeletedEdges, fakeGraph.DeleteEdgesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("m.Run() deleted edges diff:\n%s\n", diff)
+	}
+}
+
+func TestMigratorRejectsDuplicateVersions(t *testing.T) {
+	m := NewMigrator(&graphtest.FakeGraphClient{},
+		&Migration{Version: 1, Description: "first"},
+		&Migration{Version: 1, Description: "second"},
+	)
+	if _, err := m.Pending(context.Background()); err == nil {
+		t.Errorf("m.Pending() returned nil err, want an error for duplicate versions")
+	}
+}
+
+// interruptedGraph fails every CreateVertex call, like a run killed between
+// deleting a vertex and creating it again.
+type interruptedGraph struct {
+	*graphtest.MemoryGraphClient
+}
+
+func (g interruptedGraph) CreateVertex(ctx context.Context, id *pb.Uuid, t *pb.Identifier) error {
+	return errors.New("interrupted")
+}
+
+func TestMigratorRecoversRetype(t *testing.T) {
+	ctx := context.Background()
+	graph := graphtest.NewMemoryGraphClient()
+	revType := &pb.Identifier{Value: "revision"}
+	aID, articleID := citygraph.NewID(), citygraph.NewID()
+	for _, v := range []*pb.Vertex{
+		citygraph.Torontoverse,
+		{Id: citygraph.UUID(aID), T: &citygraph.NewsRevision},
+		{Id: citygraph.UUID(articleID), T: citygraph.ArticleType},
+	} {
+		if err := graph.CreateVertex(ctx, v.Id, v.T); err != nil {
+			t.Fatal(err)
+		}
+	}
+	aq := citygraph.NewSpecificVertexQuery(citygraph.UUID(aID))
+	if err := graph.SetVertexProperties(ctx, aq, "created_at", "2023-03-01"); err != nil {
+		t.Fatal(err)
+	}
+	key := &pb.EdgeKey{OutboundId: citygraph.UUID(articleID), T: &citygraph.HasRevision, InboundId: citygraph.UUID(aID)}
+	if err := graph.CreateEdge(ctx, key.OutboundId, key.T, key.InboundId); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.SetEdgeProperties(ctx, citygraph.NewSpecificEdgeQuery(key), "weight", 2); err != nil {
+		t.Fatal(err)
+	}
+	migration := &Migration{
+		Version:     1,
+		Description: "retype revisions",
+		Steps:       []MigrationStep{&RetypeVertex{From: &citygraph.NewsRevision, To: revType}},
+	}
+
+	if _, err := NewMigrator(interruptedGraph{graph}, migration).Run(ctx); err == nil {
+		t.Fatalf("m.Run() on an interrupted graph returned nil err")
+	}
+	if got, _ := graph.GetVertices(ctx, aq); len(got) != 0 {
+		t.Fatalf("interrupted m.Run() left vertex %v, want it deleted", got)
+	}
+
+	if _, err := NewMigrator(graph, migration).Run(ctx); err != nil {
+		t.Fatalf("resumed m.Run() returned err: %v", err)
+	}
+	got, err := graph.GetAllVertexProperties(ctx, aq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*pb.VertexProperties{migrationVertex(aID, revType, map[string]string{"created_at": `"2023-03-01"`})}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("recovered vertex diff:\n%s\n", diff)
+	}
+	edges, err := graph.GetAllEdgeProperties(ctx, citygraph.NewSpecificEdgeQuery(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEdges := []*pb.EdgeProperties{{
+		Edge:  &pb.Edge{Key: key},
+		Props: []*pb.NamedProperty{{Name: &pb.Identifier{Value: "weight"}, Value: citygraph.Json([]byte("2"))}},
+	}}
+	if diff := cmp.Diff(wantEdges, edges, protocmp.Transform(), protocmp.IgnoreFields(&pb.Edge{}, "created_datetime")); diff != "" {
+		t.Errorf("recovered edges diff:\n%s\n", diff)
+	}
+	checkpoint, err := graph.GetVertexProperties(ctx, citygraph.NewSpecificVertexQuery(citygraph.Torontoverse.GetId()), PropertyNameMigrationCheckpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoint) != 0 {
+		t.Errorf("resumed m.Run() left checkpoint %v", checkpoint)
+	}
+}
+
+func TestRewireEdgesReversesSameTypeEdgeOnce(t *testing.T) {
+	ctx := context.Background()
+	graph := graphtest.NewMemoryGraphClient()
+	aID, bID := citygraph.NewID(), citygraph.NewID()
+	for _, v := range []*pb.Vertex{
+		citygraph.Torontoverse,
+		{Id: citygraph.UUID(aID), T: citygraph.ArticleType},
+		{Id: citygraph.UUID(bID), T: citygraph.ArticleType},
+	} {
+		if err := graph.CreateVertex(ctx, v.Id, v.T); err != nil {
+			t.Fatal(err)
+		}
+	}
+	related := &pb.Identifier{Value: "related_to"}
+	if err := graph.CreateEdge(ctx, citygraph.UUID(aID), related, citygraph.UUID(bID)); err != nil {
+		t.Fatal(err)
+	}
+	m := NewMigrator(graph, &Migration{
+		Version:     1,
+		Description: "reverse related edges",
+		Steps:       []MigrationStep{&RewireEdges{Type: citygraph.ArticleType, Edge: related, Reverse: true}},
+	})
+
+	if _, err := m.Run(ctx); err != nil {
+		t.Fatalf("m.Run() returned err: %v", err)
+	}
+	edges, err := graph.GetEdges(ctx, citygraph.NewPipeEdgeQuery(citygraph.NewSpecificVertexQuery(citygraph.UUID(aID), citygraph.UUID(bID)), pb.EdgeDirection_OUTBOUND, related))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range edges {
+		got = append(got, describeEdge(e.GetKey()))
+	}
+	want := []string{describeEdge(&pb.EdgeKey{OutboundId: citygraph.UUID(bID), T: related, InboundId: citygraph.UUID(aID)})}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("m.Run() left edges diff:\n%s\n", diff)
+	}
+}
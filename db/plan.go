@@ -0,0 +1,481 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+// Action describes what a planned change does to the graph.
+type Action int
+
+const (
+	ActionAdd Action = iota
+	ActionChange
+	ActionRemove
+)
+
+func (a Action) symbol() string {
+	switch a {
+	case ActionAdd:
+		return "+"
+	case ActionChange:
+		return "~"
+	default:
+		return "-"
+	}
+}
+
+// Change is a single difference between the graph as it is and as a planned
+// write would leave it.
+type Change struct {
+	Action Action
+	// Target describes what changes, eg. a vertex, an edge or a property.
+	Target string
+	// Before and After hold property values. Before is nil for added
+	// properties and After is nil for removed ones.
+	Before json.RawMessage
+	After  json.RawMessage
+}
+
+// Plan holds the writes a Store would make and how they would change the
+// graph. Nothing is written until Apply is called.
+type Plan struct {
+	Changes []*Change
+
+	graph citygraph.GraphClient
+	ops   []*planOp
+}
+
+// Empty reports whether applying the plan would change nothing.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String renders the plan for humans, one change per line with property
+// values shown before and after.
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "No changes.\n"
+	}
+	var b strings.Builder
+	counts := writeChanges(&b, p.Changes)
+	fmt.Fprintf(&b, "Plan: %d to add, %d to change, %d to remove.\n", counts[ActionAdd], counts[ActionChange], counts[ActionRemove])
+	return b.String()
+}
+
+// writeChanges writes one line per change to b, with property values shown
+// before and after, and returns the number of changes of each action.
+func writeChanges(b *strings.Builder, changes []*Change) map[Action]int {
+	counts := map[Action]int{}
+	for _, c := range changes {
+		counts[c.Action]++
+		fmt.Fprintf(b, "%s %s\n", c.Action.symbol(), c.Target)
+		if c.Before != nil {
+			fmt.Fprintf(b, "    before: %s\n", c.Before)
+		}
+		if c.After != nil {
+			fmt.Fprintf(b, "    after:  %s\n", c.After)
+		}
+	}
+	return counts
+}
+
+// Apply makes the planned writes that change the graph.
+func (p *Plan) Apply(ctx context.Context) error {
+	for _, op := range p.ops {
+		if !op.changes {
+			continue
+		}
+		if err := op.apply(ctx, p.graph); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Plan runs write against a copy of the store that records writes instead of
+// making them, and returns a plan describing how those writes would change
+// the graph. Reads made by write see the graph as it would be after the
+// writes recorded so far.
+func (s *Store) Plan(ctx context.Context, write func(context.Context, *Store) error) (*Plan, error) {
+	rec := &recordingClient{GraphClient: s.GraphClient}
+	planning := *s
+	planning.GraphClient = rec
+	if err := write(ctx, &planning); err != nil {
+		return nil, err
+	}
+	plan := &Plan{graph: s.GraphClient, ops: rec.ops}
+	superseded := supersededOps(rec.ops)
+	for _, op := range rec.ops {
+		if superseded[op] {
+			continue
+		}
+		changes, err := op.diff(ctx, s.GraphClient)
+		if err != nil {
+			return nil, err
+		}
+		op.changes = len(changes) > 0
+		plan.Changes = append(plan.Changes, changes...)
+	}
+	return plan, nil
+}
+
+// PlanAndApply plans write, prints the plan to out and applies it only if
+// the answer read from in confirms it. It reports whether the plan was
+// applied.
+func (s *Store) PlanAndApply(ctx context.Context, write func(context.Context, *Store) error, in io.Reader, out io.Writer) (bool, error) {
+	plan, err := s.Plan(ctx, write)
+	if err != nil {
+		return false, err
+	}
+	fmt.Fprint(out, plan)
+	if plan.Empty() {
+		return false, nil
+	}
+	fmt.Fprint(out, "Apply these changes? [y/N]: ")
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+	default:
+		fmt.Fprintln(out, "Not applied.")
+		return false, nil
+	}
+	if err := plan.Apply(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// supersededOps returns the property writes to a single vertex that a later
+// op overwrites, so only the final value of each property is planned.
+func supersededOps(ops []*planOp) map[*planOp]bool {
+	superseded := map[*planOp]bool{}
+	last := map[string]bool{}
+	for i := len(ops) - 1; i >= 0; i-- {
+		op := ops[i]
+		if op.kind != opSetVertexProperty && op.kind != opDeleteVertexProperty {
+			continue
+		}
+		ids := op.vertexIDs()
+		if len(ids) != 1 {
+			continue
+		}
+		key := string(ids[0].GetValue()) + "\x00" + op.name
+		if last[key] {
+			superseded[op] = true
+		}
+		last[key] = true
+	}
+	return superseded
+}
+
+type opKind int
+
+const (
+	opCreateVertex opKind = iota
+	opDeleteVertices
+	opSetVertexProperty
+	opDeleteVertexProperty
+	opCreateEdge
+	opDeleteEdges
+	opSetEdgeProperty
+)
+
+// planOp is a single recorded write.
+type planOp struct {
+	kind   opKind
+	vertex *pb.Vertex
+	vq     *pb.VertexQuery
+	edge   *pb.EdgeKey
+	eq     *pb.EdgeQuery
+	name   string
+	value  json.RawMessage
+	// changes is set once the op is known to change the graph.
+	changes bool
+}
+
+func (op *planOp) apply(ctx context.Context, graph citygraph.GraphClient) error {
+	switch op.kind {
+	case opCreateVertex:
+		return graph.CreateVertex(ctx, op.vertex.Id, op.vertex.T)
+	case opDeleteVertices:
+		return graph.DeleteVertices(ctx, op.vq)
+	case opSetVertexProperty:
+		return graph.SetVertexProperties(ctx, op.vq, op.name, op.value)
+	case opDeleteVertexProperty:
+		return citygraph.DeleteVertexProperties(ctx, graph, op.vq, op.name)
+	case opCreateEdge:
+		return graph.CreateEdge(ctx, op.edge.OutboundId, op.edge.T, op.edge.InboundId)
+	case opDeleteEdges:
+		return graph.DeleteEdges(ctx, op.eq)
+	case opSetEdgeProperty:
+		return graph.SetEdgeProperties(ctx, op.eq, op.name, op.value)
+	}
+	return fmt.Errorf("unknown plan op %d", op.kind)
+}
+
+// diff compares the op against the current graph and returns the changes it
+// would make.
+func (op *planOp) diff(ctx context.Context, graph citygraph.GraphClient) ([]*Change, error) {
+	switch op.kind {
+	case opCreateVertex:
+		existing, err := graph.GetVertices(ctx, citygraph.NewSpecificVertexQuery(op.vertex.Id))
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) > 0 {
+			return nil, nil
+		}
+		return []*Change{{Action: ActionAdd, Target: describeVertex(op.vertex)}}, nil
+	case opDeleteVertices:
+		existing, err := graph.GetVertices(ctx, op.vq)
+		if err != nil {
+			return nil, err
+		}
+		var changes []*Change
+		for _, v := range existing {
+			changes = append(changes, &Change{Action: ActionRemove, Target: describeVertex(v)})
+		}
+		return changes, nil
+	case opSetVertexProperty, opDeleteVertexProperty:
+		var changes []*Change
+		for _, id := range op.vertexIDs() {
+			current, err := graph.GetVertexProperties(ctx, citygraph.NewSpecificVertexQuery(id), op.name)
+			if err != nil {
+				return nil, err
+			}
+			target := fmt.Sprintf("property %s on vertex %s", op.name, uuidString(id))
+			var before json.RawMessage
+			if len(current) > 0 {
+				before = json.RawMessage(current[0].Value.GetValue())
+			}
+			switch {
+			case op.kind == opDeleteVertexProperty && before != nil:
+				changes = append(changes, &Change{Action: ActionRemove, Target: target, Before: before})
+			case op.kind == opDeleteVertexProperty:
+			case before == nil:
+				changes = append(changes, &Change{Action: ActionAdd, Target: target, After: op.value})
+			case !jsonEqual(before, op.value):
+				changes = append(changes, &Change{Action: ActionChange, Target: target, Before: before, After: op.value})
+			}
+		}
+		return changes, nil
+	case opCreateEdge:
+		existing, err := graph.GetEdges(ctx, citygraph.NewSpecificEdgeQuery(op.edge))
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) > 0 {
+			return nil, nil
+		}
+		return []*Change{{Action: ActionAdd, Target: describeEdge(op.edge)}}, nil
+	case opDeleteEdges:
+		existing, err := graph.GetEdges(ctx, op.eq)
+		if err != nil {
+			return nil, err
+		}
+		var changes []*Change
+		for _, e := range existing {
+			changes = append(changes, &Change{Action: ActionRemove, Target: describeEdge(e.Key)})
+		}
+		return changes, nil
+	case opSetEdgeProperty:
+		current, err := graph.GetEdgeProperties(ctx, op.eq, op.name)
+		if err != nil {
+			return nil, err
+		}
+		if len(current) == 0 {
+			return []*Change{{Action: ActionAdd, Target: fmt.Sprintf("edge property %s", op.name), After: op.value}}, nil
+		}
+		var changes []*Change
+		for _, prop := range current {
+			before := json.RawMessage(prop.Value.GetValue())
+			if jsonEqual(before, op.value) {
+				continue
+			}
+			changes = append(changes, &Change{
+				Action: ActionChange,
+				Target: fmt.Sprintf("property %s on %s", op.name, describeEdge(prop.Key)),
+				Before: before,
+				After:  op.value,
+			})
+		}
+		return changes, nil
+	}
+	return nil, fmt.Errorf("unknown plan op %d", op.kind)
+}
+
+// vertexIDs returns the vertices a property op targets. Only specific vertex
+// queries are supported when planning property writes.
+func (op *planOp) vertexIDs() []*pb.Uuid {
+	return op.vq.GetSpecific().GetIds()
+}
+
+func describeVertex(v *pb.Vertex) string {
+	return fmt.Sprintf("vertex %s (%s)", uuidString(v.GetId()), v.GetT().GetValue())
+}
+
+func describeEdge(e *pb.EdgeKey) string {
+	return fmt.Sprintf("edge %s -%s-> %s", uuidString(e.GetOutboundId()), e.GetT().GetValue(), uuidString(e.GetInboundId()))
+}
+
+// recordingClient passes reads through to the graph and records writes
+// instead of making them. Property reads of specific vertices see values
+// recorded earlier.
+type recordingClient struct {
+	citygraph.GraphClient
+	ops []*planOp
+}
+
+func (r *recordingClient) CreateVertex(ctx context.Context, id *pb.Uuid, t *pb.Identifier) error {
+	r.ops = append(r.ops, &planOp{kind: opCreateVertex, vertex: &pb.Vertex{Id: id, T: t}})
+	return nil
+}
+
+func (r *recordingClient) CreateVertexFromType(ctx context.Context, t *pb.Identifier) (*pb.Uuid, error) {
+	id := citygraph.UUID(citygraph.NewID())
+	return id, r.CreateVertex(ctx, id, t)
+}
+
+func (r *recordingClient) DeleteVertices(ctx context.Context, q *pb.VertexQuery) error {
+	r.ops = append(r.ops, &planOp{kind: opDeleteVertices, vq: q})
+	return nil
+}
+
+func (r *recordingClient) SetVertexProperties(ctx context.Context, q *pb.VertexQuery, name string, jsonValue interface{}) error {
+	if q.GetSpecific() == nil {
+		return errors.New("plan: only specific vertex queries are supported for property writes")
+	}
+	b, err := json.Marshal(jsonValue)
+	if err != nil {
+		return err
+	}
+	r.ops = append(r.ops, &planOp{kind: opSetVertexProperty, vq: q, name: name, value: b})
+	return nil
+}
+
+func (r *recordingClient) DeleteVertexProperties(ctx context.Context, q *pb.VertexQuery, name string) error {
+	if q.GetSpecific() == nil {
+		return errors.New("plan: only specific vertex queries are supported for property writes")
+	}
+	r.ops = append(r.ops, &planOp{kind: opDeleteVertexProperty, vq: q, name: name})
+	return nil
+}
+
+func (r *recordingClient) CreateEdge(ctx context.Context, outbound *pb.Uuid, t *pb.Identifier, inbound *pb.Uuid) error {
+	r.ops = append(r.ops, &planOp{kind: opCreateEdge, edge: &pb.EdgeKey{OutboundId: outbound, T: t, InboundId: inbound}})
+	return nil
+}
+
+func (r *recordingClient) DeleteEdges(ctx context.Context, q *pb.EdgeQuery) error {
+	r.ops = append(r.ops, &planOp{kind: opDeleteEdges, eq: q})
+	return nil
+}
+
+func (r *recordingClient) SetEdgeProperties(ctx context.Context, q *pb.EdgeQuery, name string, jsonValue interface{}) error {
+	b, err := json.Marshal(jsonValue)
+	if err != nil {
+		return err
+	}
+	r.ops = append(r.ops, &planOp{kind: opSetEdgeProperty, eq: q, name: name, value: b})
+	return nil
+}
+
+func (r *recordingClient) NewBulkSender(ctx context.Context) (citygraph.BulkSender, error) {
+	return nil, errors.New("plan: bulk inserts can't be planned")
+}
+
+// GetVertexProperties returns the last recorded value for a single specific
+// vertex if there is one, and otherwise reads from the graph.
+func (r *recordingClient) GetVertexProperties(ctx context.Context, q *pb.VertexQuery, name string) ([]*pb.VertexProperty, error) {
+	if ids := q.GetSpecific().GetIds(); len(ids) == 1 {
+		if value, deleted, ok := r.pending(ids[0], name); ok {
+			if deleted {
+				return nil, nil
+			}
+			return []*pb.VertexProperty{{Id: ids[0], Value: &pb.Json{Value: string(value)}}}, nil
+		}
+	}
+	return r.GraphClient.GetVertexProperties(ctx, q, name)
+}
+
+// GetAllVertexProperties overlays recorded values on the properties read
+// from the graph for a single specific vertex.
+func (r *recordingClient) GetAllVertexProperties(ctx context.Context, q *pb.VertexQuery) ([]*pb.VertexProperties, error) {
+	all, err := r.GraphClient.GetAllVertexProperties(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	ids := q.GetSpecific().GetIds()
+	if len(ids) != 1 || len(all) > 1 {
+		return all, nil
+	}
+	var props []*pb.NamedProperty
+	seen := map[string]bool{}
+	if len(all) == 1 {
+		for _, prop := range all[0].Props {
+			seen[prop.Name.GetValue()] = true
+			value, deleted, ok := r.pending(ids[0], prop.Name.GetValue())
+			switch {
+			case !ok:
+				props = append(props, prop)
+			case !deleted:
+				props = append(props, &pb.NamedProperty{Name: prop.Name, Value: &pb.Json{Value: string(value)}})
+			}
+		}
+	}
+	for _, op := range r.ops {
+		if op.kind != opSetVertexProperty || seen[op.name] || !targets(op.vq, ids[0]) {
+			continue
+		}
+		if value, deleted, _ := r.pending(ids[0], op.name); !deleted {
+			seen[op.name] = true
+			props = append(props, &pb.NamedProperty{Name: &pb.Identifier{Value: op.name}, Value: &pb.Json{Value: string(value)}})
+		}
+	}
+	if len(props) == 0 {
+		return all, nil
+	}
+	vertex := &pb.Vertex{Id: ids[0]}
+	if len(all) == 1 {
+		vertex = all[0].Vertex
+	}
+	return []*pb.VertexProperties{{Vertex: vertex, Props: props}}, nil
+}
+
+// pending returns the last recorded write to a vertex property, if any.
+func (r *recordingClient) pending(id *pb.Uuid, name string) (value json.RawMessage, deleted, ok bool) {
+	for i := len(r.ops) - 1; i >= 0; i-- {
+		op := r.ops[i]
+		if op.name != name || !targets(op.vq, id) {
+			continue
+		}
+		switch op.kind {
+		case opSetVertexProperty:
+			return op.value, false, true
+		case opDeleteVertexProperty:
+			return nil, true, true
+		}
+	}
+	return nil, false, false
+}
+
+func targets(q *pb.VertexQuery, id *pb.Uuid) bool {
+	for _, qid := range q.GetSpecific().GetIds() {
+		if string(qid.GetValue()) == string(id.GetValue()) {
+			return true
+		}
+	}
+	return false
+}
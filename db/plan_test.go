@@ -0,0 +1,104 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/graphtest"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+func TestPlanAndApply(t *testing.T) {
+	aID, bID := citygraph.NewID(), citygraph.NewID()
+	aUUID, bUUID := citygraph.UUID(aID), citygraph.UUID(bID)
+	avq := citygraph.NewSpecificVertexQuery(aUUID)
+	related := &pb.EdgeKey{OutboundId: bUUID, T: &citygraph.IsRelated, InboundId: aUUID}
+
+	write := func(ctx context.Context, s *Store) error {
+		if err := s.WriteBodyText(ctx, avq, "first draft"); err != nil {
+			return err
+		}
+		// Only the last write to a property is planned.
+		if err := s.WriteBodyText(ctx, avq, "new body"); err != nil {
+			return err
+		}
+		if err := s.WriteJS(ctx, avq, "unchanged"); err != nil {
+			return err
+		}
+		if err := s.WriteTeaserJS(ctx, avq, "teaser js"); err != nil {
+			return err
+		}
+		return s.CreateEdge(ctx, bUUID, &citygraph.IsRelated, aUUID)
+	}
+	newFake := func() *graphtest.FakeGraphClient {
+		return &graphtest.FakeGraphClient{
+			GetVertexPropertiesResps: [][]*pb.VertexProperty{
+				{{Id: aUUID, Value: citygraph.StringVal("old body")}},
+				{{Id: aUUID, Value: citygraph.StringVal("unchanged")}},
+				nil,
+			},
+			GetEdgesResps: [][]*pb.Edge{{}},
+		}
+	}
+
+	wantPlan := strings.Join([]string{
+		"~ property body on vertex " + aID.String(),
+		`    before: "old body"`,
+		`    after:  "new body"`,
+		"+ property teaser_function on vertex " + aID.String(),
+		`    after:  "teaser js"`,
+		"+ edge " + bID.String() + " -is-related-> " + aID.String(),
+		"Plan: 2 to add, 1 to change, 0 to remove.",
+		"",
+	}, "\n")
+
+	t.Run("declined", func(t *testing.T) {
+		fakeGraph := newFake()
+		store := &Store{GraphClient: fakeGraph}
+		var out bytes.Buffer
+		applied, err := store.PlanAndApply(context.Background(), write, strings.NewReader("n\n"), &out)
+		if err != nil {
+			t.Fatalf("store.PlanAndApply() returned err: %v", err)
+		}
+		if applied {
+			t.Errorf("store.PlanAndApply() applied a declined plan")
+		}
+		if !strings.HasPrefix(out.String(), wantPlan) {
+			t.Errorf("store.PlanAndApply() printed:\n%s\nwant prefix:\n%s", out.String(), wantPlan)
+		}
+		if len(fakeGraph.SetVertexPropertiesReqs) != 0 || len(fakeGraph.CreateEdgeReqs) != 0 {
+			t.Errorf("store.PlanAndApply() wrote to the graph before confirmation")
+		}
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		fakeGraph := newFake()
+		store := &Store{GraphClient: fakeGraph}
+		applied, err := store.PlanAndApply(context.Background(), write, strings.NewReader("yes\n"), &bytes.Buffer{})
+		if err != nil {
+			t.Fatalf("store.PlanAndApply() returned err: %v", err)
+		}
+		if !applied {
+			t.Errorf("store.PlanAndApply() did not apply a confirmed plan")
+		}
+		wantSetVertexPropertiesReqs := []*pb.SetVertexPropertiesRequest{{
+			Q:     citygraph.NewVertexPropertyQuery(avq, citygraph.PropertyNameBodyText),
+			Value: citygraph.StringVal("new body"),
+		}, {
+			Q:     citygraph.NewVertexPropertyQuery(avq, "teaser_function"),
+			Value: citygraph.StringVal("teaser js"),
+		}}
+		if diff := cmp.Diff(wantSetVertexPropertiesReqs, fakeGraph.SetVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+			t.Errorf("store.PlanAndApply() sent set vertex properties req diff:\n%s\n", diff)
+		}
+		if diff := cmp.Diff([]*pb.EdgeKey{related}, fakeGraph.CreateEdgeReqs, protocmp.Transform()); diff != "" {
+			t.Errorf("store.PlanAndApply() sent create edge req diff:\n%s\n", diff)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph"
+)
+
+// ErrArticleNotFound is returned when no article matches an ID or path.
+var ErrArticleNotFound = errors.New("article not found")
+
+// ReadArticle reads an article back from the properties on its vertex.
+// Content kept outside the vertex properties, like related articles and
+// datasets, is not read. Articles stored without an is_live property, from
+// before it was written, read as live.
+func (s *Store) ReadArticle(ctx context.Context, id uuid.UUID) (*citygraph.Article, error) {
+	article, _, err := s.readArticle(ctx, id)
+	return article, err
+}
+
+// ResolveArticlePath finds the article a request path refers to. The path's
+// slug title must be the article's current slug title or one listed in its
+// slug_titles property, or be missing. redirect reports whether the path
+// differs from the article's canonical path and the request should be
+// redirected there. Articles that aren't live, because they were never
+// published or were taken down, are not found; use ReadArticle to preview
+// them.
+func (s *Store) ResolveArticlePath(ctx context.Context, path string) (article *citygraph.Article, redirect bool, err error) {
+	slugID, slugTitle, err := citygraph.ParseArticlePath(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrArticleNotFound, err)
+	}
+	id, err := citygraph.ParseSlugID(slugID)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrArticleNotFound, err)
+	}
+	article, slugTitles, err := s.readArticle(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if !article.IsLive {
+		return nil, false, fmt.Errorf("%w: article %s is not live", ErrArticleNotFound, id)
+	}
+	if slugTitle != "" && slugTitle != article.SlugTitle() && !contains(slugTitles, slugTitle) {
+		return nil, false, fmt.Errorf("%w: %q is not a slug title of article %s", ErrArticleNotFound, slugTitle, id)
+	}
+	canonical, err := article.Path()
+	if err != nil {
+		return nil, false, err
+	}
+	return article, path != canonical, nil
+}
+
+// readArticle returns the article with the given ID and the slug titles
+// recorded for it.
+func (s *Store) readArticle(ctx context.Context, id uuid.UUID) (*citygraph.Article, []string, error) {
+	all, err := s.GetAllVertexProperties(ctx, citygraph.NewSpecificVertexQuery(citygraph.UUID(id)))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(all) == 0 || all[0].Vertex.GetT().GetValue() != citygraph.ArticleType.Value {
+		return nil, nil, fmt.Errorf("%w: %s", ErrArticleNotFound, id)
+	}
+
+	article := &citygraph.Article{ID: id.String(), IsLive: true}
+	var slugTitles []string
+	fields := map[string]interface{}{
+		citygraph.PropertyNameDisplayName: &article.Name,
+		"past_names":                      &article.PastNames,
+		"headline_html":                   &article.Headline,
+		"slug_titles":                     &slugTitles,
+		"creators":                        &article.Authors,
+		"camera":                          &article.Camera,
+		"published_on":                    &article.PubDate,
+		citygraph.PropertyNameUpdatedAt:   &article.LastUpdated,
+		"categories":                      &article.Categories,
+		"code_credit":                     &article.CodeCredit,
+		"h2":                              &article.Description,
+		citygraph.PropertyNameImgURL:      &article.FeatureImage,
+		"pitch":                           &article.Pitch,
+		"teaser":                          &article.Teaser,
+		citygraph.PropertyNameFormat:      &article.Format,
+		"is_live":                         &article.IsLive,
+	}
+	for _, prop := range all[0].Props {
+		field, ok := fields[prop.Name.GetValue()]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal([]byte(prop.Value.GetValue()), field); err != nil {
+			return nil, nil, fmt.Errorf("article %s: %s: %w", id, prop.Name.GetValue(), err)
+		}
+	}
+	return article, slugTitles, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/graphtest"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+func TestResolveArticlePath(t *testing.T) {
+	id := citygraph.NewID()
+	article := &citygraph.Article{ID: id.String(), Name: "Bike lanes come to Bloor", Headline: "<p>Bike lanes come to Bloor</p>"}
+	slugID, err := article.SlugID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := article.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vertex := []*pb.VertexProperties{{
+		Vertex: &pb.Vertex{Id: citygraph.UUID(id), T: citygraph.ArticleType},
+		Props: namedProps(map[string]string{
+			"display_name": `"Bike lanes come to Bloor"`,
+			"past_names":   `["Bloor bike lanes approved"]`,
+			"slug_titles":  `["bloor-bike-lanes-approved","bike-lanes-come-to-bloor"]`,
+			"published_on": `"2022-06-14T00:00:00-04:00"`,
+			"is_live":      `true`,
+		}),
+	}}
+
+	for _, tc := range []struct {
+		path         string
+		wantRedirect bool
+		wantErr      error
+	}{
+		{path: canonical},
+		{path: "/articles/" + slugID + "/bloor-bike-lanes-approved", wantRedirect: true},
+		{path: "/articles/" + slugID, wantRedirect: true},
+		{path: "/articles/" + slugID + "/something-else", wantErr: ErrArticleNotFound},
+		{path: "/modules/" + slugID + "/bike-lanes-come-to-bloor", wantErr: ErrArticleNotFound},
+	} {
+		store := &Store{GraphClient: &graphtest.FakeGraphClient{
+			GetAllVertexPropertiesResps: [][]*pb.VertexProperties{vertex},
+		}}
+		got, redirect, err := store.ResolveArticlePath(context.Background(), tc.path)
+		if tc.wantErr != nil {
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("store.ResolveArticlePath(%q) returned err %v, want %v", tc.path, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("store.ResolveArticlePath(%q) returned err: %v", tc.path, err)
+			continue
+		}
+		if got.ID != id.String() || got.Name != article.Name || !got.IsLive || got.PubDate.IsZero() {
+			t.Errorf("store.ResolveArticlePath(%q) = %+v", tc.path, got)
+		}
+		if redirect != tc.wantRedirect {
+			t.Errorf("store.ResolveArticlePath(%q) redirect = %t, want %t", tc.path, redirect, tc.wantRedirect)
+		}
+	}
+}
+
+func TestResolveArticlePathNotLive(t *testing.T) {
+	ctx := context.Background()
+	id := citygraph.NewID()
+	article := &citygraph.Article{ID: id.String(), Name: "Bike lanes come to Bloor", Headline: "<p>Bike lanes come to Bloor</p>"}
+	path, err := article.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &Store{GraphClient: graphtest.NewMemoryGraphClient()}
+	if err := store.WriteArticle(ctx, article); err != nil {
+		t.Fatalf("store.WriteArticle() returned err: %v", err)
+	}
+	if _, _, err := store.ResolveArticlePath(ctx, path); !errors.Is(err, ErrArticleNotFound) {
+		t.Errorf("store.ResolveArticlePath() of an unpublished article returned err %v, want ErrArticleNotFound", err)
+	}
+
+	article.IsLive = true
+	if err := store.WriteArticle(ctx, article); err != nil {
+		t.Fatalf("store.WriteArticle() returned err: %v", err)
+	}
+	if _, _, err := store.ResolveArticlePath(ctx, path); err != nil {
+		t.Errorf("store.ResolveArticlePath() of a live article returned err: %v", err)
+	}
+
+	if _, err := store.UnpublishArticle(ctx, id, nil); err != nil {
+		t.Fatalf("store.UnpublishArticle() returned err: %v", err)
+	}
+	if _, _, err := store.ResolveArticlePath(ctx, path); !errors.Is(err, ErrArticleNotFound) {
+		t.Errorf("store.ResolveArticlePath() of a taken down article returned err %v, want ErrArticleNotFound", err)
+	}
+
+	// Articles stored before is_live was written count as live.
+	if err := citygraph.DeleteVertexProperties(ctx, store.GraphClient, citygraph.NewSpecificVertexQuery(citygraph.UUID(id)), "is_live"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.ResolveArticlePath(ctx, path); err != nil {
+		t.Errorf("store.ResolveArticlePath() of an article without is_live returned err: %v", err)
+	}
+}
+
+func TestReadArticleNotFound(t *testing.T) {
+	store := &Store{GraphClient: &graphtest.FakeGraphClient{
+		GetAllVertexPropertiesResps: [][]*pb.VertexProperties{nil},
+	}}
+	if _, err := store.ReadArticle(context.Background(), citygraph.NewID()); !errors.Is(err, ErrArticleNotFound) {
+		t.Errorf("store.ReadArticle() returned err %v, want ErrArticleNotFound", err)
+	}
+}
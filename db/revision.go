@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+// Revision is a snapshot of an article's properties, taken just before they
+// were overwritten.
+type Revision struct {
+	ID        uuid.UUID
+	ArticleID uuid.UUID
+	CreatedAt time.Time
+	// Properties holds the article's vertex properties as they were, keyed by
+	// property name.
+	Properties map[string]json.RawMessage
+}
+
+// PropertyChange describes how one property differs between two revisions.
+// Before is nil for an added property and After is nil for a removed one.
+type PropertyChange struct {
+	Name   string
+	Before json.RawMessage
+	After  json.RawMessage
+}
+
+// ListArticleRevisions returns the saved revisions of an article, oldest
+// first.
+func (s *Store) ListArticleRevisions(ctx context.Context, articleID uuid.UUID) ([]*Revision, error) {
+	avq := citygraph.NewSpecificVertexQuery(citygraph.UUID(articleID))
+	edges, err := s.edgeKeys(ctx, citygraph.NewPipeEdgeQuery(avq, pb.EdgeDirection_OUTBOUND, &citygraph.HasRevision))
+	if err != nil {
+		return nil, err
+	}
+	if len(edges) == 0 {
+		return nil, nil
+	}
+	ids := make([]*pb.Uuid, 0, len(edges))
+	for _, e := range edges {
+		ids = append(ids, e.InboundId)
+	}
+	all, err := s.GetAllVertexProperties(ctx, citygraph.NewSpecificVertexQuery(ids...))
+	if err != nil {
+		return nil, err
+	}
+	var revisions []*Revision
+	for _, vp := range all {
+		rev, err := decodeRevision(vp)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].CreatedAt.Before(revisions[j].CreatedAt)
+	})
+	return revisions, nil
+}
+
+// ReadRevision returns a single revision by ID.
+func (s *Store) ReadRevision(ctx context.Context, revisionID uuid.UUID) (*Revision, error) {
+	all, err := s.GetAllVertexProperties(ctx, citygraph.NewSpecificVertexQuery(citygraph.UUID(revisionID)))
+	if err != nil {
+		return nil, err
+	}
+	if len(all) == 0 {
+		return nil, fmt.Errorf("revision %s not found", revisionID)
+	}
+	return decodeRevision(all[0])
+}
+
+// DiffRevisions compares two revisions property by property, returning the
+// changes needed to get from a to b ordered by property name.
+func DiffRevisions(a, b *Revision) []*PropertyChange {
+	names := map[string]bool{}
+	for name := range a.Properties {
+		names[name] = true
+	}
+	for name := range b.Properties {
+		names[name] = true
+	}
+	var changes []*PropertyChange
+	for name := range names {
+		before, after := a.Properties[name], b.Properties[name]
+		if before != nil && after != nil && jsonEqual(before, after) {
+			continue
+		}
+		changes = append(changes, &PropertyChange{Name: name, Before: before, After: after})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// RestoreArticleRevision sets an article's properties back to those saved in
+// the given revision. The article's current properties are saved as a new
+// revision first, so a restore can itself be undone.
+func (s *Store) RestoreArticleRevision(ctx context.Context, articleID, revisionID uuid.UUID) error {
+	rev, err := s.ReadRevision(ctx, revisionID)
+	if err != nil {
+		return err
+	}
+	if rev.ArticleID != articleID {
+		return fmt.Errorf("revision %s belongs to %s, not %s", revisionID, rev.ArticleID, articleID)
+	}
+
+	avq := citygraph.NewSpecificVertexQuery(citygraph.UUID(articleID))
+	current, err := s.vertexProperties(ctx, avq)
+	if err != nil {
+		return err
+	}
+	if len(current) > 0 {
+		if err := s.writeRevision(ctx, articleID, current); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(rev.Properties))
+	for name := range rev.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := s.SetVertexProperties(ctx, avq, name, rev.Properties[name]); err != nil {
+			return err
+		}
+	}
+	var stale []string
+	for name := range current {
+		if _, ok := rev.Properties[name]; !ok {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	for _, name := range stale {
+		if err := citygraph.DeleteVertexProperties(ctx, s.GraphClient, avq, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotIfChanged saves the article's current properties as a revision if
+// writing props would change any of them. Nothing is saved for a new article.
+func (s *Store) snapshotIfChanged(ctx context.Context, articleID uuid.UUID, props []vertexProperty) error {
+	current, err := s.vertexProperties(ctx, citygraph.NewSpecificVertexQuery(citygraph.UUID(articleID)))
+	if err != nil {
+		return err
+	}
+	if len(current) == 0 {
+		return nil
+	}
+	for _, prop := range props {
+		want, err := json.Marshal(prop.value)
+		if err != nil {
+			return err
+		}
+		if have, ok := current[prop.name]; !ok || !jsonEqual(have, want) {
+			return s.writeRevision(ctx, articleID, current)
+		}
+	}
+	return nil
+}
+
+func (s *Store) writeRevision(ctx context.Context, articleID uuid.UUID, props map[string]json.RawMessage) error {
+	revID := citygraph.NewID()
+	if err := s.CreateVertex(ctx, citygraph.UUID(revID), &citygraph.NewsRevision); err != nil {
+		return err
+	}
+	rvq := citygraph.NewSpecificVertexQuery(citygraph.UUID(revID))
+	if err := s.SetVertexProperties(ctx, rvq, "revision_of", articleID.String()); err != nil {
+		return err
+	}
+	if err := s.SetVertexProperties(ctx, rvq, "created_at", time.Now().UTC()); err != nil {
+		return err
+	}
+	if err := s.SetVertexProperties(ctx, rvq, "properties", props); err != nil {
+		return err
+	}
+	return s.CreateEdge(ctx, citygraph.UUID(articleID), &citygraph.HasRevision, citygraph.UUID(revID))
+}
+
+// vertexProperties returns all properties of the first vertex matching q,
+// keyed by name.
+func (s *Store) vertexProperties(ctx context.Context, q *pb.VertexQuery) (map[string]json.RawMessage, error) {
+	all, err := s.GetAllVertexProperties(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	props := map[string]json.RawMessage{}
+	if len(all) == 0 {
+		return props, nil
+	}
+	for _, prop := range all[0].Props {
+		props[prop.Name.GetValue()] = json.RawMessage(prop.Value.GetValue())
+	}
+	return props, nil
+}
+
+func decodeRevision(vp *pb.VertexProperties) (*Revision, error) {
+	id, err := uuid.FromBytes(vp.Vertex.GetId().GetValue())
+	if err != nil {
+		return nil, fmt.Errorf("revision id: %w", err)
+	}
+	rev := &Revision{ID: id}
+	for _, prop := range vp.Props {
+		value := []byte(prop.Value.GetValue())
+		switch prop.Name.GetValue() {
+		case "revision_of":
+			var articleID string
+			if err := json.Unmarshal(value, &articleID); err != nil {
+				return nil, fmt.Errorf("revision %s: revision_of: %w", id, err)
+			}
+			if rev.ArticleID, err = uuid.Parse(articleID); err != nil {
+				return nil, fmt.Errorf("revision %s: revision_of: %w", id, err)
+			}
+		case "created_at":
+			if err := json.Unmarshal(value, &rev.CreatedAt); err != nil {
+				return nil, fmt.Errorf("revision %s: created_at: %w", id, err)
+			}
+		case "properties":
+			if err := json.Unmarshal(value, &rev.Properties); err != nil {
+				return nil, fmt.Errorf("revision %s: properties: %w", id, err)
+			}
+		}
+	}
+	return rev, nil
+}
+
+// jsonEqual reports whether two JSON documents hold the same value,
+// regardless of formatting.
+func jsonEqual(a, b []byte) bool {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/graphtest"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+func namedProps(props map[string]string) []*pb.NamedProperty {
+	var named []*pb.NamedProperty
+	for name, value := range props {
+		named = append(named, &pb.NamedProperty{Name: &pb.Identifier{Value: name}, Value: &pb.Json{Value: value}})
+	}
+	return named
+}
+
+func TestWriteArticleSnapshotsChanges(t *testing.T) {
+	aID := citygraph.NewID()
+	aUUID := citygraph.UUID(aID)
+	article := &citygraph.Article{
+		ID:       aID.String(),
+		Name:     "New headline",
+		Headline: "New headline",
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		props, err := articleProperties(article)
+		if err != nil {
+			t.Fatal(err)
+		}
+		current := map[string]string{}
+		for _, prop := range props {
+			b, _ := json.Marshal(prop.value)
+			current[prop.name] = string(b)
+		}
+		fakeGraph := &graphtest.FakeGraphClient{
+			GetAllVertexPropertiesResps: [][]*pb.VertexProperties{{{
+				Vertex: &pb.Vertex{Id: aUUID, T: citygraph.ArticleType},
+				Props:  namedProps(current),
+			}}},
+			GetEdgesResps: [][]*pb.Edge{nil, nil},
+		}
+		store := &Store{GraphClient: fakeGraph}
+		if err := store.WriteArticle(context.Background(), article); err != nil {
+			t.Fatalf("store.WriteArticle() returned err: %v", err)
+		}
+		if len(fakeGraph.CreateVertexReqs) != 1 || len(fakeGraph.CreateEdgeReqs) != 0 {
+			t.Errorf("store.WriteArticle() saved a revision for an unchanged article")
+		}
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		fakeGraph := &graphtest.FakeGraphClient{
+			GetAllVertexPropertiesResps: [][]*pb.VertexProperties{{{
+				Vertex: &pb.Vertex{Id: aUUID, T: citygraph.ArticleType},
+				Props:  namedProps(map[string]string{"display_name": `"Old headline"`}),
+			}}},
+			GetEdgesResps: [][]*pb.Edge{nil, nil},
+		}
+		store := &Store{GraphClient: fakeGraph}
+		if err := store.WriteArticle(context.Background(), article); err != nil {
+			t.Fatalf("store.WriteArticle() returned err: %v", err)
+		}
+
+		if len(fakeGraph.CreateVertexReqs) != 2 {
+			t.Fatalf("store.WriteArticle() created %d vertices, want article and revision", len(fakeGraph.CreateVertexReqs))
+		}
+		rev := fakeGraph.CreateVertexReqs[1]
+		if diff := cmp.Diff(&citygraph.NewsRevision, rev.T, protocmp.Transform()); diff != "" {
+			t.Errorf("store.WriteArticle() revision vertex type diff:\n%s\n", diff)
+		}
+		wantCreateEdgeReqs := []*pb.EdgeKey{{OutboundId: aUUID, T: &citygraph.HasRevision, InboundId: rev.Id}}
+		if diff := cmp.Diff(wantCreateEdgeReqs, fakeGraph.CreateEdgeReqs, protocmp.Transform()); diff != "" {
+			t.Errorf("store.WriteArticle() sent graph create edge req diff:\n%s\n", diff)
+		}
+
+		rvq := citygraph.NewSpecificVertexQuery(rev.Id)
+		var gotSnapshot *pb.SetVertexPropertiesRequest
+		for _, req := range fakeGraph.SetVertexPropertiesReqs {
+			if cmp.Equal(req.Q, citygraph.NewVertexPropertyQuery(rvq, "properties"), protocmp.Transform()) {
+				gotSnapshot = req
+			}
+		}
+		if gotSnapshot == nil {
+			t.Fatal("store.WriteArticle() did not save revision properties")
+		}
+		if want := `{"display_name":"Old headline"}`; gotSnapshot.Value.Value != want {
+			t.Errorf("store.WriteArticle() saved revision properties %s, want %s", gotSnapshot.Value.Value, want)
+		}
+	})
+}
+
+func TestDiffRevisions(t *testing.T) {
+	a := &Revision{Properties: map[string]json.RawMessage{
+		"display_name": json.RawMessage(`"Old"`),
+		"pitch":        json.RawMessage(`85`),
+		"format":       json.RawMessage(`"content-map"`),
+	}}
+	b := &Revision{Properties: map[string]json.RawMessage{
+		"display_name": json.RawMessage(`"New"`),
+		"pitch":        json.RawMessage(`85.0`),
+		"h2":           json.RawMessage(`"Subhead"`),
+	}}
+	want := []*PropertyChange{
+		{Name: "display_name", Before: json.RawMessage(`"Old"`), After: json.RawMessage(`"New"`)},
+		{Name: "format", Before: json.RawMessage(`"content-map"`)},
+		{Name: "h2", After: json.RawMessage(`"Subhead"`)},
+	}
+	if diff := cmp.Diff(want, DiffRevisions(a, b)); diff != "" {
+		t.Errorf("DiffRevisions() diff:\n%s\n", diff)
+	}
+}
+
+func TestRestoreArticleRevision(t *testing.T) {
+	aID, revID := citygraph.NewID(), citygraph.NewID()
+	aUUID := citygraph.UUID(aID)
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetAllVertexPropertiesResps: [][]*pb.VertexProperties{{{
+			Vertex: &pb.Vertex{Id: citygraph.UUID(revID), T: &citygraph.NewsRevision},
+			Props: namedProps(map[string]string{
+				"revision_of": `"` + aID.String() + `"`,
+				"created_at":  `"2023-03-01T12:00:00Z"`,
+				"properties":  `{"display_name":"Old headline"}`,
+			}),
+		}}, {{
+			Vertex: &pb.Vertex{Id: aUUID, T: citygraph.ArticleType},
+			Props: namedProps(map[string]string{
+				"display_name": `"New headline"`,
+				"h2":           `"Added later"`,
+			}),
+		}}},
+	}
+	store := &Store{GraphClient: fakeGraph}
+
+	if err := store.RestoreArticleRevision(context.Background(), aID, revID); err != nil {
+		t.Fatalf("store.RestoreArticleRevision() returned err: %v", err)
+	}
+
+	avq := citygraph.NewSpecificVertexQuery(aUUID)
+	if len(fakeGraph.CreateEdgeReqs) != 1 {
+		t.Errorf("store.RestoreArticleRevision() did not save the current properties as a revision")
+	}
+	last := fakeGraph.SetVertexPropertiesReqs[len(fakeGraph.SetVertexPropertiesReqs)-1]
+	wantLast := &pb.SetVertexPropertiesRequest{
+		Q:     citygraph.NewVertexPropertyQuery(avq, "display_name"),
+		Value: citygraph.StringVal("Old headline"),
+	}
+	if diff := cmp.Diff(wantLast, last, protocmp.Transform()); diff != "" {
+		t.Errorf("store.RestoreArticleRevision() sent set vertex properties req diff:\n%s\n", diff)
+	}
+	wantDeleteReqs := []*pb.VertexPropertyQuery{citygraph.NewVertexPropertyQuery(avq, "h2")}
+	if diff := cmp.Diff(wantDeleteReqs, fakeGraph.DeleteVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.RestoreArticleRevision() sent delete vertex properties req diff:\n%s\n", diff)
+	}
+}
@@ -3,16 +3,65 @@ package db
 import (
 	"context"
 	"fmt"
-	"sort"
 
 	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/geojson"
 	"github.com/geomodulus/citygraph/pb"
 
 	"github.com/google/uuid"
 )
 
+// Store reads and writes content in the graph.
 type Store struct {
 	citygraph.GraphClient
+
+	// AllowInvalid lets writers store content that fails validation. By
+	// default writers return the *citygraph.ValidationError instead.
+	AllowInvalid bool
+
+	// URLs, if set, is used to write canonical_path and canonical_url
+	// properties on the vertices of pages: articles, modules, authors and
+	// categories.
+	URLs *citygraph.URLBuilder
+}
+
+// validate returns c's validation error unless the store allows invalid
+// content.
+func (s *Store) validate(c interface{ Validate() error }) error {
+	if s.AllowInvalid {
+		return nil
+	}
+	return c.Validate()
+}
+
+// canonicalProperties returns the canonical link properties for a page at
+// path, or nil if the store has no URL builder.
+func (s *Store) canonicalProperties(path string) []vertexProperty {
+	if s.URLs == nil {
+		return nil
+	}
+	return []vertexProperty{
+		{citygraph.PropertyNameCanonicalPath, path},
+		{citygraph.PropertyNameCanonicalURL, s.URLs.URL(path)},
+	}
+}
+
+// geoJSON decodes v as GeoJSON, rewinding polygon rings to the right-hand
+// rule, and validates it. If v is invalid and the store allows invalid
+// content, v is returned as is.
+func (s *Store) geoJSON(v interface{}) (interface{}, error) {
+	obj, err := geojson.FromValue(v)
+	if err == nil {
+		obj.Rewind()
+		err = obj.Validate()
+	}
+	if err != nil {
+		if s.AllowInvalid {
+			return v, nil
+		}
+		return nil, fmt.Errorf("invalid geojson: %w", err)
+	}
+	return obj, nil
 }
 
 func (s *Store) WriteBodyText(ctx context.Context, q *pb.VertexQuery, body string) error {
@@ -23,15 +72,69 @@ func (s *Store) WriteJS(ctx context.Context, q *pb.VertexQuery, fn string) error
 	return s.SetVertexProperties(ctx, q, citygraph.PropertyNameJSFunc, fn)
 }
 
+// WriteTeaserGeoJSON writes the teaser as typed GeoJSON, as with
+// WriteArticleGeoJSONDatasetWithData.
 func (s *Store) WriteTeaserGeoJSON(ctx context.Context, q *pb.VertexQuery, teaser map[string]interface{}) error {
-	return s.SetVertexProperties(ctx, q, "teaser", teaser)
+	obj, err := s.geoJSON(teaser)
+	if err != nil {
+		return fmt.Errorf("teaser: %w", err)
+	}
+	return s.SetVertexProperties(ctx, q, "teaser", obj)
 }
 
 func (s *Store) WriteTeaserJS(ctx context.Context, q *pb.VertexQuery, fn string) error {
 	return s.SetVertexProperties(ctx, q, "teaser_function", fn)
 }
 
+// vertexProperty is a named value to be written to a vertex.
+type vertexProperty struct {
+	name  string
+	value interface{}
+}
+
+// articleProperties returns the properties written to an article's vertex, in
+// the order they are written.
+func articleProperties(article *citygraph.Article) ([]vertexProperty, error) {
+	slugID, err := article.SlugID()
+	if err != nil {
+		return nil, err
+	}
+	props := []vertexProperty{
+		{citygraph.PropertyNameDisplayName, article.Name},
+		{"past_names", article.PastNames},
+		{"headline_html", article.Headline},
+		{"slug_id", slugID},
+		{"slug_title", article.SlugTitle()},
+		{"slug_titles", article.AllSlugTitles()},
+		{"creators", article.Authors},
+	}
+	if article.Camera != nil {
+		props = append(props, vertexProperty{"camera", article.Camera})
+	}
+	props = append(props,
+		vertexProperty{"published_on", article.PubDate},
+		vertexProperty{citygraph.PropertyNameUpdatedAt, article.LastUpdated},
+		vertexProperty{"categories", article.Categories},
+		vertexProperty{"code_credit", article.CodeCredit},
+		vertexProperty{"h2", article.Description},
+		vertexProperty{citygraph.PropertyNameImgURL, article.FeatureImage},
+		vertexProperty{"pitch", article.Pitch},
+		vertexProperty{"is_live", article.IsLive},
+	)
+	if len(article.Teaser) > 0 {
+		props = append(props, vertexProperty{"teaser", article.Teaser})
+	}
+	props = append(props, vertexProperty{"format", article.Format})
+	return props, nil
+}
+
+// WriteArticle writes the article's vertex, properties and related edges. If
+// the write changes an existing article, its previous properties are first
+// saved as a revision.
 func (s *Store) WriteArticle(ctx context.Context, article *citygraph.Article) error {
+	if err := s.validate(article); err != nil {
+		return err
+	}
 	id, err := article.UUID()
 	if err != nil {
 		return err
@@ -43,63 +146,28 @@ func (s *Store) WriteArticle(ctx context.Context, article *citygraph.Article) er
 	if err != nil {
 		return err
 	}
-	if err := s.SetVertexProperties(ctx, q, citygraph.PropertyNameDisplayName, article.Name); err != nil {
-		return err
-	}
-	if err := s.SetVertexProperties(ctx, q, "past_names", article.PastNames); err != nil {
-		return err
-	}
-	if err := s.SetVertexProperties(ctx, q, "headline_html", article.Headline); err != nil {
-		return err
-	}
-	slugID, err := article.SlugID()
+	props, err := articleProperties(article)
 	if err != nil {
 		return err
 	}
-	if err := s.SetVertexProperties(ctx, q, "slug_id", slugID); err != nil {
-		return err
-	}
-	if err := s.SetVertexProperties(ctx, q, "slug_title", article.SlugTitle()); err != nil {
-		return err
-	}
-	if err := s.SetVertexProperties(ctx, q, "slug_titles", article.AllSlugTitles()); err != nil {
+	path, err := article.Path()
+	if err != nil {
 		return err
 	}
-	if err := s.SetVertexProperties(ctx, q, "creators", article.Authors); err != nil {
-		return err
+	props = append(props, s.canonicalProperties(path)...)
+	if err := s.snapshotIfChanged(ctx, id, props); err != nil {
+		return fmt.Errorf("snapshot revision: %w", err)
 	}
-	if article.Camera != nil {
-		if err := s.SetVertexProperties(ctx, q, "camera", article.Camera); err != nil {
+	for _, prop := range props {
+		if err := s.SetVertexProperties(ctx, q, prop.name, prop.value); err != nil {
 			return err
 		}
 	}
-	if err := s.SetVertexProperties(ctx, q, "published_on", article.PubDate); err != nil {
-		return err
-	}
-	if err := s.SetVertexProperties(ctx, q, citygraph.PropertyNameUpdatedAt, article.LastUpdated); err != nil {
-		return err
-	}
-	if err := s.SetVertexProperties(ctx, q, "categories", article.Categories); err != nil {
-		return err
-	}
-	if err := s.SetVertexProperties(ctx, q, "code_credit", article.CodeCredit); err != nil {
-		return err
-	}
-	if err := s.SetVertexProperties(ctx, q, "h2", article.Description); err != nil {
-		return err
-	}
-	if err := s.SetVertexProperties(ctx, q, citygraph.PropertyNameImgURL, article.FeatureImage); err != nil {
-		return err
-	}
-	if err := s.SetVertexProperties(ctx, q, "pitch", article.Pitch); err != nil {
+
+	if err := s.writeCategories(ctx, id, article.Categories); err != nil {
 		return err
 	}
-	if len(article.Teaser) > 0 {
-		if err := s.SetVertexProperties(ctx, q, "teaser", article.Teaser); err != nil {
-			return err
-		}
-	}
-	if err := s.SetVertexProperties(ctx, q, "format", article.Format); err != nil {
+	if err := s.writeAuthors(ctx, id, article.Authors); err != nil {
 		return err
 	}
 
@@ -116,6 +184,9 @@ func (s *Store) WriteArticle(ctx context.Context, article *citygraph.Article) er
 }
 
 func (s *Store) WriteArticleGeoJSONDataset(ctx context.Context, aUUID uuid.UUID, dataset *citygraph.GeoJSONDataset) error {
+	if err := s.validate(dataset); err != nil {
+		return err
+	}
 	id, err := dataset.UUID()
 	if err != nil {
 		return err
@@ -143,7 +214,18 @@ func (s *Store) WriteArticleGeoJSONDataset(ctx context.Context, aUUID uuid.UUID,
 	return nil
 }
 
+// WriteArticleGeoJSONDatasetWithData writes the dataset like
+// WriteArticleGeoJSONDataset, storing data as its GeoJSON feature. data may be
+// a geojson.Object, encoded GeoJSON or a value that encodes to GeoJSON; it is
+// validated and written with polygon rings wound to the right-hand rule.
 func (s *Store) WriteArticleGeoJSONDatasetWithData(ctx context.Context, aUUID uuid.UUID, dataset *citygraph.GeoJSONDataset, data interface{}) error {
+	if err := s.validate(dataset); err != nil {
+		return err
+	}
+	feature, err := s.geoJSON(data)
+	if err != nil {
+		return fmt.Errorf("dataset %s: %w", dataset.ID, err)
+	}
 	id, err := dataset.UUID()
 	if err != nil {
 		return err
@@ -161,7 +243,7 @@ func (s *Store) WriteArticleGeoJSONDatasetWithData(ctx context.Context, aUUID uu
 	if err := s.SetVertexProperties(ctx, q, "sources", dataset.Sources); err != nil {
 		return err
 	}
-	if err := s.SetVertexProperties(ctx, q, citygraph.PropertyNameGeoJSONFeature, data); err != nil {
+	if err := s.SetVertexProperties(ctx, q, citygraph.PropertyNameGeoJSONFeature, feature); err != nil {
 		return err
 	}
 	if err := s.CreateEdge(ctx, citygraph.UUID(aUUID), &citygraph.IllustratedBy, citygraph.UUID(id)); err != nil {
@@ -171,6 +253,9 @@ func (s *Store) WriteArticleGeoJSONDatasetWithData(ctx context.Context, aUUID uu
 }
 
 func (s *Store) WriteModule(ctx context.Context, module *citygraph.Module) error {
+	if err := s.validate(module); err != nil {
+		return err
+	}
 	id, err := module.UUID()
 	if err != nil {
 		return err
@@ -198,6 +283,9 @@ func (s *Store) WriteModule(ctx context.Context, module *citygraph.Module) error
 	if err := s.SetVertexProperties(ctx, q, "slug_title", module.SlugTitle()); err != nil {
 		return err
 	}
+	if err := s.SetVertexProperties(ctx, q, "slug_titles", module.AllSlugTitles()); err != nil {
+		return err
+	}
 	if err := s.SetVertexProperties(ctx, q, "creators", module.Creators); err != nil {
 		return err
 	}
@@ -232,78 +320,200 @@ func (s *Store) WriteModule(ctx context.Context, module *citygraph.Module) error
 			return err
 		}
 	}
+	path, err := module.Path()
+	if err != nil {
+		return err
+	}
+	for _, prop := range s.canonicalProperties(path) {
+		if err := s.SetVertexProperties(ctx, q, prop.name, prop.value); err != nil {
+			return err
+		}
+	}
+	if err := s.writeCategories(ctx, id, module.Categories); err != nil {
+		return err
+	}
+	if err := s.writeAuthors(ctx, id, module.Creators); err != nil {
+		return err
+	}
 
 	return nil
 }
 
-type ArticleListing struct {
-	ID string `json:"id"`
-	// Name is the article headline, no HTML tags.
-	Name string `json:"name"`
-	// Headline is an <h1> tag containing the headline.
-	Headline   string   `json:"headline"`
-	Subhead    string   `json:"subhead"`
-	SlugPath   string   `json:"slug_path"`
-	PubDate    string   `json:"pubdate"`
-	UpdatedAt  string   `json:"updated_at"`
-	Authors    []string `json:"authors"`
-	Categories []string `json:"categories"`
-	ImgURL     string   `json:"img_url"`
+// writeCategories makes the item citygraph.ItemAbout the news-category vertex
+// of each of its categories, creating the vertices as needed, and no others.
+func (s *Store) writeCategories(ctx context.Context, id uuid.UUID, categories []string) error {
+	var targets []uuid.UUID
+	for _, name := range categories {
+		categoryID := citygraph.CategoryID(name)
+		if err := s.writeNamed(ctx, categoryID, &citygraph.NewsCategory, name, citygraph.CategoryPath(name)); err != nil {
+			return fmt.Errorf("category %q: %w", name, err)
+		}
+		targets = append(targets, categoryID)
+	}
+	return s.replaceEdges(ctx, id, &citygraph.ItemAbout, targets)
 }
 
-func (s *Store) WriteArticleListings(ctx context.Context, _ *Store, articles []*citygraph.Article) error {
-	latest := []*ArticleListing{}
-	for _, article := range articles {
-		if article.PubDate == "" || !article.IsLive {
-			continue
+// writeAuthors writes a news-author vertex for each author, so their pages
+// have canonical links in the graph, and makes the item citygraph.Published
+// by each of them. Published edges from authors no longer credited are
+// deleted; those from publishers are left alone.
+func (s *Store) writeAuthors(ctx context.Context, id uuid.UUID, authors []string) error {
+	var authorIDs []uuid.UUID
+	for _, name := range authors {
+		authorID := citygraph.AuthorID(name)
+		if err := s.writeNamed(ctx, authorID, &citygraph.NewsAuthor, name, citygraph.AuthorPath(name)); err != nil {
+			return fmt.Errorf("author %q: %w", name, err)
 		}
+		authorIDs = append(authorIDs, authorID)
+	}
 
-		articlePath, err := article.Path()
-		if err != nil {
-			return fmt.Errorf("failed to get article path: %w", err)
+	q := citygraph.NewPipeEdgeQuery(citygraph.NewSpecificVertexQuery(citygraph.UUID(id)), pb.EdgeDirection_INBOUND, &citygraph.Published)
+	existing, err := s.edgeKeys(ctx, q)
+	if err != nil {
+		return fmt.Errorf("get %s edges: %w", citygraph.Published.GetValue(), err)
+	}
+	wanted := make(map[uuid.UUID]bool, len(authorIDs))
+	for _, authorID := range authorIDs {
+		wanted[authorID] = true
+	}
+	linked := make(map[uuid.UUID]bool, len(existing))
+	var others []*pb.EdgeKey
+	for _, key := range existing {
+		source, err := uuid.FromBytes(key.GetOutboundId().GetValue())
+		if err == nil && wanted[source] {
+			linked[source] = true
+			continue
 		}
-		latest = append(latest, &ArticleListing{
-			ID:         article.ID,
-			Name:       article.Name,
-			Headline:   article.Headline,
-			Subhead:    article.Description,
-			SlugPath:   articlePath,
-			PubDate:    article.PubDate,
-			UpdatedAt:  article.LastUpdated,
-			Authors:    article.Authors,
-			Categories: article.Categories,
-			ImgURL:     article.FeatureImage,
-		})
-	}
-
-	sort.Slice(latest, func(i, j int) bool {
-		// Case where both items have UpdatedAt.
-		if latest[i].UpdatedAt != "" && latest[j].UpdatedAt != "" {
-			return latest[i].UpdatedAt > latest[j].UpdatedAt
+		others = append(others, key)
+	}
+	stale, err := s.edgesFromAuthors(ctx, others)
+	if err != nil {
+		return err
+	}
+	if len(stale) > 0 {
+		if err := s.DeleteEdges(ctx, citygraph.NewSpecificEdgeQuery(stale...)); err != nil {
+			return fmt.Errorf("delete %s edges: %w", citygraph.Published.GetValue(), err)
 		}
-
-		// Case where only i item has UpdatedAt.
-		if latest[i].UpdatedAt != "" && latest[j].UpdatedAt == "" {
-			return latest[i].UpdatedAt > latest[j].PubDate
+	}
+	for _, authorID := range authorIDs {
+		if linked[authorID] {
+			continue
 		}
-
-		// Case where only j item has UpdatedAt.
-		if latest[i].UpdatedAt == "" && latest[j].UpdatedAt != "" {
-			return latest[i].PubDate > latest[j].UpdatedAt
+		linked[authorID] = true
+		if err := s.CreateEdge(ctx, citygraph.UUID(authorID), &citygraph.Published, citygraph.UUID(id)); err != nil {
+			return fmt.Errorf("create %s edge: %w", citygraph.Published.GetValue(), err)
 		}
+	}
+	return nil
+}
 
-		// Case where neither item has UpdatedAt, compare PubDate.
-		return latest[i].PubDate > latest[j].PubDate
-	})
+// edgesFromAuthors returns the edges whose outbound vertex is a news-author.
+func (s *Store) edgesFromAuthors(ctx context.Context, keys []*pb.EdgeKey) ([]*pb.EdgeKey, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	var sources []*pb.Uuid
+	for _, key := range keys {
+		sources = append(sources, key.GetOutboundId())
+	}
+	vertices, err := s.GetVertices(ctx, citygraph.NewSpecificVertexQuery(sources...))
+	if err != nil {
+		return nil, fmt.Errorf("get %s sources: %w", citygraph.Published.GetValue(), err)
+	}
+	authors := map[string]bool{}
+	for _, v := range vertices {
+		if v.GetT().GetValue() == citygraph.NewsAuthor.GetValue() {
+			authors[uuidString(v.GetId())] = true
+		}
+	}
+	var fromAuthors []*pb.EdgeKey
+	for _, key := range keys {
+		if authors[uuidString(key.GetOutboundId())] {
+			fromAuthors = append(fromAuthors, key)
+		}
+	}
+	return fromAuthors, nil
+}
 
-	if err := s.SetVertexProperties(
-		ctx,
-		citygraph.NewSpecificVertexQuery(citygraph.Torontoverse.Id),
-		"latest_articles_public",
-		latest,
-	); err != nil {
+// writeNamed writes a vertex identified by name, such as a category, with its
+// display name and canonical links.
+func (s *Store) writeNamed(ctx context.Context, id uuid.UUID, t *pb.Identifier, name, path string) error {
+	if err := s.CreateVertex(ctx, citygraph.UUID(id), t); err != nil {
 		return err
 	}
+	q := citygraph.NewSpecificVertexQuery(citygraph.UUID(id))
+	props := append([]vertexProperty{{citygraph.PropertyNameDisplayName, name}}, s.canonicalProperties(path)...)
+	for _, prop := range props {
+		if err := s.SetVertexProperties(ctx, q, prop.name, prop.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// WriteLocatedIn makes the item citygraph.LocatedIn the given ward and
+// neighbourhood vertices, and no others, so area-filtered feeds include it.
+func (s *Store) WriteLocatedIn(ctx context.Context, id uuid.UUID, areaIDs []uuid.UUID) error {
+	return s.replaceEdges(ctx, id, &citygraph.LocatedIn, areaIDs)
+}
+
+// replaceEdges sets the item's outbound edges of type t to go to exactly the
+// targets, deleting stale edges and creating missing ones.
+func (s *Store) replaceEdges(ctx context.Context, id uuid.UUID, t *pb.Identifier, targets []uuid.UUID) error {
+	q := citygraph.NewPipeEdgeQuery(citygraph.NewSpecificVertexQuery(citygraph.UUID(id)), pb.EdgeDirection_OUTBOUND, t)
+	existing, err := s.edgeKeys(ctx, q)
+	if err != nil {
+		return fmt.Errorf("get %s edges: %w", t.GetValue(), err)
+	}
+	wanted := make(map[uuid.UUID]bool, len(targets))
+	for _, target := range targets {
+		wanted[target] = true
+	}
+	linked := make(map[uuid.UUID]bool, len(existing))
+	var stale []*pb.EdgeKey
+	for _, key := range existing {
+		target, err := uuid.FromBytes(key.GetInboundId().GetValue())
+		if err == nil && wanted[target] {
+			linked[target] = true
+			continue
+		}
+		stale = append(stale, key)
+	}
+	if len(stale) > 0 {
+		if err := s.DeleteEdges(ctx, citygraph.NewSpecificEdgeQuery(stale...)); err != nil {
+			return fmt.Errorf("delete %s edges: %w", t.GetValue(), err)
+		}
+	}
+	for _, target := range targets {
+		if linked[target] {
+			continue
+		}
+		linked[target] = true
+		if err := s.CreateEdge(ctx, citygraph.UUID(id), t, citygraph.UUID(target)); err != nil {
+			return fmt.Errorf("create %s edge: %w", t.GetValue(), err)
+		}
+	}
 	return nil
 }
+
+type ArticleListing struct {
+	ID string `json:"id"`
+	// Name is the article headline, no HTML tags.
+	Name string `json:"name"`
+	// Headline is an <h1> tag containing the headline.
+	Headline   string         `json:"headline"`
+	Subhead    string         `json:"subhead"`
+	SlugPath   string         `json:"slug_path"`
+	PubDate    citygraph.Date `json:"pubdate"`
+	UpdatedAt  citygraph.Date `json:"updated_at"`
+	Authors    []string       `json:"authors"`
+	Categories []string       `json:"categories"`
+	ImgURL     string         `json:"img_url"`
+}
+
+// WriteArticleListings rebuilds every article listing from the supplied
+// articles. The second argument is unused and kept for compatibility; use a
+// ListingMaintainer to update listings one article at a time.
+func (s *Store) WriteArticleListings(ctx context.Context, _ *Store, articles []*citygraph.Article) error {
+	return NewListingMaintainer(s).Rebuild(ctx, articles)
+}
@@ -3,12 +3,16 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 	"google.golang.org/protobuf/testing/protocmp"
 
 	"github.com/geomodulus/citygraph"
+	feedproducer "github.com/geomodulus/citygraph/feed_producer"
 	"github.com/geomodulus/citygraph/graphtest"
 	"github.com/geomodulus/citygraph/pb"
 )
@@ -17,7 +21,7 @@ func TestWriteBodyText(t *testing.T) {
 	aID := citygraph.NewID()
 	aUUID := citygraph.UUID(aID)
 	fakeGraph := &graphtest.FakeGraphClient{}
-	store := &Store{fakeGraph}
+	store := &Store{GraphClient: fakeGraph}
 
 	module := &citygraph.Module{
 		ID: aID.String(),
@@ -41,7 +45,7 @@ func TestWriteJS(t *testing.T) {
 	aID := citygraph.NewID()
 	aUUID := citygraph.UUID(aID)
 	fakeGraph := &graphtest.FakeGraphClient{}
-	store := &Store{fakeGraph}
+	store := &Store{GraphClient: fakeGraph}
 
 	module := &citygraph.Module{
 		ID: aID.String(),
@@ -64,27 +68,50 @@ func TestWriteJS(t *testing.T) {
 func TestWriteArticle(t *testing.T) {
 	aID, bID := citygraph.NewID(), citygraph.NewID()
 	aUUID := citygraph.UUID(aID)
-	fakeGraph := &graphtest.FakeGraphClient{}
-	store := &Store{fakeGraph}
+	catID, oldCatID := citygraph.CategoryID("Unit testing"), citygraph.CategoryID("Old category")
+	staleEdge := &pb.EdgeKey{
+		OutboundId: aUUID,
+		T:          &citygraph.ItemAbout,
+		InboundId:  citygraph.UUID(oldCatID),
+	}
+	// Raoul Duke is already credited and Old Author no longer is. The
+	// publisher's edge is left alone.
+	publisherID, oldAuthorID := citygraph.NewID(), citygraph.AuthorID("Old Author")
+	raoulEdge := &pb.EdgeKey{OutboundId: citygraph.UUID(citygraph.AuthorID("Raoul Duke")), T: &citygraph.Published, InboundId: aUUID}
+	oldAuthorEdge := &pb.EdgeKey{OutboundId: citygraph.UUID(oldAuthorID), T: &citygraph.Published, InboundId: aUUID}
+	publisherEdge := &pb.EdgeKey{OutboundId: citygraph.UUID(publisherID), T: &citygraph.Published, InboundId: aUUID}
+	fakeGraph := &graphtest.FakeGraphClient{
+		// A new article has no existing properties to save as a revision.
+		GetAllVertexPropertiesResps: [][]*pb.VertexProperties{nil},
+		GetEdgesResps: [][]*pb.Edge{
+			{{Key: staleEdge}},
+			{{Key: raoulEdge}, {Key: oldAuthorEdge}, {Key: publisherEdge}},
+		},
+		GetVerticesResps: [][]*pb.Vertex{{
+			{Id: citygraph.UUID(oldAuthorID), T: &citygraph.NewsAuthor},
+			{Id: citygraph.UUID(publisherID), T: &citygraph.NewsPublisher},
+		}},
+	}
+	store := &Store{GraphClient: fakeGraph}
 
+	smZoom := 10.5
 	article := &citygraph.Article{
 		ID:        aID.String(),
 		Name:      "Headline **with emphasis** here",
 		PastNames: []string{"Headline where we forgot emphasis here"},
 		Headline:  "Headline <em>with emphasis</em> here",
 		Authors:   []string{"Raoul Duke", "Hunter Thompson"},
-		Camera: map[string]interface{}{
-			"sm": map[string]interface{}{
-				"zoom": 10.5,
-			},
+		Camera: &citygraph.Camera{
+			Breakpoints: map[string]*citygraph.CameraOptions{"sm": {Zoom: &smZoom}},
 		},
-		PubDate:      "2022-06-14",
-		LastUpdated:  "2022-09-01T10:30:00-05:00",
+		PubDate:      citygraph.MustParseDate("2022-06-14"),
+		LastUpdated:  citygraph.MustParseDate("2022-09-01T10:30:00-05:00"),
 		Categories:   []string{"Unit testing"},
 		CodeCredit:   "some programmer",
 		Description:  "some description",
-		FeatureImage: "some url",
+		FeatureImage: "https://some.url/image.png",
 		Pitch:        85,
+		IsLive:       true,
 		Related:      []string{bID.String()},
 		Teaser: map[string]interface{}{
 			"type": "FeatureCollection",
@@ -112,6 +139,15 @@ func TestWriteArticle(t *testing.T) {
 	wantCreateVertexReqs := []*pb.Vertex{{
 		Id: aUUID,
 		T:  citygraph.ArticleType,
+	}, {
+		Id: citygraph.UUID(catID),
+		T:  &citygraph.NewsCategory,
+	}, {
+		Id: citygraph.UUID(citygraph.AuthorID("Raoul Duke")),
+		T:  &citygraph.NewsAuthor,
+	}, {
+		Id: citygraph.UUID(citygraph.AuthorID("Hunter Thompson")),
+		T:  &citygraph.NewsAuthor,
 	}}
 	if diff := cmp.Diff(wantCreateVertexReqs, fakeGraph.CreateVertexReqs, protocmp.Transform()); diff != "" {
 		t.Errorf("store.WriteArticle() sent create vertex graph req diff:\n%s\n", diff)
@@ -143,10 +179,10 @@ func TestWriteArticle(t *testing.T) {
 		Value: citygraph.Json(cameraBytes),
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, "published_on"),
-		Value: citygraph.StringVal(article.PubDate),
+		Value: citygraph.StringVal(article.PubDate.String()),
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, citygraph.PropertyNameUpdatedAt),
-		Value: citygraph.StringVal(article.LastUpdated),
+		Value: citygraph.StringVal(article.LastUpdated.String()),
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, "categories"),
 		Value: citygraph.Json(catBytes),
@@ -162,18 +198,46 @@ func TestWriteArticle(t *testing.T) {
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, "pitch"),
 		Value: citygraph.Json(pitchBytes),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(avq, "is_live"),
+		Value: citygraph.Json([]byte("true")),
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, "teaser"),
 		Value: citygraph.Json(teaserBytes),
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, "format"),
 		Value: citygraph.StringVal(article.Format),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(citygraph.NewSpecificVertexQuery(citygraph.UUID(catID)), "display_name"),
+		Value: citygraph.StringVal("Unit testing"),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(citygraph.NewSpecificVertexQuery(citygraph.UUID(citygraph.AuthorID("Raoul Duke"))), "display_name"),
+		Value: citygraph.StringVal("Raoul Duke"),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(citygraph.NewSpecificVertexQuery(citygraph.UUID(citygraph.AuthorID("Hunter Thompson"))), "display_name"),
+		Value: citygraph.StringVal("Hunter Thompson"),
 	}}
 	if diff := cmp.Diff(wantSetVertexPropertiesReqs, fakeGraph.SetVertexPropertiesReqs, protocmp.Transform()); diff != "" {
 		t.Errorf("store.WriteArticle() sent set vertex property graph req diff:\n%s\n", diff)
 	}
 
+	wantDeleteEdgesReqs := []*pb.EdgeQuery{
+		citygraph.NewSpecificEdgeQuery(staleEdge),
+		citygraph.NewSpecificEdgeQuery(oldAuthorEdge),
+	}
+	if diff := cmp.Diff(wantDeleteEdgesReqs, fakeGraph.DeleteEdgesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.WriteArticle() sent graph delete edges req diff:\n%s\n", diff)
+	}
+
 	wantCreateEdgeReqs := []*pb.EdgeKey{{
+		OutboundId: aUUID,
+		T:          &citygraph.ItemAbout,
+		InboundId:  citygraph.UUID(catID),
+	}, {
+		OutboundId: citygraph.UUID(citygraph.AuthorID("Hunter Thompson")),
+		T:          &citygraph.Published,
+		InboundId:  aUUID,
+	}, {
 		OutboundId: citygraph.UUID(bID),
 		T:          &citygraph.IsRelated,
 		InboundId:  citygraph.UUID(aID),
@@ -183,11 +247,42 @@ func TestWriteArticle(t *testing.T) {
 	}
 }
 
+func TestWriteArticleRefusesInvalid(t *testing.T) {
+	article := &citygraph.Article{
+		ID:           citygraph.NewID().String(),
+		Name:         "Some headline",
+		FeatureImage: "relative/image.png",
+	}
+
+	fakeGraph := &graphtest.FakeGraphClient{}
+	store := &Store{GraphClient: fakeGraph}
+	err := store.WriteArticle(context.Background(), article)
+	var verr *citygraph.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("store.WriteArticle() returned %v, want *citygraph.ValidationError", err)
+	}
+	if len(fakeGraph.CreateVertexReqs) != 0 {
+		t.Errorf("store.WriteArticle() wrote an invalid article")
+	}
+
+	fakeGraph = &graphtest.FakeGraphClient{
+		GetAllVertexPropertiesResps: [][]*pb.VertexProperties{nil},
+		GetEdgesResps:               [][]*pb.Edge{nil, nil},
+	}
+	store = &Store{GraphClient: fakeGraph, AllowInvalid: true}
+	if err := store.WriteArticle(context.Background(), article); err != nil {
+		t.Fatalf("store.WriteArticle() with AllowInvalid returned err: %v", err)
+	}
+	if len(fakeGraph.CreateVertexReqs) != 1 {
+		t.Errorf("store.WriteArticle() with AllowInvalid did not write the article")
+	}
+}
+
 func TestWriteArticleGeoJSONDataset(t *testing.T) {
 	aID, dID := citygraph.NewID(), citygraph.NewID()
 	dUUID := citygraph.UUID(dID)
 	fakeGraph := &graphtest.FakeGraphClient{}
-	store := &Store{fakeGraph}
+	store := &Store{GraphClient: fakeGraph}
 
 	dataset := &citygraph.GeoJSONDataset{
 		ID:   dID.String(),
@@ -239,7 +334,7 @@ func TestWriteArticleGeoJSONDatasetWithData(t *testing.T) {
 	aID, dID := citygraph.NewID(), citygraph.NewID()
 	dUUID := citygraph.UUID(dID)
 	fakeGraph := &graphtest.FakeGraphClient{}
-	store := &Store{fakeGraph}
+	store := &Store{GraphClient: fakeGraph}
 
 	dataset := &citygraph.GeoJSONDataset{
 		ID:   dID.String(),
@@ -248,12 +343,21 @@ func TestWriteArticleGeoJSONDatasetWithData(t *testing.T) {
 			URL: "https://some.source.url",
 		}},
 	}
-	if err := store.WriteArticleGeoJSONDatasetWithData(context.Background(), aID, dataset, "some data"); err != nil {
+	// The polygon is wound clockwise and should be written counterclockwise.
+	data := map[string]interface{}{
+		"type":       "Feature",
+		"properties": map[string]interface{}{"name": "Block"},
+		"geometry": map[string]interface{}{
+			"type":        "Polygon",
+			"coordinates": [][][]float64{{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}},
+		},
+	}
+	if err := store.WriteArticleGeoJSONDatasetWithData(context.Background(), aID, dataset, data); err != nil {
 		t.Errorf("store.WriteArticleGeoJSONDatasetWithData() returned err: %v", err)
 	}
 
 	srcsBytes, _ := json.Marshal(dataset.Sources)
-	dataBytes, _ := json.Marshal("some data")
+	dataBytes := []byte(`{"type":"Feature","geometry":{"coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]],"type":"Polygon"},"properties":{"name":"Block"}}`)
 	dvq := citygraph.NewSpecificVertexQuery(dUUID)
 	wantCreateVertexReqs := []*pb.Vertex{{
 		Id: dUUID,
@@ -287,29 +391,50 @@ func TestWriteArticleGeoJSONDatasetWithData(t *testing.T) {
 	}
 }
 
+func TestWriteArticleGeoJSONDatasetWithInvalidData(t *testing.T) {
+	dataset := &citygraph.GeoJSONDataset{ID: citygraph.NewID().String()}
+	unclosed := `{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1]]]}`
+
+	fakeGraph := &graphtest.FakeGraphClient{}
+	store := &Store{GraphClient: fakeGraph}
+	if err := store.WriteArticleGeoJSONDatasetWithData(context.Background(), citygraph.NewID(), dataset, json.RawMessage(unclosed)); err == nil {
+		t.Errorf("store.WriteArticleGeoJSONDatasetWithData() accepted an unclosed ring")
+	}
+	if len(fakeGraph.CreateVertexReqs) != 0 {
+		t.Errorf("store.WriteArticleGeoJSONDatasetWithData() wrote invalid data")
+	}
+}
+
 func TestWriteModule(t *testing.T) {
 	aID := citygraph.NewID()
 	aUUID := citygraph.UUID(aID)
-	fakeGraph := &graphtest.FakeGraphClient{}
-	store := &Store{fakeGraph}
+	openDataID, userGenID := citygraph.CategoryID("Open Data"), citygraph.CategoryID("User-Generated")
+	fakeGraph := &graphtest.FakeGraphClient{
+		// The module is already about one of its categories.
+		GetEdgesResps: [][]*pb.Edge{{{Key: &pb.EdgeKey{
+			OutboundId: aUUID,
+			T:          &citygraph.ItemAbout,
+			InboundId:  citygraph.UUID(openDataID),
+		}}}, nil},
+	}
+	store := &Store{GraphClient: fakeGraph}
 
+	smZoom := 10.5
 	module := &citygraph.Module{
 		ID:       aID.String(),
 		Name:     "Headline **with emphasis** here",
 		Headline: "Headline <em>with emphasis</em> here",
-		Camera: map[string]interface{}{
-			"sm": map[string]interface{}{
-				"zoom": 10.5,
-			},
+		Camera: &citygraph.Camera{
+			Breakpoints: map[string]*citygraph.CameraOptions{"sm": {Zoom: &smZoom}},
 		},
 		Creators:     []string{"John Dole", "Jane Dole"},
 		Format:       "content-map",
-		PubDate:      "2022-06-14",
-		LastUpdated:  "2022-09-01T10:30:00-05:00",
+		PubDate:      citygraph.MustParseDate("2022-06-14"),
+		LastUpdated:  citygraph.MustParseDate("2022-09-01T10:30:00-05:00"),
 		Categories:   []string{"Open Data", "User-Generated"},
 		CodeCredit:   "some programmer",
 		Description:  "some description",
-		FeatureImage: "some url",
+		FeatureImage: "https://some.url/image.png",
 		Teaser: map[string]interface{}{
 			"type": "FeatureCollection",
 		},
@@ -332,6 +457,18 @@ func TestWriteModule(t *testing.T) {
 	wantCreateVertexReqs := []*pb.Vertex{{
 		Id: aUUID,
 		T:  citygraph.ModuleType,
+	}, {
+		Id: citygraph.UUID(openDataID),
+		T:  &citygraph.NewsCategory,
+	}, {
+		Id: citygraph.UUID(userGenID),
+		T:  &citygraph.NewsCategory,
+	}, {
+		Id: citygraph.UUID(citygraph.AuthorID("John Dole")),
+		T:  &citygraph.NewsAuthor,
+	}, {
+		Id: citygraph.UUID(citygraph.AuthorID("Jane Dole")),
+		T:  &citygraph.NewsAuthor,
 	}}
 	if diff := cmp.Diff(wantCreateVertexReqs, fakeGraph.CreateVertexReqs, protocmp.Transform()); diff != "" {
 		t.Errorf("store.WriteModule() sent create vertex graph req diff:\n%s\n", diff)
@@ -349,6 +486,9 @@ func TestWriteModule(t *testing.T) {
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, "slug_title"),
 		Value: citygraph.StringVal(module.SlugTitle()),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(avq, "slug_titles"),
+		Value: citygraph.Json([]byte(`["headline-with-emphasis-here"]`)),
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, "creators"),
 		Value: citygraph.Json(creatorsBytes),
@@ -360,10 +500,10 @@ func TestWriteModule(t *testing.T) {
 		Value: citygraph.StringVal(module.Format),
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, "published_on"),
-		Value: citygraph.StringVal(module.PubDate),
+		Value: citygraph.StringVal(module.PubDate.String()),
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, citygraph.PropertyNameUpdatedAt),
-		Value: citygraph.StringVal(module.LastUpdated),
+		Value: citygraph.StringVal(module.LastUpdated.String()),
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, "categories"),
 		Value: citygraph.Json(catBytes),
@@ -379,8 +519,318 @@ func TestWriteModule(t *testing.T) {
 	}, {
 		Q:     citygraph.NewVertexPropertyQuery(avq, "teaser"),
 		Value: citygraph.Json(teaserBytes),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(citygraph.NewSpecificVertexQuery(citygraph.UUID(openDataID)), "display_name"),
+		Value: citygraph.StringVal("Open Data"),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(citygraph.NewSpecificVertexQuery(citygraph.UUID(userGenID)), "display_name"),
+		Value: citygraph.StringVal("User-Generated"),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(citygraph.NewSpecificVertexQuery(citygraph.UUID(citygraph.AuthorID("John Dole"))), "display_name"),
+		Value: citygraph.StringVal("John Dole"),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(citygraph.NewSpecificVertexQuery(citygraph.UUID(citygraph.AuthorID("Jane Dole"))), "display_name"),
+		Value: citygraph.StringVal("Jane Dole"),
 	}}
 	if diff := cmp.Diff(wantSetVertexPropertiesReqs, fakeGraph.SetVertexPropertiesReqs, protocmp.Transform()); diff != "" {
 		t.Errorf("store.WriteModule() sent set vertex property graph req diff:\n%s\n", diff)
 	}
+
+	wantCreateEdgeReqs := []*pb.EdgeKey{{
+		OutboundId: aUUID,
+		T:          &citygraph.ItemAbout,
+		InboundId:  citygraph.UUID(userGenID),
+	}, {
+		OutboundId: citygraph.UUID(citygraph.AuthorID("John Dole")),
+		T:          &citygraph.Published,
+		InboundId:  aUUID,
+	}, {
+		OutboundId: citygraph.UUID(citygraph.AuthorID("Jane Dole")),
+		T:          &citygraph.Published,
+		InboundId:  aUUID,
+	}}
+	if diff := cmp.Diff(wantCreateEdgeReqs, fakeGraph.CreateEdgeReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.WriteModule() sent graph create edge req diff:\n%s\n", diff)
+	}
+	if len(fakeGraph.DeleteEdgesReqs) != 0 {
+		t.Errorf("store.WriteModule() deleted edges to its own categories or creators")
+	}
+}
+
+func TestWriteModuleCanonicalURL(t *testing.T) {
+	urls, err := citygraph.NewURLBuilder("https://torontoverse.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := &citygraph.Module{ID: citygraph.NewID().String(), Name: "Ward map"}
+	fakeGraph := &graphtest.FakeGraphClient{GetEdgesResps: [][]*pb.Edge{nil, nil}}
+	store := &Store{GraphClient: fakeGraph, URLs: urls}
+	if err := store.WriteModule(context.Background(), module); err != nil {
+		t.Fatalf("store.WriteModule() returned err: %v", err)
+	}
+
+	path, err := module.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mvq, err := module.VertexQuery()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs := fakeGraph.SetVertexPropertiesReqs
+	want := []*pb.SetVertexPropertiesRequest{{
+		Q:     citygraph.NewVertexPropertyQuery(mvq, citygraph.PropertyNameCanonicalPath),
+		Value: citygraph.StringVal(path),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(mvq, citygraph.PropertyNameCanonicalURL),
+		Value: citygraph.StringVal("https://torontoverse.com" + path),
+	}}
+	if diff := cmp.Diff(want, reqs[len(reqs)-2:], protocmp.Transform()); diff != "" {
+		t.Errorf("store.WriteModule() sent set vertex properties req diff:\n%s\n", diff)
+	}
+}
+
+// TestStoreWritesMatchSchemas writes one of everything through a validating
+// client, so a Store writer that adds a property must declare it in
+// citygraph.Schemas.
+func TestStoreWritesMatchSchemas(t *testing.T) {
+	ctx := context.Background()
+	urls, err := citygraph.NewURLBuilder("https://torontoverse.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	articleID := citygraph.NewID()
+	fakeGraph := &graphtest.FakeGraphClient{
+		// The article exists with a different name, so a revision is saved.
+		GetAllVertexPropertiesResps: [][]*pb.VertexProperties{{{
+			Vertex: &pb.Vertex{Id: citygraph.UUID(articleID), T: citygraph.ArticleType},
+			Props: []*pb.NamedProperty{{
+				Name:  &pb.Identifier{Value: citygraph.PropertyNameDisplayName},
+				Value: citygraph.Json([]byte(`"Old headline"`)),
+			}},
+		}}},
+		GetVerticesResps: [][]*pb.Vertex{nil},
+		// Neither the article nor the module is about any category or
+		// published by any author yet.
+		GetEdgesResps: [][]*pb.Edge{nil, nil, nil, nil},
+	}
+	store := &Store{GraphClient: citygraph.NewValidatingClient(fakeGraph, citygraph.Schemas), URLs: urls}
+	store.GraphClient.(*citygraph.ValidatingClient).Strict = true
+
+	zoom := 12.0
+	camera := &citygraph.Camera{CameraOptions: citygraph.CameraOptions{Zoom: &zoom}}
+	article := &citygraph.Article{
+		ID:           articleID.String(),
+		Name:         "Headline",
+		Headline:     "<p>Headline</p>",
+		PastNames:    []string{"Old headline"},
+		Authors:      []string{"Raoul Duke"},
+		Camera:       camera,
+		PubDate:      citygraph.MustParseDate("2022-06-14"),
+		Categories:   []string{"Transit"},
+		FeatureImage: "https://some.url/image.png",
+		Pitch:        45,
+		Teaser:       map[string]interface{}{"type": "FeatureCollection"},
+	}
+	if err := store.WriteArticle(ctx, article); err != nil {
+		t.Errorf("store.WriteArticle() returned err: %v", err)
+	}
+	aq, _ := article.VertexQuery()
+	if err := store.WriteBodyText(ctx, aq, "<p>Body</p>"); err != nil {
+		t.Errorf("store.WriteBodyText() returned err: %v", err)
+	}
+	if err := store.WriteJS(ctx, aq, "() => {}"); err != nil {
+		t.Errorf("store.WriteJS() returned err: %v", err)
+	}
+	if err := store.WriteTeaserJS(ctx, aq, "() => {}"); err != nil {
+		t.Errorf("store.WriteTeaserJS() returned err: %v", err)
+	}
+
+	module := &citygraph.Module{ID: citygraph.NewID().String(), Name: "Ward map", Camera: camera, Creators: []string{"Jane Dole"}}
+	if err := store.WriteModule(ctx, module); err != nil {
+		t.Errorf("store.WriteModule() returned err: %v", err)
+	}
+
+	dataset := &citygraph.GeoJSONDataset{
+		ID:     citygraph.NewID().String(),
+		Name:   "Wards",
+		URL:    "https://dataset.url",
+		Source: &citygraph.Source{URL: "https://some.source.url"},
+	}
+	if err := store.WriteArticleGeoJSONDataset(ctx, articleID, dataset); err != nil {
+		t.Errorf("store.WriteArticleGeoJSONDataset() returned err: %v", err)
+	}
+
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	announcement := &citygraph.Announcement{
+		ID:       citygraph.NewID().String(),
+		Title:    "Line 1 closed",
+		BodyHTML: "<p>Shuttle buses are running.</p>",
+		Severity: citygraph.SeverityWarning,
+		Start:    now,
+		End:      now.Add(time.Hour),
+	}
+	if err := store.WriteAnnouncement(ctx, announcement); err != nil {
+		t.Errorf("store.WriteAnnouncement() returned err: %v", err)
+	}
+
+	link := &citygraph.WebLink{
+		URL:       "https://example.com/story",
+		Title:     "Council approves bike lanes",
+		OpenGraph: map[string]string{"type": "article"},
+	}
+	if _, err := store.WriteWebLink(ctx, link); err != nil {
+		t.Errorf("store.WriteWebLink() returned err: %v", err)
+	}
+
+	wantTypes := []string{
+		citygraph.ArticleType.Value,
+		citygraph.NewsRevision.Value,
+		citygraph.NewsCategory.Value,
+		citygraph.NewsAuthor.Value,
+		citygraph.ModuleType.Value,
+		citygraph.NewsAuthor.Value,
+		citygraph.NewsGeoJSON.Value,
+		citygraph.AnnouncementType.Value,
+		citygraph.WebLinkType.Value,
+	}
+	var gotTypes []string
+	for _, v := range fakeGraph.CreateVertexReqs {
+		gotTypes = append(gotTypes, v.GetT().GetValue())
+	}
+	if diff := cmp.Diff(wantTypes, gotTypes); diff != "" {
+		t.Errorf("store created vertex types diff:\n%s\n", diff)
+	}
+}
+
+// TestStoreWritesFeedFilterEdges checks that the edges the Store writes are
+// the ones feedproducer.FeedFilter reads for category and area feeds.
+func TestStoreWritesFeedFilterEdges(t *testing.T) {
+	ctx := context.Background()
+	graph := graphtest.NewMemoryGraphClient()
+	store := &Store{GraphClient: citygraph.NewValidatingClient(graph, citygraph.Schemas)}
+	store.GraphClient.(*citygraph.ValidatingClient).Strict = true
+
+	wardID := citygraph.NewID()
+	if err := graph.CreateVertex(ctx, citygraph.UUID(wardID), &citygraph.CityWard); err != nil {
+		t.Fatal(err)
+	}
+	articleID, moduleID := citygraph.NewID(), citygraph.NewID()
+	article := &citygraph.Article{ID: articleID.String(), Name: "Subway delays", Headline: "<p>Subway delays</p>", Categories: []string{"Transit"}}
+	if err := store.WriteArticle(ctx, article); err != nil {
+		t.Fatalf("store.WriteArticle() returned err: %v", err)
+	}
+	if err := store.WriteLocatedIn(ctx, articleID, []uuid.UUID{wardID}); err != nil {
+		t.Fatalf("store.WriteLocatedIn() returned err: %v", err)
+	}
+	module := &citygraph.Module{ID: moduleID.String(), Name: "Rent map", Categories: []string{"Housing", "transit"}}
+	if err := store.WriteModule(ctx, module); err != nil {
+		t.Fatalf("store.WriteModule() returned err: %v", err)
+	}
+	items := []*feedproducer.ContentItem{{FeedID: "a", ContentID: articleID}, {FeedID: "m", ContentID: moduleID}}
+
+	filtered := func(f *feedproducer.FeedFilter) []string {
+		t.Helper()
+		got, err := f.Filter(ctx, graph, items)
+		if err != nil {
+			t.Fatalf("FeedFilter.Filter() returned err: %v", err)
+		}
+		var ids []string
+		for _, item := range got {
+			ids = append(ids, item.FeedID)
+		}
+		return ids
+	}
+	for _, tc := range []struct {
+		filter *feedproducer.FeedFilter
+		want   []string
+	}{
+		{&feedproducer.FeedFilter{CategoryIDs: []uuid.UUID{citygraph.CategoryID("Transit")}}, []string{"a", "m"}},
+		{&feedproducer.FeedFilter{CategoryIDs: []uuid.UUID{citygraph.CategoryID("Housing")}}, []string{"m"}},
+		{&feedproducer.FeedFilter{AreaIDs: []uuid.UUID{wardID}}, []string{"a"}},
+	} {
+		if diff := cmp.Diff(tc.want, filtered(tc.filter)); diff != "" {
+			t.Errorf("FeedFilter%+v.Filter() diff:\n%s\n", *tc.filter, diff)
+		}
+	}
+
+	// Recategorizing and moving the article replaces its edges.
+	article.Categories = []string{"Housing"}
+	if err := store.WriteArticle(ctx, article); err != nil {
+		t.Fatalf("store.WriteArticle() returned err: %v", err)
+	}
+	if err := store.WriteLocatedIn(ctx, articleID, nil); err != nil {
+		t.Fatalf("store.WriteLocatedIn() returned err: %v", err)
+	}
+	for _, tc := range []struct {
+		filter *feedproducer.FeedFilter
+		want   []string
+	}{
+		{&feedproducer.FeedFilter{CategoryIDs: []uuid.UUID{citygraph.CategoryID("Transit")}}, []string{"m"}},
+		{&feedproducer.FeedFilter{CategoryIDs: []uuid.UUID{citygraph.CategoryID("Housing")}}, []string{"a", "m"}},
+		{&feedproducer.FeedFilter{AreaIDs: []uuid.UUID{wardID}}, nil},
+	} {
+		if diff := cmp.Diff(tc.want, filtered(tc.filter)); diff != "" {
+			t.Errorf("after rewrite, FeedFilter%+v.Filter() diff:\n%s\n", *tc.filter, diff)
+		}
+	}
+}
+
+func TestWriteArticleLinksCategoriesAndAuthors(t *testing.T) {
+	ctx := context.Background()
+	urls, err := citygraph.NewURLBuilder("https://torontoverse.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph := graphtest.NewMemoryGraphClient()
+	store := &Store{GraphClient: graph, URLs: urls}
+	article := &citygraph.Article{
+		ID:         citygraph.NewID().String(),
+		Name:       "Subway delays",
+		Headline:   "<p>Subway delays</p>",
+		Authors:    []string{"Raoul Duke"},
+		Categories: []string{"Open Data"},
+	}
+	if err := store.WriteArticle(ctx, article); err != nil {
+		t.Fatalf("store.WriteArticle() returned err: %v", err)
+	}
+	for id, want := range map[uuid.UUID]string{
+		citygraph.CategoryID("Open Data"): urls.CategoryURL("Open Data"),
+		citygraph.AuthorID("Raoul Duke"):  urls.AuthorURL("Raoul Duke"),
+	} {
+		props, err := graph.GetVertexProperties(ctx, citygraph.NewSpecificVertexQuery(citygraph.UUID(id)), citygraph.PropertyNameCanonicalURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		if len(props) > 0 {
+			json.Unmarshal([]byte(props[0].GetValue().GetValue()), &got)
+		}
+		if got != want {
+			t.Errorf("vertex %s canonical_url = %q, want %q", id, got, want)
+		}
+	}
+
+	// The author vertex isn't left orphaned: it published the article.
+	authorQuery := citygraph.NewSpecificVertexQuery(citygraph.UUID(citygraph.AuthorID("Raoul Duke")))
+	edges, err := graph.GetEdges(ctx, citygraph.NewPipeEdgeQuery(authorQuery, pb.EdgeDirection_OUTBOUND, &citygraph.Published))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edges) != 1 || uuidString(edges[0].GetKey().GetInboundId()) != article.ID {
+		t.Errorf("author published edges = %v, want one to article %s", edges, article.ID)
+	}
+
+	// Crediting someone else drops the first author's edge.
+	article.Authors = []string{"Hunter Thompson"}
+	if err := store.WriteArticle(ctx, article); err != nil {
+		t.Fatalf("store.WriteArticle() returned err: %v", err)
+	}
+	edges, err = graph.GetEdges(ctx, citygraph.NewPipeEdgeQuery(authorQuery, pb.EdgeDirection_OUTBOUND, &citygraph.Published))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edges) != 0 {
+		t.Errorf("former author published edges = %v, want none", edges)
+	}
 }
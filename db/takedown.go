@@ -0,0 +1,223 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+// FeedRemover is the part of feedproducer.FeedClient used to pull an item
+// from the live feed.
+type FeedRemover interface {
+	RemoveContent(ctx context.Context, id uuid.UUID) error
+}
+
+// TakedownOptions control how an article is unpublished or deleted.
+type TakedownOptions struct {
+	// Feed, if set, is used to remove the article from the live feed.
+	Feed FeedRemover
+	// DryRun reports what would be removed without changing anything.
+	DryRun bool
+}
+
+// TakedownReport lists what was removed when an article was unpublished or
+// deleted. On a dry run nothing is changed and the report lists what would
+// have been removed instead.
+type TakedownReport struct {
+	DryRun bool
+	// Vertices removed from the graph.
+	Vertices []*pb.Vertex
+	// Edges removed from the graph.
+	Edges []*pb.EdgeKey
+	// Listings holds the names of listing properties the article was dropped
+	// from.
+	Listings []string
+	// Feed is true if the article was removed from the live feed.
+	Feed bool
+}
+
+func (r *TakedownReport) String() string {
+	var b strings.Builder
+	verb := "removed"
+	if r.DryRun {
+		verb = "would remove"
+	}
+	for _, v := range r.Vertices {
+		fmt.Fprintf(&b, "%s vertex %s (%s)\n", verb, uuidString(v.GetId()), v.GetT().GetValue())
+	}
+	for _, e := range r.Edges {
+		fmt.Fprintf(&b, "%s edge %s -%s-> %s\n", verb, uuidString(e.GetOutboundId()), e.GetT().GetValue(), uuidString(e.GetInboundId()))
+	}
+	for _, name := range r.Listings {
+		fmt.Fprintf(&b, "%s listing entry from %s\n", verb, name)
+	}
+	if r.Feed {
+		fmt.Fprintf(&b, "%s from live feed\n", verb)
+	}
+	return b.String()
+}
+
+func uuidString(id *pb.Uuid) string {
+	u, err := uuid.FromBytes(id.GetValue())
+	if err != nil {
+		return fmt.Sprintf("%x", id.GetValue())
+	}
+	return u.String()
+}
+
+// UnpublishArticle takes an article down while keeping its vertex: it is
+// marked as not live, dropped from the article listings, disconnected from
+// its publishers and removed from the live feed.
+func (s *Store) UnpublishArticle(ctx context.Context, id uuid.UUID, opts *TakedownOptions) (*TakedownReport, error) {
+	if opts == nil {
+		opts = &TakedownOptions{}
+	}
+	report := &TakedownReport{DryRun: opts.DryRun}
+	if err := s.unpublish(ctx, id, opts, report); err != nil {
+		return nil, err
+	}
+	if !opts.DryRun {
+		if err := s.SetVertexProperties(ctx, citygraph.NewSpecificVertexQuery(citygraph.UUID(id)), "is_live", false); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// DeleteArticle unpublishes an article and then removes its vertex, its
+// is-related, illustrated-by and has-revision edges, its revisions and any
+// GeoJSON dataset vertices that no other item is illustrated by.
+func (s *Store) DeleteArticle(ctx context.Context, id uuid.UUID, opts *TakedownOptions) (*TakedownReport, error) {
+	if opts == nil {
+		opts = &TakedownOptions{}
+	}
+	report := &TakedownReport{DryRun: opts.DryRun}
+	if err := s.unpublish(ctx, id, opts, report); err != nil {
+		return nil, err
+	}
+
+	avq := citygraph.NewSpecificVertexQuery(citygraph.UUID(id))
+	var edges []*pb.EdgeKey
+	for _, q := range []*pb.EdgeQuery{
+		citygraph.NewPipeEdgeQuery(avq, pb.EdgeDirection_INBOUND, &citygraph.IsRelated),
+		citygraph.NewPipeEdgeQuery(avq, pb.EdgeDirection_OUTBOUND, &citygraph.IsRelated),
+	} {
+		found, err := s.edgeKeys(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, found...)
+	}
+
+	datasetEdges, err := s.edgeKeys(ctx, citygraph.NewPipeEdgeQuery(avq, pb.EdgeDirection_OUTBOUND, &citygraph.IllustratedBy))
+	if err != nil {
+		return nil, err
+	}
+	edges = append(edges, datasetEdges...)
+
+	var dropped []*pb.Uuid
+	for _, e := range datasetEdges {
+		dvq := citygraph.NewSpecificVertexQuery(e.InboundId)
+		illustrating, err := s.edgeKeys(ctx, citygraph.NewPipeEdgeQuery(dvq, pb.EdgeDirection_INBOUND, &citygraph.IllustratedBy))
+		if err != nil {
+			return nil, err
+		}
+		shared := false
+		for _, other := range illustrating {
+			if uuidString(other.OutboundId) != id.String() {
+				shared = true
+				break
+			}
+		}
+		if !shared {
+			dropped = append(dropped, e.InboundId)
+			report.Vertices = append(report.Vertices, &pb.Vertex{Id: e.InboundId, T: &citygraph.NewsGeoJSON})
+		}
+	}
+
+	revisionEdges, err := s.edgeKeys(ctx, citygraph.NewPipeEdgeQuery(avq, pb.EdgeDirection_OUTBOUND, &citygraph.HasRevision))
+	if err != nil {
+		return nil, err
+	}
+	edges = append(edges, revisionEdges...)
+	for _, e := range revisionEdges {
+		dropped = append(dropped, e.InboundId)
+		report.Vertices = append(report.Vertices, &pb.Vertex{Id: e.InboundId, T: &citygraph.NewsRevision})
+	}
+	report.Edges = append(report.Edges, edges...)
+	report.Vertices = append(report.Vertices, &pb.Vertex{Id: citygraph.UUID(id), T: citygraph.ArticleType})
+
+	if opts.DryRun {
+		return report, nil
+	}
+	if len(edges) > 0 {
+		if err := s.DeleteEdges(ctx, citygraph.NewSpecificEdgeQuery(edges...)); err != nil {
+			return nil, err
+		}
+	}
+	if len(dropped) > 0 {
+		if err := s.DeleteVertices(ctx, citygraph.NewSpecificVertexQuery(dropped...)); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.DeleteVertices(ctx, avq); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// unpublish removes the article from listings, publisher edges and the feed,
+// recording each removal in report.
+func (s *Store) unpublish(ctx context.Context, id uuid.UUID, opts *TakedownOptions, report *TakedownReport) error {
+	listings, err := NewListingMaintainer(s).remove(ctx, id.String(), opts.DryRun)
+	if err != nil {
+		return err
+	}
+	report.Listings = listings
+
+	avq := citygraph.NewSpecificVertexQuery(citygraph.UUID(id))
+	var edges []*pb.EdgeKey
+	for _, q := range []*pb.EdgeQuery{
+		citygraph.NewPipeEdgeQuery(avq, pb.EdgeDirection_INBOUND, &citygraph.Published),
+		citygraph.NewPipeEdgeQuery(avq, pb.EdgeDirection_OUTBOUND, &citygraph.PublishedBy),
+	} {
+		found, err := s.edgeKeys(ctx, q)
+		if err != nil {
+			return err
+		}
+		edges = append(edges, found...)
+	}
+	report.Edges = append(report.Edges, edges...)
+	if len(edges) > 0 && !opts.DryRun {
+		if err := s.DeleteEdges(ctx, citygraph.NewSpecificEdgeQuery(edges...)); err != nil {
+			return err
+		}
+	}
+
+	if opts.Feed != nil {
+		report.Feed = true
+		if !opts.DryRun {
+			if err := opts.Feed.RemoveContent(ctx, id); err != nil {
+				return fmt.Errorf("remove from feed: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Store) edgeKeys(ctx context.Context, q *pb.EdgeQuery) ([]*pb.EdgeKey, error) {
+	edges, err := s.GetEdges(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*pb.EdgeKey, 0, len(edges))
+	for _, e := range edges {
+		keys = append(keys, e.Key)
+	}
+	return keys, nil
+}
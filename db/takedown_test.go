@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/geomodulus/citygraph"
+	feedproducer "github.com/geomodulus/citygraph/feed_producer"
+	"github.com/geomodulus/citygraph/graphtest"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+func listingProps(t *testing.T, listings ...*ArticleListing) []*pb.VertexProperty {
+	t.Helper()
+	b, err := json.Marshal(listings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []*pb.VertexProperty{{Id: citygraph.Torontoverse.Id, Value: citygraph.Json(b)}}
+}
+
+func pageCountProps(pages int) []*pb.VertexProperty {
+	return []*pb.VertexProperty{{Id: citygraph.Torontoverse.Id, Value: citygraph.IntVal(pages)}}
+}
+
+func TestUnpublishArticle(t *testing.T) {
+	aID, bID, publisherID := citygraph.NewID(), citygraph.NewID(), citygraph.NewID()
+	aUUID := citygraph.UUID(aID)
+	publishedEdge := &pb.EdgeKey{OutboundId: citygraph.UUID(publisherID), T: &citygraph.Published, InboundId: aUUID}
+	publishedByEdge := &pb.EdgeKey{OutboundId: aUUID, T: &citygraph.PublishedBy, InboundId: citygraph.UUID(publisherID)}
+	other := &ArticleListing{ID: bID.String(), Name: "Other"}
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetVertexPropertiesResps: [][]*pb.VertexProperty{
+			listingProps(t, &ArticleListing{ID: aID.String(), Name: "Gone"}, other),
+			pageCountProps(1),
+		},
+		GetEdgesResps: [][]*pb.Edge{{{Key: publishedEdge}}, {{Key: publishedByEdge}}},
+	}
+	store := &Store{GraphClient: fakeGraph}
+	feed := &feedproducer.FakeFeedClient{}
+
+	report, err := store.UnpublishArticle(context.Background(), aID, &TakedownOptions{Feed: feed})
+	if err != nil {
+		t.Fatalf("store.UnpublishArticle() returned err: %v", err)
+	}
+
+	wantReport := &TakedownReport{
+		Edges:    []*pb.EdgeKey{publishedEdge, publishedByEdge},
+		Listings: []string{PropertyNameLatestArticles},
+		Feed:     true,
+	}
+	if diff := cmp.Diff(wantReport, report, protocmp.Transform()); diff != "" {
+		t.Errorf("store.UnpublishArticle() report diff:\n%s\n", diff)
+	}
+
+	remainingBytes, _ := json.Marshal([]*ArticleListing{other})
+	tvq := citygraph.NewSpecificVertexQuery(citygraph.Torontoverse.Id)
+	wantSetVertexPropertiesReqs := []*pb.SetVertexPropertiesRequest{{
+		Q:     citygraph.NewVertexPropertyQuery(tvq, PropertyNameLatestArticles),
+		Value: citygraph.Json(remainingBytes),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(tvq, ListingPageName(PropertyNameLatestArticles, 1)),
+		Value: citygraph.Json(remainingBytes),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(tvq, ListingPageCountName(PropertyNameLatestArticles)),
+		Value: citygraph.IntVal(1),
+	}, {
+		Q:     citygraph.NewVertexPropertyQuery(citygraph.NewSpecificVertexQuery(aUUID), "is_live"),
+		Value: citygraph.Json([]byte("false")),
+	}}
+	if diff := cmp.Diff(wantSetVertexPropertiesReqs, fakeGraph.SetVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.UnpublishArticle() sent set vertex properties req diff:\n%s\n", diff)
+	}
+
+	wantDeleteEdgesReqs := []*pb.EdgeQuery{citygraph.NewSpecificEdgeQuery(publishedEdge, publishedByEdge)}
+	if diff := cmp.Diff(wantDeleteEdgesReqs, fakeGraph.DeleteEdgesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.UnpublishArticle() sent delete edges req diff:\n%s\n", diff)
+	}
+	if len(fakeGraph.DeleteVerticesReqs) != 0 {
+		t.Errorf("store.UnpublishArticle() deleted vertices: %v", fakeGraph.DeleteVerticesReqs)
+	}
+
+	if diff := cmp.Diff([]uuid.UUID{aID}, feed.RemoveContentReqs); diff != "" {
+		t.Errorf("store.UnpublishArticle() sent feed remove content req diff:\n%s\n", diff)
+	}
+}
+
+func TestDeleteArticle(t *testing.T) {
+	aID, relatedID, sharedID, orphanID, otherID := citygraph.NewID(), citygraph.NewID(), citygraph.NewID(), citygraph.NewID(), citygraph.NewID()
+	revisionID := citygraph.NewID()
+	aUUID := citygraph.UUID(aID)
+	relatedEdge := &pb.EdgeKey{OutboundId: citygraph.UUID(relatedID), T: &citygraph.IsRelated, InboundId: aUUID}
+	sharedEdge := &pb.EdgeKey{OutboundId: aUUID, T: &citygraph.IllustratedBy, InboundId: citygraph.UUID(sharedID)}
+	orphanEdge := &pb.EdgeKey{OutboundId: aUUID, T: &citygraph.IllustratedBy, InboundId: citygraph.UUID(orphanID)}
+	otherSharedEdge := &pb.EdgeKey{OutboundId: citygraph.UUID(otherID), T: &citygraph.IllustratedBy, InboundId: citygraph.UUID(sharedID)}
+	revisionEdge := &pb.EdgeKey{OutboundId: aUUID, T: &citygraph.HasRevision, InboundId: citygraph.UUID(revisionID)}
+
+	newFake := func() *graphtest.FakeGraphClient {
+		return &graphtest.FakeGraphClient{
+			GetVertexPropertiesResps: [][]*pb.VertexProperty{nil},
+			GetEdgesResps: [][]*pb.Edge{
+				// Publisher edges.
+				{}, {},
+				// Related edges, inbound then outbound.
+				{{Key: relatedEdge}}, {},
+				// Datasets illustrating the article.
+				{{Key: sharedEdge}, {Key: orphanEdge}},
+				// Items illustrated by each dataset.
+				{{Key: sharedEdge}, {Key: otherSharedEdge}},
+				{{Key: orphanEdge}},
+				// Revisions.
+				{{Key: revisionEdge}},
+			},
+		}
+	}
+	wantReport := &TakedownReport{
+		Vertices: []*pb.Vertex{
+			{Id: citygraph.UUID(orphanID), T: &citygraph.NewsGeoJSON},
+			{Id: citygraph.UUID(revisionID), T: &citygraph.NewsRevision},
+			{Id: aUUID, T: citygraph.ArticleType},
+		},
+		Edges: []*pb.EdgeKey{relatedEdge, sharedEdge, orphanEdge, revisionEdge},
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		fakeGraph := newFake()
+		store := &Store{GraphClient: fakeGraph}
+		report, err := store.DeleteArticle(context.Background(), aID, &TakedownOptions{DryRun: true})
+		if err != nil {
+			t.Fatalf("store.DeleteArticle() returned err: %v", err)
+		}
+		wantDryRun := *wantReport
+		wantDryRun.DryRun = true
+		if diff := cmp.Diff(&wantDryRun, report, protocmp.Transform()); diff != "" {
+			t.Errorf("store.DeleteArticle() report diff:\n%s\n", diff)
+		}
+		if len(fakeGraph.DeleteEdgesReqs) != 0 || len(fakeGraph.DeleteVerticesReqs) != 0 || len(fakeGraph.SetVertexPropertiesReqs) != 0 {
+			t.Errorf("store.DeleteArticle() changed the graph on a dry run")
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		fakeGraph := newFake()
+		store := &Store{GraphClient: fakeGraph}
+		report, err := store.DeleteArticle(context.Background(), aID, nil)
+		if err != nil {
+			t.Fatalf("store.DeleteArticle() returned err: %v", err)
+		}
+		if diff := cmp.Diff(wantReport, report, protocmp.Transform()); diff != "" {
+			t.Errorf("store.DeleteArticle() report diff:\n%s\n", diff)
+		}
+
+		wantDeleteEdgesReqs := []*pb.EdgeQuery{citygraph.NewSpecificEdgeQuery(relatedEdge, sharedEdge, orphanEdge, revisionEdge)}
+		if diff := cmp.Diff(wantDeleteEdgesReqs, fakeGraph.DeleteEdgesReqs, protocmp.Transform()); diff != "" {
+			t.Errorf("store.DeleteArticle() sent delete edges req diff:\n%s\n", diff)
+		}
+		wantDeleteVerticesReqs := []*pb.VertexQuery{
+			citygraph.NewSpecificVertexQuery(citygraph.UUID(orphanID), citygraph.UUID(revisionID)),
+			citygraph.NewSpecificVertexQuery(aUUID),
+		}
+		if diff := cmp.Diff(wantDeleteVerticesReqs, fakeGraph.DeleteVerticesReqs, protocmp.Transform()); diff != "" {
+			t.Errorf("store.DeleteArticle() sent delete vertices req diff:\n%s\n", diff)
+		}
+	})
+}
+
+func TestRepublishArticle(t *testing.T) {
+	ctx := context.Background()
+	id := citygraph.NewID()
+	article := &citygraph.Article{ID: id.String(), Name: "Bike lanes come to Bloor", Headline: "<p>Bike lanes come to Bloor</p>", IsLive: true}
+	store := &Store{GraphClient: graphtest.NewMemoryGraphClient()}
+
+	isLive := func() bool {
+		t.Helper()
+		got, err := store.ReadArticle(ctx, id)
+		if err != nil {
+			t.Fatalf("store.ReadArticle() returned err: %v", err)
+		}
+		return got.IsLive
+	}
+
+	if err := store.WriteArticle(ctx, article); err != nil {
+		t.Fatalf("store.WriteArticle() returned err: %v", err)
+	}
+	if !isLive() {
+		t.Errorf("article isn't live after store.WriteArticle()")
+	}
+	if _, err := store.UnpublishArticle(ctx, id, nil); err != nil {
+		t.Fatalf("store.UnpublishArticle() returned err: %v", err)
+	}
+	if isLive() {
+		t.Errorf("article is live after store.UnpublishArticle()")
+	}
+	if err := store.WriteArticle(ctx, article); err != nil {
+		t.Fatalf("store.WriteArticle() returned err: %v", err)
+	}
+	if !isLive() {
+		t.Errorf("article isn't live after being written again")
+	}
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph"
+)
+
+// WebLinkFeed adds web links to the live feed. feedproducer.WebLinkAdder
+// implements it for a feedproducer.FeedClient.
+type WebLinkFeed interface {
+	AddWebLink(ctx context.Context, id uuid.UUID) error
+}
+
+// WriteWebLink canonicalizes the link's URL, setting its ID to match, and
+// writes its vertex and properties. It reports whether the vertex is new:
+// links to a page already in the graph, however its URL was written, update
+// the existing vertex, keeping its description, image and source unless the
+// link has new ones.
+func (s *Store) WriteWebLink(ctx context.Context, link *citygraph.WebLink) (created bool, err error) {
+	if err := link.Canonicalize(); err != nil {
+		return false, fmt.Errorf("web link: %w", err)
+	}
+	if err := s.validate(link); err != nil {
+		return false, err
+	}
+	q, err := link.VertexQuery()
+	if err != nil {
+		return false, err
+	}
+	existing, err := s.GetVertices(ctx, q)
+	if err != nil {
+		return false, err
+	}
+	if len(existing) == 0 {
+		id, err := link.UUID()
+		if err != nil {
+			return false, err
+		}
+		if err := s.CreateVertex(ctx, citygraph.UUID(id), citygraph.WebLinkType); err != nil {
+			return false, err
+		}
+	}
+	props := []vertexProperty{
+		{citygraph.PropertyNameDisplayName, link.Title},
+		{"url", link.URL},
+		{citygraph.PropertyNameCanonicalURL, link.URL},
+	}
+	for _, prop := range []vertexProperty{
+		{"h2", link.Description},
+		{citygraph.PropertyNameImgURL, link.ImageURL},
+		{"source", link.Source},
+	} {
+		if len(existing) == 0 || prop.value != "" {
+			props = append(props, prop)
+		}
+	}
+	if len(link.OpenGraph) > 0 {
+		props = append(props, vertexProperty{"opengraph", link.OpenGraph})
+	}
+	for _, prop := range props {
+		if err := s.SetVertexProperties(ctx, q, prop.name, prop.value); err != nil {
+			return false, err
+		}
+	}
+	return len(existing) == 0, nil
+}
+
+// PublishWebLink writes the link and adds it to the feed as WEB_LINK
+// content. Adding content the feed already has doesn't release it again, so
+// a publish that failed after writing the vertex can simply be retried.
+func (s *Store) PublishWebLink(ctx context.Context, link *citygraph.WebLink, feed WebLinkFeed) (uuid.UUID, error) {
+	if _, err := s.WriteWebLink(ctx, link); err != nil {
+		return uuid.UUID{}, err
+	}
+	id, err := link.UUID()
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if err := feed.AddWebLink(ctx, id); err != nil {
+		return uuid.UUID{}, fmt.Errorf("add web link %s: %w", id, err)
+	}
+	return id, nil
+}
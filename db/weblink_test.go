@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/geomodulus/citygraph"
+	feedproducer "github.com/geomodulus/citygraph/feed_producer"
+	fpb "github.com/geomodulus/citygraph/feed_producer/pb"
+	"github.com/geomodulus/citygraph/graphtest"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+func TestPublishWebLink(t *testing.T) {
+	fakeGraph := &graphtest.FakeGraphClient{GetVerticesResps: [][]*pb.Vertex{nil}}
+	store := &Store{GraphClient: fakeGraph}
+	feed := &feedproducer.FakeFeedClient{}
+	adder := feedproducer.WebLinkAdder{Feed: feed}
+	link := &citygraph.WebLink{
+		URL:    "https://Example.com/story?utm_source=feed",
+		Title:  "Council approves bike lanes",
+		Source: "The Daily",
+	}
+
+	id, err := store.PublishWebLink(context.Background(), link, adder)
+	if err != nil {
+		t.Fatalf("store.PublishWebLink() returned err: %v", err)
+	}
+	wantID := citygraph.WebLinkID("https://example.com/story")
+	if id != wantID {
+		t.Errorf("store.PublishWebLink() = %s, want %s", id, wantID)
+	}
+	wantCreateVertexReqs := []*pb.Vertex{{Id: citygraph.UUID(wantID), T: citygraph.WebLinkType}}
+	if diff := cmp.Diff(wantCreateVertexReqs, fakeGraph.CreateVertexReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.PublishWebLink() sent create vertex req diff:\n%s\n", diff)
+	}
+	q := citygraph.NewSpecificVertexQuery(citygraph.UUID(wantID))
+	var wantSetVertexPropertiesReqs []*pb.SetVertexPropertiesRequest
+	for _, prop := range []vertexProperty{
+		{citygraph.PropertyNameDisplayName, "Council approves bike lanes"},
+		{"url", "https://example.com/story"},
+		{citygraph.PropertyNameCanonicalURL, "https://example.com/story"},
+		{"h2", ""},
+		{citygraph.PropertyNameImgURL, ""},
+		{"source", "The Daily"},
+	} {
+		req, err := citygraph.SetVertexPropertiesRequest(q, prop.name, prop.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantSetVertexPropertiesReqs = append(wantSetVertexPropertiesReqs, req)
+	}
+	if diff := cmp.Diff(wantSetVertexPropertiesReqs, fakeGraph.SetVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.PublishWebLink() sent set vertex properties req diff:\n%s\n", diff)
+	}
+	wantContent := []*feedproducer.Content{{Type: fpb.ContentType_WEB_LINK, ID: wantID}}
+	if diff := cmp.Diff(wantContent, feed.AddContentReqs); diff != "" {
+		t.Errorf("store.PublishWebLink() added content diff:\n%s\n", diff)
+	}
+}
+
+func TestPublishWebLinkDuplicate(t *testing.T) {
+	id := citygraph.WebLinkID("https://example.com/story")
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetVerticesResps: [][]*pb.Vertex{{{Id: citygraph.UUID(id), T: citygraph.WebLinkType}}},
+	}
+	store := &Store{GraphClient: fakeGraph}
+	feed := &feedproducer.FakeFeedClient{}
+	adder := feedproducer.WebLinkAdder{Feed: feed}
+	// The update has no description, image or source, so the stored ones
+	// are kept.
+	link := &citygraph.WebLink{URL: "https://example.com/story#comments", Title: "Updated title"}
+
+	got, err := store.PublishWebLink(context.Background(), link, adder)
+	if err != nil {
+		t.Fatalf("store.PublishWebLink() returned err: %v", err)
+	}
+	if got != id {
+		t.Errorf("store.PublishWebLink() = %s, want existing %s", got, id)
+	}
+	if len(fakeGraph.CreateVertexReqs) != 0 {
+		t.Errorf("store.PublishWebLink() created a second vertex: %v", fakeGraph.CreateVertexReqs)
+	}
+	q := citygraph.NewSpecificVertexQuery(citygraph.UUID(id))
+	var wantSetVertexPropertiesReqs []*pb.SetVertexPropertiesRequest
+	for _, prop := range []vertexProperty{
+		{citygraph.PropertyNameDisplayName, "Updated title"},
+		{"url", "https://example.com/story"},
+		{citygraph.PropertyNameCanonicalURL, "https://example.com/story"},
+	} {
+		req, err := citygraph.SetVertexPropertiesRequest(q, prop.name, prop.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantSetVertexPropertiesReqs = append(wantSetVertexPropertiesReqs, req)
+	}
+	if diff := cmp.Diff(wantSetVertexPropertiesReqs, fakeGraph.SetVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("store.PublishWebLink() sent set vertex properties req diff:\n%s\n", diff)
+	}
+	// The link is added again in case an earlier publish wrote the vertex
+	// but failed to reach the feed.
+	wantContent := []*feedproducer.Content{{Type: fpb.ContentType_WEB_LINK, ID: id}}
+	if diff := cmp.Diff(wantContent, feed.AddContentReqs); diff != "" {
+		t.Errorf("store.PublishWebLink() added content diff:\n%s\n", diff)
+	}
+}
@@ -0,0 +1,199 @@
+package citygraph
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/geomodulus/citygraph/pb"
+)
+
+var (
+	// ErrUnknownEdgeType is returned for an edge type with no rules.
+	ErrUnknownEdgeType = errors.New("edge type has no rules")
+	// ErrEdgeNotAllowed is returned for an edge between vertex types its
+	// rules don't allow.
+	ErrEdgeNotAllowed = errors.New("edge is not allowed between these vertex types")
+)
+
+// EdgeRule allows edges of type Edge from vertices of type Source to
+// vertices of type Target. A nil Source or Target allows any vertex type.
+type EdgeRule struct {
+	Source *pb.Identifier
+	Edge   *pb.Identifier
+	Target *pb.Identifier
+}
+
+func (r *EdgeRule) String() string {
+	return fmt.Sprintf("%s -%s-> %s", typeName(r.Source), r.Edge.GetValue(), typeName(r.Target))
+}
+
+func (r *EdgeRule) allows(source, target string) bool {
+	return (r.Source == nil || r.Source.GetValue() == source) && (r.Target == nil || r.Target.GetValue() == target)
+}
+
+// EdgeInverse pairs an edge type with the type of the edge that always runs
+// the other way between the same vertices.
+type EdgeInverse struct {
+	Edge    *pb.Identifier
+	Inverse *pb.Identifier
+}
+
+// EdgeRegistry holds the rules for each edge type and the inverse of each
+// paired edge type.
+type EdgeRegistry struct {
+	rules    map[string][]*EdgeRule
+	inverses map[string]*pb.Identifier
+}
+
+// NewEdgeRegistry returns a registry of the given rules and inverse pairs.
+// Each pair is registered both ways, and each rule of a paired edge type
+// implies the reversed rule for its inverse. It's an error to pair an edge
+// type with two different inverses.
+func NewEdgeRegistry(rules []*EdgeRule, inverses []*EdgeInverse) (*EdgeRegistry, error) {
+	r := &EdgeRegistry{rules: map[string][]*EdgeRule{}, inverses: map[string]*pb.Identifier{}}
+	for _, rule := range rules {
+		if rule.Edge.GetValue() == "" {
+			return nil, errors.New("edge rule has no edge type")
+		}
+		r.add(rule)
+	}
+	for _, pair := range inverses {
+		if err := r.pair(pair.Edge, pair.Inverse); err != nil {
+			return nil, err
+		}
+		if err := r.pair(pair.Inverse, pair.Edge); err != nil {
+			return nil, err
+		}
+	}
+	for _, pair := range inverses {
+		for _, rule := range r.rules[pair.Edge.GetValue()] {
+			r.add(&EdgeRule{Source: rule.Target, Edge: pair.Inverse, Target: rule.Source})
+		}
+		for _, rule := range r.rules[pair.Inverse.GetValue()] {
+			r.add(&EdgeRule{Source: rule.Target, Edge: pair.Edge, Target: rule.Source})
+		}
+	}
+	return r, nil
+}
+
+func MustNewEdgeRegistry(rules []*EdgeRule, inverses []*EdgeInverse) *EdgeRegistry {
+	r, err := NewEdgeRegistry(rules, inverses)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// add adds rule unless an identical one is already registered.
+func (r *EdgeRegistry) add(rule *EdgeRule) {
+	edge := rule.Edge.GetValue()
+	for _, existing := range r.rules[edge] {
+		if existing.String() == rule.String() {
+			return
+		}
+	}
+	r.rules[edge] = append(r.rules[edge], rule)
+}
+
+func (r *EdgeRegistry) pair(edge, inverse *pb.Identifier) error {
+	if edge.GetValue() == "" || inverse.GetValue() == "" {
+		return errors.New("inverse pair is missing an edge type")
+	}
+	if existing, ok := r.inverses[edge.GetValue()]; ok && existing.GetValue() != inverse.GetValue() {
+		return fmt.Errorf("edge type %q has inverses %q and %q", edge.GetValue(), existing.GetValue(), inverse.GetValue())
+	}
+	r.inverses[edge.GetValue()] = inverse
+	return nil
+}
+
+// Declared reports whether the edge type has rules.
+func (r *EdgeRegistry) Declared(edge string) bool {
+	_, ok := r.rules[edge]
+	return ok
+}
+
+// Check returns nil if an edge of the given type may run from a vertex of
+// type source to one of type target.
+func (r *EdgeRegistry) Check(source, edge, target string) error {
+	rules, ok := r.rules[edge]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownEdgeType, edge)
+	}
+	for _, rule := range rules {
+		if rule.allows(source, target) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -%s-> %s", ErrEdgeNotAllowed, source, edge, target)
+}
+
+// Inverse returns the type of the edge that runs the other way alongside
+// edges of the given type, or nil if it has none.
+func (r *EdgeRegistry) Inverse(edge string) *pb.Identifier {
+	return r.inverses[edge]
+}
+
+// Rules returns the rules for the given edge type, sorted for display.
+func (r *EdgeRegistry) Rules(edge string) []*EdgeRule {
+	rules := append([]*EdgeRule(nil), r.rules[edge]...)
+	sort.Slice(rules, func(i, j int) bool { return rules[i].String() < rules[j].String() })
+	return rules
+}
+
+func typeName(t *pb.Identifier) string {
+	if t == nil {
+		return "*"
+	}
+	return t.GetValue()
+}
+
+// rulesBetween returns a rule allowing edge from each of sources to each of
+// targets. A nil list allows any vertex type.
+func rulesBetween(edge *pb.Identifier, sources, targets []*pb.Identifier) []*EdgeRule {
+	if sources == nil {
+		sources = []*pb.Identifier{nil}
+	}
+	if targets == nil {
+		targets = []*pb.Identifier{nil}
+	}
+	var rules []*EdgeRule
+	for _, source := range sources {
+		for _, target := range targets {
+			rules = append(rules, &EdgeRule{Source: source, Edge: edge, Target: target})
+		}
+	}
+	return rules
+}
+
+// itemTypes are the vertex types of publishable content.
+var itemTypes = []*pb.Identifier{
+	ArticleType, ModuleType, &NewsArticle, &NewsWireArticle, &NewsWireBulletin, &PlaceType, WebLinkType,
+}
+
+// EdgeRules declares which vertex types each edge type may connect, and which
+// edge types are kept in sync as inverse pairs.
+var EdgeRules = MustNewEdgeRegistry(concatRules(
+	rulesBetween(&Published, []*pb.Identifier{&NewsPublisher, &NewsAuthor}, itemTypes),
+	rulesBetween(&PublishesAbout, append([]*pb.Identifier{&NewsPublisher}, itemTypes...), []*pb.Identifier{&NewsCategory}),
+	rulesBetween(&ItemAbout, append([]*pb.Identifier{&NewsPublisher}, itemTypes...), []*pb.Identifier{&NewsCategory}),
+	rulesBetween(&IllustratedBy, itemTypes, []*pb.Identifier{&NewsGeoJSON, &NewsImage}),
+	rulesBetween(&CoversTopic, []*pb.Identifier{&NewsPublisher, &NewsAuthor}, []*pb.Identifier{&NewsCategory}),
+	rulesBetween(&Featuring, itemTypes, nil),
+	rulesBetween(&HasRevision, []*pb.Identifier{ArticleType}, []*pb.Identifier{&NewsRevision}),
+	rulesBetween(&IsRelated, itemTypes, itemTypes),
+	rulesBetween(&HasOffice, []*pb.Identifier{&CityPrimary}, []*pb.Identifier{&CityMayorOffice}),
+	rulesBetween(&HasConstituency, []*pb.Identifier{&CityPrimary}, []*pb.Identifier{&CityWard}),
+	rulesBetween(&LocatedIn, nil, []*pb.Identifier{&CityWard, &CityNeighbourhood}),
+	rulesBetween(HasOtherLocation, []*pb.Identifier{&PlaceType}, []*pb.Identifier{&PlaceType}),
+), []*EdgeInverse{
+	{Edge: &Published, Inverse: &PublishedBy},
+})
+
+func concatRules(lists ...[]*EdgeRule) []*EdgeRule {
+	var rules []*EdgeRule
+	for _, l := range lists {
+		rules = append(rules, l...)
+	}
+	return rules
+}
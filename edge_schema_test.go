@@ -0,0 +1,58 @@
+package citygraph
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/geomodulus/citygraph/pb"
+)
+
+func TestEdgeRulesCheck(t *testing.T) {
+	for _, tc := range []struct {
+		source, edge, target string
+		want                 error
+	}{
+		{NewsPublisher.Value, Published.Value, ArticleType.Value, nil},
+		{ArticleType.Value, PublishedBy.Value, NewsAuthor.Value, nil},
+		{ArticleType.Value, Published.Value, NewsPublisher.Value, ErrEdgeNotAllowed},
+		{NewsPublisher.Value, PublishedBy.Value, ArticleType.Value, ErrEdgeNotAllowed},
+		{PlaceType.Value, LocatedIn.Value, CityWard.Value, nil},
+		{PlaceType.Value, LocatedIn.Value, NewsCategory.Value, ErrEdgeNotAllowed},
+		{ArticleType.Value, "no-such-edge", ArticleType.Value, ErrUnknownEdgeType},
+	} {
+		if err := EdgeRules.Check(tc.source, tc.edge, tc.target); !errors.Is(err, tc.want) {
+			t.Errorf("EdgeRules.Check(%s, %s, %s) returned %v, want %v", tc.source, tc.edge, tc.target, err, tc.want)
+		}
+	}
+	if got := EdgeRules.Inverse(Published.Value); got.GetValue() != PublishedBy.Value {
+		t.Errorf("EdgeRules.Inverse(published) = %v, want published-by", got)
+	}
+	if got := EdgeRules.Inverse(PublishedBy.Value); got.GetValue() != Published.Value {
+		t.Errorf("EdgeRules.Inverse(published-by) = %v, want published", got)
+	}
+	if got := EdgeRules.Inverse(ItemAbout.Value); got != nil {
+		t.Errorf("EdgeRules.Inverse(item-about) = %v, want nil", got)
+	}
+}
+
+func TestNewEdgeRegistry(t *testing.T) {
+	parent, child := &pb.Identifier{Value: "parent-of"}, &pb.Identifier{Value: "child-of"}
+	r, err := NewEdgeRegistry([]*EdgeRule{{Source: &CityPrimary, Edge: parent, Target: &CityWard}}, []*EdgeInverse{{Edge: parent, Inverse: child}})
+	if err != nil {
+		t.Fatalf("NewEdgeRegistry() returned err: %v", err)
+	}
+	var got []string
+	for _, rule := range r.Rules(child.Value) {
+		got = append(got, rule.String())
+	}
+	if diff := cmp.Diff([]string{"city-ward -child-of-> city-primary"}, got); diff != "" {
+		t.Errorf("NewEdgeRegistry() implied inverse rules diff:\n%s\n", diff)
+	}
+
+	other := &pb.Identifier{Value: "other-of"}
+	if _, err := NewEdgeRegistry(nil, []*EdgeInverse{{Edge: parent, Inverse: child}, {Edge: parent, Inverse: other}}); err == nil {
+		t.Errorf("NewEdgeRegistry() with two inverses for one edge type returned nil err")
+	}
+}
@@ -0,0 +1,319 @@
+package feedproducer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+)
+
+// FeedClient is a typed client for the FeedProducer service.
+type FeedClient interface {
+	AddAnnouncement(ctx context.Context, id uuid.UUID) error
+	AddContent(ctx context.Context, content *Content) error
+	RemoveContent(ctx context.Context, id uuid.UUID) error
+	ReadLatest(ctx context.Context, count int) ([]*ContentItem, error)
+	// ReadFiltered is like ReadLatest but only returns items matching the
+	// filter.
+	ReadFiltered(ctx context.Context, count int, filter *FeedFilter) ([]*ContentItem, error)
+	QueueItem(ctx context.Context, id uuid.UUID) error
+	// WatchFeed calls fn for each item inserted in the feed after the one
+	// with ID afterFeedID, or from now on if it's "", until ctx is done or fn
+	// returns an error. If types are given, only items of those content
+	// types are passed to fn.
+	WatchFeed(ctx context.Context, afterFeedID string, types []pb.ContentType, fn func(*ContentItem) error) error
+	// ReadHistory returns the events concerning the content with the given
+	// ID at or after since, oldest first. A zero since returns them all.
+	ReadHistory(ctx context.Context, id uuid.UUID, since time.Time) ([]*HistoryEvent, error)
+
+	// ListActiveReleases and ListAllReleases drain the release stream.
+	ListActiveReleases(ctx context.Context) ([]*Release, error)
+	ListAllReleases(ctx context.Context) ([]*Release, error)
+	// IterActiveReleases and IterAllReleases call fn for each release as it
+	// arrives, stopping at the first error fn returns.
+	IterActiveReleases(ctx context.Context, fn func(*Release) error) error
+	IterAllReleases(ctx context.Context, fn func(*Release) error) error
+}
+
+// Content is an item to add to the feed.
+type Content struct {
+	Type pb.ContentType
+	// ID is the UUID of the content's graph vertex.
+	ID uuid.UUID
+	// Wait is the minimum time between appearances in the feed.
+	Wait time.Duration
+	// Until, if set, promotes the content from now until this time.
+	Until time.Time
+	// Expires, if set, stops the content being promoted after this time.
+	Expires time.Time
+	// ImmediateRelease schedules the content to be released right away.
+	ImmediateRelease bool
+}
+
+// NewVertexContent returns content for the vertex with the given ID and type,
+// looking up its content type in ContentTypes.
+func NewVertexContent(vertexType string, id uuid.UUID) (*Content, error) {
+	ct, err := ContentTypes.ContentType(vertexType)
+	if err != nil {
+		return nil, err
+	}
+	return &Content{Type: ct, ID: id}, nil
+}
+
+// WebLinkAdder adds web link vertices to the feed by ID, as
+// db.Store.PublishWebLink expects.
+type WebLinkAdder struct {
+	Feed FeedClient
+}
+
+// AddWebLink adds the web link vertex with the given ID as WEB_LINK content.
+func (a WebLinkAdder) AddWebLink(ctx context.Context, id uuid.UUID) error {
+	return a.Feed.AddContent(ctx, &Content{Type: pb.ContentType_WEB_LINK, ID: id})
+}
+
+// Request returns the AddContentRequest for the content.
+func (c *Content) Request() *pb.AddContentRequest {
+	req := &pb.AddContentRequest{
+		ContentType:      c.Type,
+		Id:               c.ID.String(),
+		ImmediateRelease: c.ImmediateRelease,
+	}
+	if c.Wait != 0 {
+		req.Wait = durationpb.New(c.Wait)
+	}
+	if !c.Until.IsZero() {
+		req.Until = timestamppb.New(c.Until)
+	}
+	if !c.Expires.IsZero() {
+		req.Expires = timestamppb.New(c.Expires)
+	}
+	return req
+}
+
+// ContentItem is one insertion of content into the live feed.
+type ContentItem struct {
+	// FeedID identifies this insertion; the same content may be inserted
+	// many times.
+	FeedID    string    `json:"feed_id"`
+	ContentID uuid.UUID `json:"content_id"`
+	AddedAt   time.Time `json:"added_at"`
+	// ContentType is CONTENT_TYPE_UNKNOWN for announcements.
+	ContentType pb.ContentType `json:"content_type,omitempty"`
+}
+
+// IsAnnouncement reports whether the item is an announcement rather than
+// content. Announcements have no content type and pass every type filter.
+func (i *ContentItem) IsAnnouncement() bool {
+	return i.ContentType == pb.ContentType_CONTENT_TYPE_UNKNOWN
+}
+
+func newContentItem(item *pb.ContentItem) (*ContentItem, error) {
+	id, err := uuid.Parse(item.ContentId)
+	if err != nil {
+		return nil, fmt.Errorf("feed item %s: content id: %w", item.FeedId, err)
+	}
+	return &ContentItem{
+		FeedID:      item.FeedId,
+		ContentID:   id,
+		AddedAt:     item.AddedAt.AsTime(),
+		ContentType: item.ContentType,
+	}, nil
+}
+
+func (i *ContentItem) proto() *pb.ContentItem {
+	return &pb.ContentItem{
+		FeedId:      i.FeedID,
+		ContentId:   i.ContentID.String(),
+		AddedAt:     timestamppb.New(i.AddedAt),
+		ContentType: i.ContentType,
+	}
+}
+
+// Release is content scheduled for release into the feed.
+type Release struct {
+	ContentID uuid.UUID
+	// VertexType is the content's vertex type in the graph.
+	VertexType string
+	// ContentType is VertexType's content type in ContentTypes, or
+	// CONTENT_TYPE_UNKNOWN if it has none.
+	ContentType pb.ContentType
+}
+
+// NewRelease returns the release of the given vertex, looking up its content
+// type in ContentTypes.
+func NewRelease(id uuid.UUID, vertexType string) *Release {
+	ct, _ := ContentTypes.ContentType(vertexType)
+	return &Release{ContentID: id, VertexType: vertexType, ContentType: ct}
+}
+
+// Client implements FeedClient over a gRPC connection.
+type Client struct {
+	feed pb.FeedProducerClient
+}
+
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{pb.NewFeedProducerClient(conn)}
+}
+
+// AddAnnouncement adds the citygraph.AnnouncementType vertex with the given ID
+// to the feed. db.Store.PublishAnnouncement and SyncAnnouncements call it for
+// announcements that have started.
+func (c *Client) AddAnnouncement(ctx context.Context, id uuid.UUID) error {
+	_, err := c.feed.AddAnnouncement(ctx, &pb.AddAnnouncementRequest{Id: id.String()})
+	return err
+}
+
+func (c *Client) AddContent(ctx context.Context, content *Content) error {
+	_, err := c.feed.AddContent(ctx, content.Request())
+	return err
+}
+
+func (c *Client) RemoveContent(ctx context.Context, id uuid.UUID) error {
+	_, err := c.feed.RemoveContent(ctx, &pb.RemoveContentRequest{Id: id.String()})
+	return err
+}
+
+func (c *Client) ReadLatest(ctx context.Context, count int) ([]*ContentItem, error) {
+	return c.ReadFiltered(ctx, count, nil)
+}
+
+func (c *Client) ReadFiltered(ctx context.Context, count int, filter *FeedFilter) ([]*ContentItem, error) {
+	resp, err := c.feed.ReadLatest(ctx, &pb.ReadLatestRequest{Count: int32(count), Filter: filter.proto()})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*ContentItem, 0, len(resp.Latest))
+	for _, item := range resp.Latest {
+		converted, err := newContentItem(item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, converted)
+	}
+	return items, nil
+}
+
+func (c *Client) QueueItem(ctx context.Context, id uuid.UUID) error {
+	_, err := c.feed.QueueItem(ctx, &pb.QueueItemRequest{Id: id.String()})
+	return err
+}
+
+func (c *Client) WatchFeed(ctx context.Context, afterFeedID string, types []pb.ContentType, fn func(*ContentItem) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.feed.WatchFeed(ctx, &pb.WatchFeedRequest{AfterFeedId: afterFeedID, ContentTypes: types})
+	if err != nil {
+		return err
+	}
+	for {
+		item, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		converted, err := newContentItem(item)
+		if err != nil {
+			return err
+		}
+		if err := fn(converted); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) ReadHistory(ctx context.Context, id uuid.UUID, since time.Time) ([]*HistoryEvent, error) {
+	req := &pb.ReadHistoryRequest{Id: id.String()}
+	if !since.IsZero() {
+		req.Since = timestamppb.New(since)
+	}
+	resp, err := c.feed.ReadHistory(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*HistoryEvent, 0, len(resp.Events))
+	for _, event := range resp.Events {
+		converted, err := newHistoryEvent(event)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, converted)
+	}
+	return events, nil
+}
+
+func (c *Client) ListActiveReleases(ctx context.Context) ([]*Release, error) {
+	return drainReleases(ctx, c.IterActiveReleases)
+}
+
+func (c *Client) ListAllReleases(ctx context.Context) ([]*Release, error) {
+	return drainReleases(ctx, c.IterAllReleases)
+}
+
+func (c *Client) IterActiveReleases(ctx context.Context, fn func(*Release) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.feed.ListActiveReleases(ctx, &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+	return iterReleases(stream, fn)
+}
+
+func (c *Client) IterAllReleases(ctx context.Context, fn func(*Release) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.feed.ListAllReleases(ctx, &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+	return iterReleases(stream, fn)
+}
+
+// releaseStream is the receiving side of both release listing streams.
+type releaseStream interface {
+	Recv() (*pb.ReleaseItem, error)
+}
+
+func iterReleases(stream releaseStream, fn func(*Release) error) error {
+	for {
+		item, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		id, err := uuid.Parse(item.ContentId)
+		if err != nil {
+			return fmt.Errorf("release content id: %w", err)
+		}
+		if err := fn(NewRelease(id, item.VertexType)); err != nil {
+			return err
+		}
+	}
+}
+
+func drainReleases(ctx context.Context, iter func(context.Context, func(*Release) error) error) ([]*Release, error) {
+	var releases []*Release
+	err := iter(ctx, func(r *Release) error {
+		releases = append(releases, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
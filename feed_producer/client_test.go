@@ -0,0 +1,149 @@
+package feedproducer
+
+import (
+	"context"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+)
+
+const bufSize = 1024 * 1024
+
+type fakeFeedServer struct {
+	pb.UnimplementedFeedProducerServer
+
+	addContentReqs  []*pb.AddContentRequest
+	readLatestResp  *pb.ReadLatestResponse
+	allReleasesResp []*pb.ReleaseItem
+}
+
+func (f *fakeFeedServer) AddContent(ctx context.Context, req *pb.AddContentRequest) (*emptypb.Empty, error) {
+	f.addContentReqs = append(f.addContentReqs, req)
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fakeFeedServer) ReadLatest(ctx context.Context, req *pb.ReadLatestRequest) (*pb.ReadLatestResponse, error) {
+	return f.readLatestResp, nil
+}
+
+func (f *fakeFeedServer) ListAllReleases(_ *emptypb.Empty, stream pb.FeedProducer_ListAllReleasesServer) error {
+	for _, item := range f.allReleasesResp {
+		if err := stream.Send(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestClient(t *testing.T, server pb.FeedProducerServer) *Client {
+	t.Helper()
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	pb.RegisterFeedProducerServer(s, server)
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			log.Fatal(err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial bufnet: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewClient(conn)
+}
+
+func TestClientAddContent(t *testing.T) {
+	fakeServer := &fakeFeedServer{}
+	client := newTestClient(t, fakeServer)
+	id := uuid.New()
+	expires := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := client.AddContent(context.Background(), &Content{
+		Type:    pb.ContentType_POINT_OF_INTEREST,
+		ID:      id,
+		Wait:    time.Hour,
+		Expires: expires,
+	}); err != nil {
+		t.Fatalf("client.AddContent() returned err: %v", err)
+	}
+	want := []*pb.AddContentRequest{{
+		ContentType: pb.ContentType_POINT_OF_INTEREST,
+		Id:          id.String(),
+		Wait:        durationpb.New(time.Hour),
+		Expires:     timestamppb.New(expires),
+	}}
+	if diff := cmp.Diff(want, fakeServer.addContentReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("client.AddContent() sent req diff:\n%s\n", diff)
+	}
+}
+
+func TestWebLinkAdder(t *testing.T) {
+	fakeServer := &fakeFeedServer{}
+	client := newTestClient(t, fakeServer)
+	id := uuid.New()
+	if err := (WebLinkAdder{Feed: client}).AddWebLink(context.Background(), id); err != nil {
+		t.Fatalf("WebLinkAdder.AddWebLink() returned err: %v", err)
+	}
+	want := []*pb.AddContentRequest{{ContentType: pb.ContentType_WEB_LINK, Id: id.String()}}
+	if diff := cmp.Diff(want, fakeServer.addContentReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("WebLinkAdder.AddWebLink() sent req diff:\n%s\n", diff)
+	}
+}
+
+func TestClientReadLatest(t *testing.T) {
+	id := uuid.New()
+	addedAt := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	client := newTestClient(t, &fakeFeedServer{
+		readLatestResp: &pb.ReadLatestResponse{Latest: []*pb.ContentItem{{
+			FeedId:    "feed-1",
+			ContentId: id.String(),
+			AddedAt:   timestamppb.New(addedAt),
+		}}},
+	})
+	got, err := client.ReadLatest(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("client.ReadLatest() returned err: %v", err)
+	}
+	want := []*ContentItem{{FeedID: "feed-1", ContentID: id, AddedAt: addedAt}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("client.ReadLatest() diff:\n%s\n", diff)
+	}
+}
+
+func TestClientListAllReleases(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	client := newTestClient(t, &fakeFeedServer{
+		allReleasesResp: []*pb.ReleaseItem{
+			{ContentId: a.String(), VertexType: "news-contributor-article"},
+			{ContentId: b.String(), VertexType: "place"},
+		},
+	})
+	got, err := client.ListAllReleases(context.Background())
+	if err != nil {
+		t.Fatalf("client.ListAllReleases() returned err: %v", err)
+	}
+	want := []*Release{
+		{ContentID: a, VertexType: "news-contributor-article", ContentType: pb.ContentType_ARTICLE},
+		{ContentID: b, VertexType: "place"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("client.ListAllReleases() diff:\n%s\n", diff)
+	}
+}
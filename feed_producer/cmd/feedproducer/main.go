@@ -0,0 +1,64 @@
+// Command feedproducer runs the reference FeedProducer server against an
+// IndraDB graph.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/geomodulus/citygraph"
+	feedproducer "github.com/geomodulus/citygraph/feed_producer"
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+)
+
+var (
+	listenAddr = flag.String("listen", ":8081", "address to serve FeedProducer on")
+	graphAddr  = flag.String("graph", "localhost:27615", "address of the IndraDB graph")
+	queuePath  = flag.String("queue", "feed_queue.json", "file to keep the feed queue in, with its history in the same name plus .history; empty to keep both in memory")
+	interval   = flag.Duration("interval", time.Minute, "how often to release due content")
+)
+
+func main() {
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	conn, err := grpc.Dial(*graphAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("dial graph %s: %v", *graphAddr, err)
+	}
+	defer conn.Close()
+
+	queue, err := feedproducer.OpenQueue(*queuePath)
+	if err != nil {
+		log.Fatalf("open queue: %v", err)
+	}
+	server := feedproducer.NewServer(citygraph.NewClient(conn), queue)
+
+	lis, err := net.Listen("tcp", *listenAddr)
+	if err != nil {
+		log.Fatalf("listen %s: %v", *listenAddr, err)
+	}
+	s := grpc.NewServer()
+	pb.RegisterFeedProducerServer(s, server)
+
+	go server.Run(ctx, *interval)
+	go func() {
+		<-ctx.Done()
+		s.GracefulStop()
+	}()
+
+	log.Printf("feed producer listening on %s", lis.Addr())
+	if err := s.Serve(lis); err != nil {
+		log.Fatalf("serve: %v", err)
+	}
+}
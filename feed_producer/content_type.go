@@ -0,0 +1,110 @@
+package feedproducer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+)
+
+var (
+	// ErrUnmappedContentType is returned for a content type with no graph
+	// vertex type.
+	ErrUnmappedContentType = errors.New("content type has no vertex type")
+	// ErrUnmappedVertexType is returned for a vertex type that can't be
+	// added to the feed.
+	ErrUnmappedVertexType = errors.New("vertex type has no content type")
+)
+
+// ContentTypeRegistry maps feed content types to graph vertex types and back.
+// Each content type maps to at most one vertex type, and vice versa.
+type ContentTypeRegistry struct {
+	vertexTypes  map[pb.ContentType]string
+	contentTypes map[string]pb.ContentType
+}
+
+// ContentTypes is the registry of content types the feed knows about. Code
+// that enqueues content or reads a ReleaseItem's vertex_type should use it
+// rather than relying on the types' names.
+var ContentTypes = MustNewContentTypeRegistry(map[pb.ContentType]string{
+	pb.ContentType_POINT_OF_INTEREST: citygraph.PlaceType.Value,
+	pb.ContentType_NEWSWIRE_ARTICLE:  citygraph.NewsWireArticle.Value,
+	pb.ContentType_ARTICLE:           citygraph.ArticleType.Value,
+	pb.ContentType_NEWSWIRE_BULLETIN: citygraph.NewsWireBulletin.Value,
+	pb.ContentType_WEB_LINK:          citygraph.WebLinkType.Value,
+})
+
+// NewContentTypeRegistry returns a registry with the given mappings. It's an
+// error to map CONTENT_TYPE_UNKNOWN, or two content types to one vertex type.
+func NewContentTypeRegistry(mappings map[pb.ContentType]string) (*ContentTypeRegistry, error) {
+	r := &ContentTypeRegistry{
+		vertexTypes:  map[pb.ContentType]string{},
+		contentTypes: map[string]pb.ContentType{},
+	}
+	for ct, vt := range mappings {
+		if err := r.register(ct, vt); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// MustNewContentTypeRegistry is like NewContentTypeRegistry but panics if the
+// mappings are invalid. It's for registries built from literals, like
+// ContentTypes.
+func MustNewContentTypeRegistry(mappings map[pb.ContentType]string) *ContentTypeRegistry {
+	r, err := NewContentTypeRegistry(mappings)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (r *ContentTypeRegistry) register(ct pb.ContentType, vt string) error {
+	switch {
+	case ct == pb.ContentType_CONTENT_TYPE_UNKNOWN:
+		return fmt.Errorf("can't map %s to vertex type %q", ct, vt)
+	case vt == "":
+		return fmt.Errorf("content type %s: empty vertex type", ct)
+	}
+	if other, ok := r.contentTypes[vt]; ok && other != ct {
+		return fmt.Errorf("vertex type %q is mapped to both %s and %s", vt, other, ct)
+	}
+	r.vertexTypes[ct] = vt
+	r.contentTypes[vt] = ct
+	return nil
+}
+
+// VertexType returns the vertex type of content of the given type.
+func (r *ContentTypeRegistry) VertexType(ct pb.ContentType) (string, error) {
+	vt, ok := r.vertexTypes[ct]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnmappedContentType, ct)
+	}
+	return vt, nil
+}
+
+// ContentType returns the content type for vertices of the given type.
+func (r *ContentTypeRegistry) ContentType(vertexType string) (pb.ContentType, error) {
+	ct, ok := r.contentTypes[vertexType]
+	if !ok {
+		return pb.ContentType_CONTENT_TYPE_UNKNOWN, fmt.Errorf("%w: %q", ErrUnmappedVertexType, vertexType)
+	}
+	return ct, nil
+}
+
+// Unmapped lists the content types defined in service.proto that have no
+// vertex type, in enum order.
+func (r *ContentTypeRegistry) Unmapped() []pb.ContentType {
+	var unmapped []pb.ContentType
+	for n := range pb.ContentType_name {
+		ct := pb.ContentType(n)
+		if _, ok := r.vertexTypes[ct]; !ok && ct != pb.ContentType_CONTENT_TYPE_UNKNOWN {
+			unmapped = append(unmapped, ct)
+		}
+	}
+	sort.Slice(unmapped, func(i, j int) bool { return unmapped[i] < unmapped[j] })
+	return unmapped
+}
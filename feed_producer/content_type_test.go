@@ -0,0 +1,52 @@
+package feedproducer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+)
+
+func TestContentTypes(t *testing.T) {
+	for ct, vt := range map[pb.ContentType]string{
+		pb.ContentType_POINT_OF_INTEREST: "poi",
+		pb.ContentType_NEWSWIRE_ARTICLE:  "news-wire-article",
+		pb.ContentType_ARTICLE:           "news-contributor-article",
+		pb.ContentType_NEWSWIRE_BULLETIN: "news-wire-bulletin",
+	} {
+		gotVT, err := ContentTypes.VertexType(ct)
+		if err != nil || gotVT != vt {
+			t.Errorf("ContentTypes.VertexType(%s) = %q, %v; want %q", ct, gotVT, err, vt)
+		}
+		gotCT, err := ContentTypes.ContentType(vt)
+		if err != nil || gotCT != ct {
+			t.Errorf("ContentTypes.ContentType(%q) = %s, %v; want %s", vt, gotCT, err, ct)
+		}
+	}
+
+	if _, err := ContentTypes.VertexType(pb.ContentType_SPORTS); !errors.Is(err, ErrUnmappedContentType) {
+		t.Errorf("ContentTypes.VertexType(SPORTS) returned %v, want ErrUnmappedContentType", err)
+	}
+	if _, err := ContentTypes.ContentType(citygraph.ModuleType.Value); !errors.Is(err, ErrUnmappedVertexType) {
+		t.Errorf("ContentTypes.ContentType(module) returned %v, want ErrUnmappedVertexType", err)
+	}
+	want := []pb.ContentType{pb.ContentType_SPORTS, pb.ContentType_TRANSIT}
+	if diff := cmp.Diff(want, ContentTypes.Unmapped()); diff != "" {
+		t.Errorf("ContentTypes.Unmapped() diff:\n%s\n", diff)
+	}
+}
+
+func TestNewContentTypeRegistryErrors(t *testing.T) {
+	for name, mappings := range map[string]map[pb.ContentType]string{
+		"unknown":   {pb.ContentType_CONTENT_TYPE_UNKNOWN: "poi"},
+		"empty":     {pb.ContentType_SPORTS: ""},
+		"duplicate": {pb.ContentType_SPORTS: "poi", pb.ContentType_POINT_OF_INTEREST: "poi"},
+	} {
+		if _, err := NewContentTypeRegistry(mappings); err == nil {
+			t.Errorf("NewContentTypeRegistry(%s) returned nil err", name)
+		}
+	}
+}
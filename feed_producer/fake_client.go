@@ -0,0 +1,187 @@
+package feedproducer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+)
+
+// FakeFeedClient records the requests it receives and returns canned
+// responses, in order, for the methods that read from the feed.
+type FakeFeedClient struct {
+	sync.Mutex
+
+	AddAnnouncementReqs []uuid.UUID
+	AddContentReqs      []*Content
+	RemoveContentReqs   []uuid.UUID
+	QueueItemReqs       []uuid.UUID
+
+	ReadLatestReqs  []int
+	ReadLatestResps [][]*ContentItem
+
+	ReadFilteredReqs  []*pb.ReadLatestRequest
+	ReadFilteredResps [][]*ContentItem
+
+	ListActiveReleasesResps [][]*Release
+	ListAllReleasesResps    [][]*Release
+
+	WatchFeedReqs  []*pb.WatchFeedRequest
+	WatchFeedResps [][]*ContentItem
+
+	ReadHistoryReqs  []*pb.ReadHistoryRequest
+	ReadHistoryResps [][]*HistoryEvent
+}
+
+func (f *FakeFeedClient) AddAnnouncement(ctx context.Context, id uuid.UUID) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.AddAnnouncementReqs = append(f.AddAnnouncementReqs, id)
+	return nil
+}
+
+func (f *FakeFeedClient) AddContent(ctx context.Context, content *Content) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.AddContentReqs = append(f.AddContentReqs, content)
+	return nil
+}
+
+func (f *FakeFeedClient) RemoveContent(ctx context.Context, id uuid.UUID) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.RemoveContentReqs = append(f.RemoveContentReqs, id)
+	return nil
+}
+
+func (f *FakeFeedClient) QueueItem(ctx context.Context, id uuid.UUID) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.QueueItemReqs = append(f.QueueItemReqs, id)
+	return nil
+}
+
+func (f *FakeFeedClient) ReadLatest(ctx context.Context, count int) ([]*ContentItem, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if len(f.ReadLatestResps) == 0 {
+		return nil, errors.New("ReadLatest: fake has no response to return")
+	}
+	f.ReadLatestReqs = append(f.ReadLatestReqs, count)
+	items, remaining := f.ReadLatestResps[0], f.ReadLatestResps[1:]
+	f.ReadLatestResps = remaining
+	return items, nil
+}
+
+// WatchFeed passes the next canned items to fn, filtered by type, then
+// returns nil as if the stream had ended.
+func (f *FakeFeedClient) WatchFeed(ctx context.Context, afterFeedID string, types []pb.ContentType, fn func(*ContentItem) error) error {
+	f.Lock()
+	if len(f.WatchFeedResps) == 0 {
+		f.Unlock()
+		return errors.New("WatchFeed: fake has no response to return")
+	}
+	f.WatchFeedReqs = append(f.WatchFeedReqs, &pb.WatchFeedRequest{AfterFeedId: afterFeedID, ContentTypes: types})
+	items, remaining := f.WatchFeedResps[0], f.WatchFeedResps[1:]
+	f.WatchFeedResps = remaining
+	f.Unlock()
+
+	for _, item := range items {
+		if !matchesTypes(types, item) {
+			continue
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *FakeFeedClient) ReadHistory(ctx context.Context, id uuid.UUID, since time.Time) ([]*HistoryEvent, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if len(f.ReadHistoryResps) == 0 {
+		return nil, errors.New("ReadHistory: fake has no response to return")
+	}
+	req := &pb.ReadHistoryRequest{Id: id.String()}
+	if !since.IsZero() {
+		req.Since = timestamppb.New(since)
+	}
+	f.ReadHistoryReqs = append(f.ReadHistoryReqs, req)
+	events, remaining := f.ReadHistoryResps[0], f.ReadHistoryResps[1:]
+	f.ReadHistoryResps = remaining
+	return events, nil
+}
+
+func (f *FakeFeedClient) ReadFiltered(ctx context.Context, count int, filter *FeedFilter) ([]*ContentItem, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if len(f.ReadFilteredResps) == 0 {
+		return nil, errors.New("ReadFiltered: fake has no response to return")
+	}
+	f.ReadFilteredReqs = append(f.ReadFilteredReqs, &pb.ReadLatestRequest{Count: int32(count), Filter: filter.proto()})
+	items, remaining := f.ReadFilteredResps[0], f.ReadFilteredResps[1:]
+	f.ReadFilteredResps = remaining
+	return items, nil
+}
+
+func (f *FakeFeedClient) ListActiveReleases(ctx context.Context) ([]*Release, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if len(f.ListActiveReleasesResps) == 0 {
+		return nil, errors.New("ListActiveReleases: fake has no response to return")
+	}
+	releases, remaining := f.ListActiveReleasesResps[0], f.ListActiveReleasesResps[1:]
+	f.ListActiveReleasesResps = remaining
+	return releases, nil
+}
+
+func (f *FakeFeedClient) ListAllReleases(ctx context.Context) ([]*Release, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if len(f.ListAllReleasesResps) == 0 {
+		return nil, errors.New("ListAllReleases: fake has no response to return")
+	}
+	releases, remaining := f.ListAllReleasesResps[0], f.ListAllReleasesResps[1:]
+	f.ListAllReleasesResps = remaining
+	return releases, nil
+}
+
+func (f *FakeFeedClient) IterActiveReleases(ctx context.Context, fn func(*Release) error) error {
+	releases, err := f.ListActiveReleases(ctx)
+	if err != nil {
+		return err
+	}
+	return iterSlice(releases, fn)
+}
+
+func (f *FakeFeedClient) IterAllReleases(ctx context.Context, fn func(*Release) error) error {
+	releases, err := f.ListAllReleases(ctx)
+	if err != nil {
+		return err
+	}
+	return iterSlice(releases, fn)
+}
+
+func iterSlice(releases []*Release, fn func(*Release) error) error {
+	for _, r := range releases {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
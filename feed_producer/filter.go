@@ -0,0 +1,248 @@
+package feedproducer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+	graphpb "github.com/geomodulus/citygraph/pb"
+)
+
+// FeedFilter narrows the feed down to matching content, for local or topical
+// feeds. Content must match every criterion that's set, and any one of the
+// values for each. Membership of categories and areas is resolved through
+// graph edges.
+type FeedFilter struct {
+	// CategoryIDs are news-category vertices the content is citygraph.ItemAbout,
+	// as written by db.Store for each category; see citygraph.CategoryID.
+	CategoryIDs []uuid.UUID
+	// AreaIDs are ward or neighbourhood vertices the content is
+	// citygraph.LocatedIn, as written by db.Store.WriteLocatedIn.
+	AreaIDs []uuid.UUID
+	// ContentTypes limits content to the given types. Announcements pass
+	// whatever the types.
+	ContentTypes []pb.ContentType
+	// BBox, as [west, south, east, north], must contain the content's
+	// location property or camera center.
+	BBox []float64
+}
+
+func newFeedFilter(f *pb.FeedFilter) (*FeedFilter, error) {
+	if f == nil {
+		return nil, nil
+	}
+	filter := &FeedFilter{ContentTypes: f.ContentTypes, BBox: f.Bbox}
+	var err error
+	if filter.CategoryIDs, err = parseIDs(f.CategoryIds); err != nil {
+		return nil, fmt.Errorf("category: %w", err)
+	}
+	if filter.AreaIDs, err = parseIDs(f.AreaIds); err != nil {
+		return nil, fmt.Errorf("area: %w", err)
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func (f *FeedFilter) proto() *pb.FeedFilter {
+	if f == nil {
+		return nil
+	}
+	filter := &pb.FeedFilter{ContentTypes: f.ContentTypes, Bbox: f.BBox}
+	for _, id := range f.CategoryIDs {
+		filter.CategoryIds = append(filter.CategoryIds, id.String())
+	}
+	for _, id := range f.AreaIDs {
+		filter.AreaIds = append(filter.AreaIds, id.String())
+	}
+	return filter
+}
+
+// Validate checks the bounding box, if any.
+func (f *FeedFilter) Validate() error {
+	if len(f.BBox) == 0 {
+		return nil
+	}
+	if len(f.BBox) != 4 {
+		return fmt.Errorf("bbox has %d values, want 4", len(f.BBox))
+	}
+	west, south, east, north := f.BBox[0], f.BBox[1], f.BBox[2], f.BBox[3]
+	if west < -180 || east > 180 || south < -90 || north > 90 || west > east || south > north {
+		return fmt.Errorf("bbox %v is not [west, south, east, north]", f.BBox)
+	}
+	return nil
+}
+
+// IsEmpty reports whether the filter matches everything.
+func (f *FeedFilter) IsEmpty() bool {
+	return f == nil || len(f.CategoryIDs) == 0 && len(f.AreaIDs) == 0 && len(f.ContentTypes) == 0 && len(f.BBox) == 0
+}
+
+// Filter returns the items whose content matches, in order. It makes at most
+// one graph request per criterion.
+func (f *FeedFilter) Filter(ctx context.Context, graph citygraph.GraphClient, items []*ContentItem) ([]*ContentItem, error) {
+	if f.IsEmpty() || len(items) == 0 {
+		return items, nil
+	}
+	candidates := items
+	if len(f.ContentTypes) > 0 {
+		candidates = nil
+		for _, item := range items {
+			if matchesTypes(f.ContentTypes, item) {
+				candidates = append(candidates, item)
+			}
+		}
+	}
+	for _, criterion := range []struct {
+		edge    *graphpb.Identifier
+		targets []uuid.UUID
+	}{
+		{&citygraph.ItemAbout, f.CategoryIDs},
+		{&citygraph.LocatedIn, f.AreaIDs},
+	} {
+		if len(criterion.targets) == 0 || len(candidates) == 0 {
+			continue
+		}
+		members, err := linkedTo(ctx, graph, candidates, criterion.edge, criterion.targets)
+		if err != nil {
+			return nil, err
+		}
+		candidates = keep(candidates, members)
+	}
+	if len(f.BBox) > 0 && len(candidates) > 0 {
+		inside, err := f.inBBox(ctx, graph, candidates)
+		if err != nil {
+			return nil, err
+		}
+		candidates = keep(candidates, inside)
+	}
+	return candidates, nil
+}
+
+// linkedTo returns the content with an edge of type t to any of the targets.
+func linkedTo(ctx context.Context, graph citygraph.GraphClient, items []*ContentItem, t *graphpb.Identifier, targets []uuid.UUID) (map[uuid.UUID]bool, error) {
+	wanted := map[uuid.UUID]bool{}
+	for _, id := range targets {
+		wanted[id] = true
+	}
+	edges, err := graph.GetEdges(ctx, citygraph.NewPipeEdgeQuery(contentQuery(items), graphpb.EdgeDirection_OUTBOUND, t))
+	if err != nil {
+		return nil, err
+	}
+	linked := map[uuid.UUID]bool{}
+	for _, e := range edges {
+		in, err := uuid.FromBytes(e.GetKey().GetInboundId().GetValue())
+		if err != nil {
+			return nil, err
+		}
+		if !wanted[in] {
+			continue
+		}
+		out, err := uuid.FromBytes(e.GetKey().GetOutboundId().GetValue())
+		if err != nil {
+			return nil, err
+		}
+		linked[out] = true
+	}
+	return linked, nil
+}
+
+// inBBox returns the content located within the filter's bounding box.
+func (f *FeedFilter) inBBox(ctx context.Context, graph citygraph.GraphClient, items []*ContentItem) (map[uuid.UUID]bool, error) {
+	all, err := graph.GetAllVertexProperties(ctx, contentQuery(items))
+	if err != nil {
+		return nil, err
+	}
+	inside := map[uuid.UUID]bool{}
+	for _, v := range all {
+		id, err := uuid.FromBytes(v.GetVertex().GetId().GetValue())
+		if err != nil {
+			return nil, err
+		}
+		loc, err := location(v.GetProps())
+		if err != nil {
+			return nil, fmt.Errorf("content %s: %w", id, err)
+		}
+		if loc != nil && loc.Lng >= f.BBox[0] && loc.Lat >= f.BBox[1] && loc.Lng <= f.BBox[2] && loc.Lat <= f.BBox[3] {
+			inside[id] = true
+		}
+	}
+	return inside, nil
+}
+
+// location returns the vertex's location property, or its camera's center,
+// or nil if it has neither.
+func location(props []*graphpb.NamedProperty) (*citygraph.LngLat, error) {
+	var camera *citygraph.LngLat
+	for _, prop := range props {
+		switch prop.GetName().GetValue() {
+		case "location":
+			var loc citygraph.LngLat
+			if err := json.Unmarshal([]byte(prop.GetValue().GetValue()), &loc); err != nil {
+				return nil, fmt.Errorf("location: %w", err)
+			}
+			return &loc, nil
+		case "camera":
+			var c citygraph.Camera
+			if err := json.Unmarshal([]byte(prop.GetValue().GetValue()), &c); err != nil {
+				return nil, fmt.Errorf("camera: %w", err)
+			}
+			camera = c.Center
+		}
+	}
+	return camera, nil
+}
+
+func contentQuery(items []*ContentItem) *graphpb.VertexQuery {
+	seen := map[uuid.UUID]bool{}
+	var ids []*graphpb.Uuid
+	for _, item := range items {
+		if !seen[item.ContentID] {
+			seen[item.ContentID] = true
+			ids = append(ids, citygraph.UUID(item.ContentID))
+		}
+	}
+	return citygraph.NewSpecificVertexQuery(ids...)
+}
+
+func keep(items []*ContentItem, ids map[uuid.UUID]bool) []*ContentItem {
+	var kept []*ContentItem
+	for _, item := range items {
+		if ids[item.ContentID] {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func parseIDs(ids []string) ([]uuid.UUID, error) {
+	var parsed []uuid.UUID
+	for _, id := range ids {
+		u, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("id %q: %w", id, err)
+		}
+		parsed = append(parsed, u)
+	}
+	return parsed, nil
+}
+
+// matchesTypes reports whether an item passes a content type filter: the
+// filter is empty, the item is an announcement, or its type is listed.
+func matchesTypes(types []pb.ContentType, item *ContentItem) bool {
+	return len(types) == 0 || item.IsAnnouncement() || containsType(types, item.ContentType)
+}
+
+func containsType(types []pb.ContentType, ct pb.ContentType) bool {
+	for _, t := range types {
+		if t == ct {
+			return true
+		}
+	}
+	return false
+}
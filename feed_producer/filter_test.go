@@ -0,0 +1,115 @@
+package feedproducer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+	"github.com/geomodulus/citygraph/graphtest"
+	graphpb "github.com/geomodulus/citygraph/pb"
+)
+
+func edge(out uuid.UUID, t *graphpb.Identifier, in uuid.UUID) *graphpb.Edge {
+	return &graphpb.Edge{Key: &graphpb.EdgeKey{OutboundId: citygraph.UUID(out), T: t, InboundId: citygraph.UUID(in)}}
+}
+
+func TestFeedFilter(t *testing.T) {
+	annex := uuid.New()
+	article, place, other := uuid.New(), uuid.New(), uuid.New()
+	items := []*ContentItem{
+		{FeedID: "3", ContentID: article, ContentType: pb.ContentType_ARTICLE},
+		{FeedID: "2", ContentID: place, ContentType: pb.ContentType_POINT_OF_INTEREST},
+		{FeedID: "1", ContentID: other, ContentType: pb.ContentType_ARTICLE},
+	}
+
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetEdgesResps: [][]*graphpb.Edge{{
+			edge(article, &citygraph.LocatedIn, annex),
+			edge(place, &citygraph.LocatedIn, annex),
+			edge(other, &citygraph.LocatedIn, uuid.New()),
+		}},
+		GetAllVertexPropertiesResps: [][]*graphpb.VertexProperties{{{
+			Vertex: &graphpb.Vertex{Id: citygraph.UUID(article)},
+			Props: []*graphpb.NamedProperty{
+				{Name: &graphpb.Identifier{Value: "camera"}, Value: &graphpb.Json{Value: `{"center":[-79.40,43.67],"zoom":14}`}},
+			},
+		}, {
+			Vertex: &graphpb.Vertex{Id: citygraph.UUID(place)},
+			Props: []*graphpb.NamedProperty{
+				{Name: &graphpb.Identifier{Value: "location"}, Value: &graphpb.Json{Value: `{"lng":-79.2,"lat":43.8}`}},
+			},
+		}}},
+	}
+	filter := &FeedFilter{AreaIDs: []uuid.UUID{annex}, BBox: []float64{-79.45, 43.6, -79.35, 43.7}}
+	got, err := filter.Filter(context.Background(), fakeGraph, items)
+	if err != nil {
+		t.Fatalf("Filter() returned err: %v", err)
+	}
+	if diff := cmp.Diff(items[:1], got); diff != "" {
+		t.Errorf("Filter() diff:\n%s\n", diff)
+	}
+	allContent := citygraph.NewSpecificVertexQuery(citygraph.UUID(article), citygraph.UUID(place), citygraph.UUID(other))
+	wantEdgesReqs := []*graphpb.EdgeQuery{citygraph.NewPipeEdgeQuery(allContent, graphpb.EdgeDirection_OUTBOUND, &citygraph.LocatedIn)}
+	if diff := cmp.Diff(wantEdgesReqs, fakeGraph.GetEdgesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("Filter() sent get edges req diff:\n%s\n", diff)
+	}
+	wantPropsReqs := []*graphpb.VertexQuery{citygraph.NewSpecificVertexQuery(citygraph.UUID(article), citygraph.UUID(place))}
+	if diff := cmp.Diff(wantPropsReqs, fakeGraph.GetAllVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("Filter() sent get all vertex properties req diff:\n%s\n", diff)
+	}
+
+	// Content type filtering doesn't need the graph, and lets announcements
+	// through.
+	announcement := &ContentItem{FeedID: "4", ContentID: uuid.New()}
+	byType := &FeedFilter{ContentTypes: []pb.ContentType{pb.ContentType_POINT_OF_INTEREST}}
+	got, err = byType.Filter(context.Background(), nil, append([]*ContentItem{announcement}, items...))
+	if err != nil {
+		t.Fatalf("Filter() returned err: %v", err)
+	}
+	if diff := cmp.Diff([]*ContentItem{announcement, items[1]}, got); diff != "" {
+		t.Errorf("Filter() by content type diff:\n%s\n", diff)
+	}
+
+	for _, bbox := range [][]float64{{1, 2, 3}, {10, 0, -10, 5}, {0, -95, 10, 0}} {
+		if err := (&FeedFilter{BBox: bbox}).Validate(); err == nil {
+			t.Errorf("Validate() of bbox %v returned nil err", bbox)
+		}
+	}
+}
+
+func TestServerReadLatestFiltered(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	category := uuid.New()
+	about, notAbout := uuid.New(), uuid.New()
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetEdgesResps: [][]*graphpb.Edge{{edge(about, &citygraph.ItemAbout, category)}},
+	}
+	queue, _ := OpenQueue("")
+	client := newTestClient(t, &Server{Graph: fakeGraph, Queue: queue, Now: func() time.Time { return now }})
+	for _, id := range []uuid.UUID{about, notAbout} {
+		if err := queue.Add(&Content{Type: pb.ContentType_ARTICLE, ID: id}, now); err != nil {
+			t.Fatalf("queue.Add() returned err: %v", err)
+		}
+	}
+	if _, err := queue.Release(now); err != nil {
+		t.Fatalf("queue.Release() returned err: %v", err)
+	}
+
+	got, err := client.ReadFiltered(context.Background(), 10, &FeedFilter{CategoryIDs: []uuid.UUID{category}})
+	if err != nil {
+		t.Fatalf("client.ReadFiltered() returned err: %v", err)
+	}
+	if diff := cmp.Diff([]uuid.UUID{about}, releasedIDs(got)); diff != "" {
+		t.Errorf("client.ReadFiltered() diff:\n%s\n", diff)
+	}
+
+	if _, err := client.ReadFiltered(context.Background(), 10, &FeedFilter{BBox: []float64{1}}); err == nil {
+		t.Error("client.ReadFiltered() with bad bbox returned nil err")
+	}
+}
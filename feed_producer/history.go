@@ -0,0 +1,228 @@
+package feedproducer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+)
+
+// MaxHistoryPerContent is the number of history events a Queue keeps for
+// each piece of content.
+const MaxHistoryPerContent = 100
+
+// Triggers recorded in history events besides the names of the RPCs.
+const (
+	// TriggerSchedule marks events caused by the passage of time.
+	TriggerSchedule = "schedule"
+)
+
+// HistoryEvent records something the feed did with a piece of content.
+type HistoryEvent struct {
+	ContentID uuid.UUID           `json:"content_id"`
+	Type      pb.HistoryEventType `json:"type"`
+	At        time.Time           `json:"at"`
+	// Trigger is the RPC that caused the event, or TriggerSchedule.
+	Trigger string `json:"trigger"`
+	// FeedID is the feed item inserted by a release.
+	FeedID string `json:"feed_id,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func newHistoryEvent(event *pb.HistoryEvent) (*HistoryEvent, error) {
+	id, err := uuid.Parse(event.ContentId)
+	if err != nil {
+		return nil, fmt.Errorf("history event: content id: %w", err)
+	}
+	return &HistoryEvent{
+		ContentID: id,
+		Type:      event.Type,
+		At:        event.At.AsTime(),
+		Trigger:   event.Trigger,
+		FeedID:    event.FeedId,
+		Detail:    event.Detail,
+	}, nil
+}
+
+func (e *HistoryEvent) proto() *pb.HistoryEvent {
+	return &pb.HistoryEvent{
+		ContentId: e.ContentID.String(),
+		Type:      e.Type,
+		At:        timestamppb.New(e.At),
+		Trigger:   e.Trigger,
+		FeedId:    e.FeedID,
+		Detail:    e.Detail,
+	}
+}
+
+// History returns the events concerning the content with the given ID at or
+// after since, oldest first. Only the latest MaxHistoryPerContent events for
+// each piece of content are kept.
+func (q *Queue) History(id uuid.UUID, since time.Time) []*HistoryEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var events []*HistoryEvent
+	for _, e := range q.history[id] {
+		if !e.At.Before(since) {
+			copied := *e
+			events = append(events, &copied)
+		}
+	}
+	return events
+}
+
+// record adds an event to the history, to be appended to the history file
+// by the next save. The caller must hold q.mu.
+func (q *Queue) record(event *HistoryEvent) {
+	q.remember(event)
+	if q.path != "" {
+		q.unsaved = append(q.unsaved, event)
+	}
+}
+
+// remember adds an event to the history kept in memory, dropping the oldest
+// event for the same content if it has too many.
+func (q *Queue) remember(event *HistoryEvent) {
+	events := append(q.history[event.ContentID], event)
+	if extra := len(events) - MaxHistoryPerContent; extra > 0 {
+		events = append([]*HistoryEvent(nil), events[extra:]...)
+	}
+	q.history[event.ContentID] = events
+}
+
+// historyPath is the file history events are appended to, one JSON object
+// per line.
+func (q *Queue) historyPath() string {
+	return q.path + ".history"
+}
+
+// loadHistory reads the history file, along with any history saved in the
+// queue file itself by older versions. The file is rewritten with only the
+// events kept if it held others, including lines that can't be decoded such
+// as one cut short by a crash.
+func (q *Queue) loadHistory() error {
+	legacy := q.state.History
+	q.state.History = nil
+	for _, event := range legacy {
+		q.remember(event)
+	}
+	read := 0
+	f, err := os.Open(q.historyPath())
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			read++
+			var event HistoryEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				continue
+			}
+			q.remember(&event)
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("history %s: %w", q.historyPath(), err)
+		}
+	}
+	kept := 0
+	for _, events := range q.history {
+		kept += len(events)
+	}
+	if len(legacy) == 0 && kept == read {
+		return nil
+	}
+	if err := q.rewriteHistory(); err != nil {
+		return err
+	}
+	if len(legacy) > 0 {
+		return q.save()
+	}
+	return nil
+}
+
+// rewriteHistory replaces the history file with the events kept in memory.
+func (q *Queue) rewriteHistory() error {
+	var events []*HistoryEvent
+	for _, kept := range q.history {
+		events = append(events, kept...)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	b, err := encodeHistory(events)
+	if err != nil {
+		return err
+	}
+	tmp := q.historyPath() + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.historyPath())
+}
+
+// appendHistory appends the events recorded since the last save to the
+// history file.
+func (q *Queue) appendHistory() error {
+	if len(q.unsaved) == 0 {
+		return nil
+	}
+	b, err := encodeHistory(q.unsaved)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(q.historyPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	q.unsaved = nil
+	return nil
+}
+
+func encodeHistory(events []*HistoryEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// describeSchedule summarizes an entry's schedule for ADDED and UPDATED
+// events.
+func describeSchedule(e *queueEntry) string {
+	parts := []string{e.Type.String()}
+	if e.Wait > 0 {
+		parts = append(parts, "wait "+e.Wait.String())
+	}
+	if !e.Until.IsZero() {
+		parts = append(parts, "until "+e.Until.Format(time.RFC3339))
+	}
+	if !e.Expires.IsZero() {
+		parts = append(parts, "expires "+e.Expires.Format(time.RFC3339))
+	}
+	if e.Queued {
+		parts = append(parts, "immediate release")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,180 @@
+package feedproducer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+	"github.com/geomodulus/citygraph/graphtest"
+)
+
+func TestQueueHistory(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	id, other := uuid.New(), uuid.New()
+	q, _ := OpenQueue("")
+
+	steps := []func() error{
+		func() error {
+			return q.Add(&Content{Type: pb.ContentType_ARTICLE, ID: id, Wait: time.Hour, Expires: now.Add(3 * time.Hour)}, now)
+		},
+		func() error { return q.Add(&Content{Type: pb.ContentType_ARTICLE, ID: other}, now) },
+		func() error { _, err := q.Release(now); return err },
+		func() error { _, err := q.Release(now.Add(time.Hour)); return err },
+		func() error { return q.Enqueue(id, now.Add(90*time.Minute)) },
+		func() error { _, err := q.Release(now.Add(90 * time.Minute)); return err },
+		func() error { _, err := q.Release(now.Add(3 * time.Hour)); return err },
+		func() error { return q.Remove(other, now.Add(4*time.Hour)) },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d returned err: %v", i, err)
+		}
+	}
+
+	want := []*HistoryEvent{
+		{ContentID: id, Type: pb.HistoryEventType_ADDED, At: now, Trigger: "AddContent", Detail: "ARTICLE, wait 1h0m0s, expires 2023-03-01T15:00:00Z"},
+		{ContentID: id, Type: pb.HistoryEventType_RELEASED, At: now, Trigger: "AddContent", Detail: "first release"},
+		{ContentID: id, Type: pb.HistoryEventType_RE_RELEASED, At: now.Add(time.Hour), Trigger: TriggerSchedule, Detail: "wait of 1h0m0s elapsed"},
+		{ContentID: id, Type: pb.HistoryEventType_QUEUED, At: now.Add(90 * time.Minute), Trigger: "QueueItem"},
+		{ContentID: id, Type: pb.HistoryEventType_RE_RELEASED, At: now.Add(90 * time.Minute), Trigger: "QueueItem", Detail: "queued for immediate release"},
+		{ContentID: id, Type: pb.HistoryEventType_EXPIRED, At: now.Add(3 * time.Hour), Trigger: TriggerSchedule, Detail: "expired at 2023-03-01T15:00:00Z"},
+	}
+	ignoreFeedID := cmpopts.IgnoreFields(HistoryEvent{}, "FeedID")
+	if diff := cmp.Diff(want, q.History(id, time.Time{}), ignoreFeedID); diff != "" {
+		t.Errorf("History() diff:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(want[3:], q.History(id, now.Add(90*time.Minute)), ignoreFeedID); diff != "" {
+		t.Errorf("History() since diff:\n%s\n", diff)
+	}
+
+	// Each release records the feed item it inserted.
+	latest := q.Latest(1)
+	events := q.History(id, now.Add(90*time.Minute))
+	if events[1].FeedID != latest[0].FeedID {
+		t.Errorf("History() release feed id = %q, want %q", events[1].FeedID, latest[0].FeedID)
+	}
+
+	otherEvents := q.History(other, now.Add(4*time.Hour))
+	wantOther := []*HistoryEvent{{ContentID: other, Type: pb.HistoryEventType_REMOVED, At: now.Add(4 * time.Hour), Trigger: "RemoveContent", Detail: "taken out of the feed"}}
+	if diff := cmp.Diff(wantOther, otherEvents); diff != "" {
+		t.Errorf("History() of removed content diff:\n%s\n", diff)
+	}
+}
+
+func TestQueueHistoryPersists(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "queue.json")
+	id, other := uuid.New(), uuid.New()
+	q, err := OpenQueue(path)
+	if err != nil {
+		t.Fatalf("OpenQueue() returned err: %v", err)
+	}
+	if err := q.Add(&Content{Type: pb.ContentType_ARTICLE, ID: id}, now); err != nil {
+		t.Fatalf("Add() returned err: %v", err)
+	}
+	for i := 0; i < MaxHistoryPerContent; i++ {
+		if err := q.Enqueue(id, now.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("Enqueue() returned err: %v", err)
+		}
+	}
+	if err := q.Add(&Content{Type: pb.ContentType_ARTICLE, ID: other}, now); err != nil {
+		t.Fatalf("Add() returned err: %v", err)
+	}
+
+	events := q.History(id, time.Time{})
+	if len(events) != MaxHistoryPerContent || events[0].Type != pb.HistoryEventType_QUEUED {
+		t.Fatalf("History() returned %d events starting with %v, want the latest %d", len(events), events[0].Type, MaxHistoryPerContent)
+	}
+	if b, _ := os.ReadFile(path); bytes.Contains(b, []byte(`"history"`)) {
+		t.Errorf("queue file holds history: %s", b)
+	}
+
+	reopened, err := OpenQueue(path)
+	if err != nil {
+		t.Fatalf("OpenQueue() returned err: %v", err)
+	}
+	if diff := cmp.Diff(events, reopened.History(id, time.Time{})); diff != "" {
+		t.Errorf("History() after reopening diff:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(q.History(other, time.Time{}), reopened.History(other, time.Time{})); diff != "" {
+		t.Errorf("History() of other content after reopening diff:\n%s\n", diff)
+	}
+	// Reopening drops the events that weren't kept from the file.
+	b, err := os.ReadFile(path + ".history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(b), "\n"); got != MaxHistoryPerContent+1 {
+		t.Errorf("history file has %d events after reopening, want %d", got, MaxHistoryPerContent+1)
+	}
+}
+
+func TestQueueMovesHistoryOutOfQueueFile(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "queue.json")
+	id := uuid.New()
+	saved := `{"content":[],"announcements":[],"feed":[],"history":[` +
+		`{"content_id":"` + id.String() + `","type":1,"at":"2023-03-01T12:00:00Z","trigger":"AddContent"}]}`
+	if err := os.WriteFile(path, []byte(saved), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := OpenQueue(path)
+	if err != nil {
+		t.Fatalf("OpenQueue() returned err: %v", err)
+	}
+	want := []*HistoryEvent{{ContentID: id, Type: pb.HistoryEventType_ADDED, At: now, Trigger: "AddContent"}}
+	if diff := cmp.Diff(want, q.History(id, time.Time{})); diff != "" {
+		t.Errorf("History() diff:\n%s\n", diff)
+	}
+	if b, _ := os.ReadFile(path); bytes.Contains(b, []byte(`"history"`)) {
+		t.Errorf("queue file still holds history: %s", b)
+	}
+	reopened, err := OpenQueue(path)
+	if err != nil {
+		t.Fatalf("OpenQueue() returned err: %v", err)
+	}
+	if diff := cmp.Diff(want, reopened.History(id, time.Time{})); diff != "" {
+		t.Errorf("History() after reopening diff:\n%s\n", diff)
+	}
+}
+
+func TestClientReadHistory(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	id := uuid.New()
+	graph := graphtest.NewMemoryGraphClient()
+	if err := graph.CreateVertex(context.Background(), citygraph.UUID(id), citygraph.ArticleType); err != nil {
+		t.Fatal(err)
+	}
+	queue, _ := OpenQueue("")
+	server := &Server{Graph: graph, Queue: queue, Now: func() time.Time { return now }}
+	client := newTestClient(t, server)
+
+	if err := client.AddContent(context.Background(), &Content{Type: pb.ContentType_ARTICLE, ID: id, ImmediateRelease: true}); err != nil {
+		t.Fatalf("client.AddContent() returned err: %v", err)
+	}
+	if _, err := queue.Release(now); err != nil {
+		t.Fatalf("queue.Release() returned err: %v", err)
+	}
+	got, err := client.ReadHistory(context.Background(), id, time.Time{})
+	if err != nil {
+		t.Fatalf("client.ReadHistory() returned err: %v", err)
+	}
+	want := []*HistoryEvent{
+		{ContentID: id, Type: pb.HistoryEventType_ADDED, At: now, Trigger: "AddContent", Detail: "ARTICLE, immediate release"},
+		{ContentID: id, Type: pb.HistoryEventType_RELEASED, At: now, Trigger: "AddContent", Detail: "queued for immediate release", FeedID: queue.LastFeedID()},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("client.ReadHistory() diff:\n%s\n", diff)
+	}
+}
@@ -37,6 +37,10 @@ type FeedProducerClient interface {
 	ListAllReleases(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (FeedProducer_ListAllReleasesClient, error)
 	// QueueItem schedules an item for immediate release.
 	QueueItem(ctx context.Context, in *QueueItemRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// WatchFeed streams ContentItem messages as they're inserted in the feed.
+	WatchFeed(ctx context.Context, in *WatchFeedRequest, opts ...grpc.CallOption) (FeedProducer_WatchFeedClient, error)
+	// ReadHistory returns what the feed has done with a piece of content.
+	ReadHistory(ctx context.Context, in *ReadHistoryRequest, opts ...grpc.CallOption) (*ReadHistoryResponse, error)
 }
 
 type feedProducerClient struct {
@@ -156,6 +160,47 @@ func (c *feedProducerClient) QueueItem(ctx context.Context, in *QueueItemRequest
 	return out, nil
 }
 
+func (c *feedProducerClient) WatchFeed(ctx context.Context, in *WatchFeedRequest, opts ...grpc.CallOption) (FeedProducer_WatchFeedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &FeedProducer_ServiceDesc.Streams[2], "/feed_producer.FeedProducer/WatchFeed", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &feedProducerWatchFeedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FeedProducer_WatchFeedClient interface {
+	Recv() (*ContentItem, error)
+	grpc.ClientStream
+}
+
+type feedProducerWatchFeedClient struct {
+	grpc.ClientStream
+}
+
+func (x *feedProducerWatchFeedClient) Recv() (*ContentItem, error) {
+	m := new(ContentItem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *feedProducerClient) ReadHistory(ctx context.Context, in *ReadHistoryRequest, opts ...grpc.CallOption) (*ReadHistoryResponse, error) {
+	out := new(ReadHistoryResponse)
+	err := c.cc.Invoke(ctx, "/feed_producer.FeedProducer/ReadHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FeedProducerServer is the server API for FeedProducer service.
 // All implementations must embed UnimplementedFeedProducerServer
 // for forward compatibility
@@ -174,6 +219,10 @@ type FeedProducerServer interface {
 	ListAllReleases(*emptypb.Empty, FeedProducer_ListAllReleasesServer) error
 	// QueueItem schedules an item for immediate release.
 	QueueItem(context.Context, *QueueItemRequest) (*emptypb.Empty, error)
+	// WatchFeed streams ContentItem messages as they're inserted in the feed.
+	WatchFeed(*WatchFeedRequest, FeedProducer_WatchFeedServer) error
+	// ReadHistory returns what the feed has done with a piece of content.
+	ReadHistory(context.Context, *ReadHistoryRequest) (*ReadHistoryResponse, error)
 	mustEmbedUnimplementedFeedProducerServer()
 }
 
@@ -202,6 +251,12 @@ func (UnimplementedFeedProducerServer) ListAllReleases(*emptypb.Empty, FeedProdu
 func (UnimplementedFeedProducerServer) QueueItem(context.Context, *QueueItemRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueueItem not implemented")
 }
+func (UnimplementedFeedProducerServer) WatchFeed(*WatchFeedRequest, FeedProducer_WatchFeedServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchFeed not implemented")
+}
+func (UnimplementedFeedProducerServer) ReadHistory(context.Context, *ReadHistoryRequest) (*ReadHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadHistory not implemented")
+}
 func (UnimplementedFeedProducerServer) mustEmbedUnimplementedFeedProducerServer() {}
 
 // UnsafeFeedProducerServer may be embedded to opt out of forward compatibility for this service.
@@ -347,6 +402,45 @@ func _FeedProducer_QueueItem_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FeedProducer_WatchFeed_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchFeedRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FeedProducerServer).WatchFeed(m, &feedProducerWatchFeedServer{stream})
+}
+
+type FeedProducer_WatchFeedServer interface {
+	Send(*ContentItem) error
+	grpc.ServerStream
+}
+
+type feedProducerWatchFeedServer struct {
+	grpc.ServerStream
+}
+
+func (x *feedProducerWatchFeedServer) Send(m *ContentItem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _FeedProducer_ReadHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeedProducerServer).ReadHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/feed_producer.FeedProducer/ReadHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeedProducerServer).ReadHistory(ctx, req.(*ReadHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // FeedProducer_ServiceDesc is the grpc.ServiceDesc for FeedProducer service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -374,6 +468,10 @@ var FeedProducer_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "QueueItem",
 			Handler:    _FeedProducer_QueueItem_Handler,
 		},
+		{
+			MethodName: "ReadHistory",
+			Handler:    _FeedProducer_ReadHistory_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -386,6 +484,11 @@ var FeedProducer_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _FeedProducer_ListAllReleases_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchFeed",
+			Handler:       _FeedProducer_WatchFeed_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "service.proto",
 }
@@ -0,0 +1,164 @@
+// Package feedproducer works with the FeedProducer service defined in
+// service.proto, which generates the live feeds for Geomodulus.
+package feedproducer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+)
+
+// PromotionAction is what a Promoter does with an article's feed entry.
+type PromotionAction int
+
+const (
+	// PromotionSubmit adds the article to the feed, or updates its entry.
+	PromotionSubmit PromotionAction = iota
+	// PromotionDefer leaves the feed alone until the promotion starts.
+	PromotionDefer
+	// PromotionRemove takes the article out of the feed.
+	PromotionRemove
+)
+
+func (a PromotionAction) String() string {
+	switch a {
+	case PromotionSubmit:
+		return "submit"
+	case PromotionDefer:
+		return "defer"
+	case PromotionRemove:
+		return "remove"
+	}
+	return fmt.Sprintf("PromotionAction(%d)", int(a))
+}
+
+// Promotion describes how an article should appear in the feed at a given
+// time.
+type Promotion struct {
+	Action PromotionAction
+	// Content is the feed entry to submit when Action is PromotionSubmit.
+	Content *Content
+	// Start is when a deferred promotion begins.
+	Start time.Time
+	// Reason explains a defer or removal.
+	Reason string
+}
+
+// PlanPromotion decides what to do with the article's feed entry at now,
+// based on its promo fields:
+//
+//   - promo_start, if set, is when the article enters the feed;
+//   - promo_until, if set, ends the window of intensive promotion;
+//   - promo_wait is the minimum time between appearances in the feed;
+//   - promo_expires, if set, is when the article leaves the feed for good.
+//
+// Articles that aren't live, or have no promo fields set, are removed. An
+// inconsistent promotion window is returned as a *citygraph.ValidationError.
+func PlanPromotion(article *citygraph.Article, now time.Time) (*Promotion, error) {
+	if err := article.ValidatePromotion(); err != nil {
+		return nil, err
+	}
+	switch {
+	case !article.IsLive:
+		return &Promotion{Action: PromotionRemove, Reason: "article is not live"}, nil
+	case !isPromoted(article):
+		return &Promotion{Action: PromotionRemove, Reason: "article has no promotion"}, nil
+	case !article.PromoExpires.IsZero() && !now.Before(article.PromoExpires):
+		return &Promotion{Action: PromotionRemove, Reason: "promotion expired at " + article.PromoExpires.Format(time.RFC3339)}, nil
+	case !article.PromoAt.IsZero() && now.Before(article.PromoAt):
+		return &Promotion{Action: PromotionDefer, Start: article.PromoAt, Reason: "promotion starts at " + article.PromoAt.Format(time.RFC3339)}, nil
+	}
+	content, err := NewContent(article, now)
+	if err != nil {
+		return nil, err
+	}
+	return &Promotion{Action: PromotionSubmit, Content: content}, nil
+}
+
+func isPromoted(a *citygraph.Article) bool {
+	return !a.PromoAt.IsZero() || !a.PromoUntil.IsZero() || !a.PromoExpires.IsZero() || a.PromoWait.Duration > 0
+}
+
+// NewContent converts the article's promo fields into the feed entry to add
+// at now. A promo_until already in the past is left out. ImmediateRelease is
+// never set; see Promoter.Release.
+func NewContent(article *citygraph.Article, now time.Time) (*Content, error) {
+	id, err := article.UUID()
+	if err != nil {
+		return nil, err
+	}
+	content, err := NewVertexContent(citygraph.ArticleType.Value, id)
+	if err != nil {
+		return nil, err
+	}
+	content.Expires = article.PromoExpires
+	if article.PromoWait.Duration > 0 {
+		content.Wait = article.PromoWait.Duration
+	}
+	if !article.PromoUntil.IsZero() && now.Before(article.PromoUntil) {
+		content.Until = article.PromoUntil
+	}
+	return content, nil
+}
+
+// NewAddContentRequest is like NewContent but returns the raw request.
+func NewAddContentRequest(article *citygraph.Article, now time.Time) (*pb.AddContentRequest, error) {
+	content, err := NewContent(article, now)
+	if err != nil {
+		return nil, err
+	}
+	return content.Request(), nil
+}
+
+// Promoter keeps articles' feed entries in line with their promo fields.
+type Promoter struct {
+	Feed FeedClient
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+}
+
+func NewPromoter(feed FeedClient) *Promoter {
+	return &Promoter{Feed: feed, Now: time.Now}
+}
+
+// Sync submits, updates or removes the article's feed entry as planned by
+// PlanPromotion, returning the plan it carried out.
+func (p *Promoter) Sync(ctx context.Context, article *citygraph.Article) (*Promotion, error) {
+	return p.apply(ctx, article, false)
+}
+
+// Release is like Sync but asks for a submitted article to be released into
+// the feed immediately. Use it when the promotion starts, not on every sync.
+func (p *Promoter) Release(ctx context.Context, article *citygraph.Article) (*Promotion, error) {
+	return p.apply(ctx, article, true)
+}
+
+func (p *Promoter) apply(ctx context.Context, article *citygraph.Article, immediate bool) (*Promotion, error) {
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+	promo, err := PlanPromotion(article, now())
+	if err != nil {
+		return nil, err
+	}
+	switch promo.Action {
+	case PromotionSubmit:
+		promo.Content.ImmediateRelease = immediate
+		if err := p.Feed.AddContent(ctx, promo.Content); err != nil {
+			return nil, fmt.Errorf("add content %s: %w", article.ID, err)
+		}
+	case PromotionRemove:
+		id, err := article.UUID()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Feed.RemoveContent(ctx, id); err != nil {
+			return nil, fmt.Errorf("remove content %s: %w", article.ID, err)
+		}
+	}
+	return promo, nil
+}
@@ -0,0 +1,104 @@
+package feedproducer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+)
+
+func TestPlanPromotion(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	id := citygraph.NewID()
+	article := func(promoAt, until, expires time.Time) *citygraph.Article {
+		return &citygraph.Article{
+			ID:           id.String(),
+			IsLive:       true,
+			PromoAt:      promoAt,
+			PromoUntil:   until,
+			PromoExpires: expires,
+			PromoWait:    citygraph.Duration{Duration: 4 * time.Hour},
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		article *citygraph.Article
+		want    *Promotion
+	}{{
+		name:    "active",
+		article: article(now.Add(-time.Hour), now.Add(24*time.Hour), now.Add(72*time.Hour)),
+		want: &Promotion{Action: PromotionSubmit, Content: &Content{
+			Type:    pb.ContentType_ARTICLE,
+			ID:      id,
+			Wait:    4 * time.Hour,
+			Until:   now.Add(24 * time.Hour),
+			Expires: now.Add(72 * time.Hour),
+		}},
+	}, {
+		name:    "past until",
+		article: article(now.Add(-48*time.Hour), now.Add(-24*time.Hour), time.Time{}),
+		want: &Promotion{Action: PromotionSubmit, Content: &Content{
+			Type: pb.ContentType_ARTICLE,
+			ID:   id,
+			Wait: 4 * time.Hour,
+		}},
+	}, {
+		name:    "not started",
+		article: article(now.Add(time.Hour), time.Time{}, time.Time{}),
+		want:    &Promotion{Action: PromotionDefer, Start: now.Add(time.Hour), Reason: "promotion starts at 2023-03-01T13:00:00Z"},
+	}, {
+		name:    "expired",
+		article: article(time.Time{}, time.Time{}, now),
+		want:    &Promotion{Action: PromotionRemove, Reason: "promotion expired at 2023-03-01T12:00:00Z"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := PlanPromotion(tc.article, now)
+			if err != nil {
+				t.Fatalf("PlanPromotion() returned err: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("PlanPromotion() diff:\n%s\n", diff)
+			}
+		})
+	}
+
+	t.Run("inconsistent", func(t *testing.T) {
+		_, err := PlanPromotion(article(now, now.Add(48*time.Hour), now.Add(24*time.Hour)), now)
+		var verr *citygraph.ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("PlanPromotion() returned %v, want *citygraph.ValidationError", err)
+		}
+	})
+}
+
+func TestPromoter(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	live := &citygraph.Article{ID: citygraph.NewID().String(), IsLive: true, PromoAt: now}
+	unpublished := &citygraph.Article{ID: citygraph.NewID().String(), PromoAt: now}
+
+	feed := &FakeFeedClient{}
+	p := &Promoter{Feed: feed, Now: func() time.Time { return now }}
+	if _, err := p.Release(context.Background(), live); err != nil {
+		t.Fatalf("Release() returned err: %v", err)
+	}
+	if _, err := p.Sync(context.Background(), unpublished); err != nil {
+		t.Fatalf("Sync() returned err: %v", err)
+	}
+
+	liveID, _ := live.UUID()
+	wantAdd := []*Content{{Type: pb.ContentType_ARTICLE, ID: liveID, ImmediateRelease: true}}
+	if diff := cmp.Diff(wantAdd, feed.AddContentReqs); diff != "" {
+		t.Errorf("Promoter sent add content req diff:\n%s\n", diff)
+	}
+	unpublishedID, _ := unpublished.UUID()
+	if diff := cmp.Diff([]uuid.UUID{unpublishedID}, feed.RemoveContentReqs); diff != "" {
+		t.Errorf("Promoter sent remove content req diff:\n%s\n", diff)
+	}
+}
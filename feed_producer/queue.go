@@ -0,0 +1,420 @@
+package feedproducer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+)
+
+// MaxFeedLength is the number of feed items a Queue keeps for ReadLatest.
+const MaxFeedLength = 1000
+
+var (
+	ErrUnknownContent = errors.New("content is not in the queue")
+	// ErrUnknownFeedID is returned when resuming after a feed item the
+	// Queue no longer has.
+	ErrUnknownFeedID = errors.New("feed item is not in the feed")
+)
+
+// Queue is the state behind a FeedProducer server: the content it promotes,
+// the announcements it carries, and the feed it has produced. A Queue opened
+// with a path saves itself to that file after every change.
+//
+// Content is released into the feed when it's first added, then again every
+// Wait until Until, for as long as it hasn't expired. Content with no Wait
+// is released once. QueueItem (or ImmediateRelease) releases content at the
+// next opportunity regardless of its schedule.
+//
+// Announcements don't follow a schedule. Each is released once, ahead of any
+// content due at the same time, and stays active until it's removed.
+//
+// Removing content or an announcement also takes its items out of the feed.
+// Readers resuming after a removed item carry on from the next item that's
+// still there.
+//
+// History is kept apart from the rest of the state, in a file next to the
+// queue's that events are appended to; see History.
+type Queue struct {
+	mu    sync.Mutex
+	path  string
+	state queueState
+	// history holds the latest events for each piece of content, and
+	// unsaved the events not yet appended to the history file.
+	history map[uuid.UUID][]*HistoryEvent
+	unsaved []*HistoryEvent
+	// released is closed, and replaced, whenever items are added to the feed.
+	released chan struct{}
+}
+
+type queueState struct {
+	Content       []*queueEntry  `json:"content"`
+	Announcements []*queueEntry  `json:"announcements"`
+	Feed          []*ContentItem `json:"feed"`
+	// History is only read, from queue files saved before history moved
+	// to its own file.
+	History []*HistoryEvent `json:"history,omitempty"`
+	// Removed lists the most recent feed items taken out of the feed, so
+	// readers can resume after them.
+	Removed []*removedFeedItem `json:"removed,omitempty"`
+}
+
+// removedFeedItem records where a feed item was before it was removed.
+type removedFeedItem struct {
+	FeedID string `json:"feed_id"`
+	// After is the ID of the item it followed, or "" if it was first.
+	After string `json:"after"`
+}
+
+type queueEntry struct {
+	Type       pb.ContentType `json:"type,omitempty"`
+	ID         uuid.UUID      `json:"id"`
+	Wait       time.Duration  `json:"wait,omitempty"`
+	Until      time.Time      `json:"until"`
+	Expires    time.Time      `json:"expires"`
+	Queued     bool           `json:"queued,omitempty"`
+	QueuedBy   string         `json:"queued_by,omitempty"`
+	AddedAt    time.Time      `json:"added_at"`
+	ReleasedAt time.Time      `json:"released_at"`
+}
+
+// OpenQueue loads the queue saved at path, or starts an empty one if the
+// file doesn't exist yet. An empty path keeps the queue in memory only.
+func OpenQueue(path string) (*Queue, error) {
+	q := &Queue{path: path, history: map[uuid.UUID][]*HistoryEvent{}, released: make(chan struct{})}
+	if path == "" {
+		return q, nil
+	}
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(b, &q.state); err != nil {
+			return nil, fmt.Errorf("queue %s: %w", path, err)
+		}
+	}
+	if err := q.loadHistory(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Add starts promoting content. Adding content already in the queue updates
+// its schedule without resetting when it was last released.
+func (q *Queue) Add(content *Content, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	event := &HistoryEvent{ContentID: content.ID, Type: pb.HistoryEventType_UPDATED, At: now, Trigger: "AddContent"}
+	e := findEntry(q.state.Content, content.ID)
+	if e == nil {
+		e = &queueEntry{ID: content.ID, AddedAt: now}
+		q.state.Content = append(q.state.Content, e)
+		event.Type = pb.HistoryEventType_ADDED
+	}
+	e.Type = content.Type
+	e.Wait = content.Wait
+	e.Until = content.Until
+	e.Expires = content.Expires
+	if content.ImmediateRelease && !e.Queued {
+		e.Queued, e.QueuedBy = true, "AddContent"
+	}
+	event.Detail = describeSchedule(e)
+	q.record(event)
+	return q.save()
+}
+
+// AddAnnouncement adds an announcement to go out at the next release.
+// Adding an announcement twice has no effect.
+func (q *Queue) AddAnnouncement(id uuid.UUID, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if findEntry(q.state.Announcements, id) != nil {
+		return nil
+	}
+	q.state.Announcements = append(q.state.Announcements, &queueEntry{ID: id, AddedAt: now})
+	q.record(&HistoryEvent{ContentID: id, Type: pb.HistoryEventType_ADDED, At: now, Trigger: "AddAnnouncement", Detail: "announcement"})
+	return q.save()
+}
+
+// Remove forgets the content or announcement with the given ID and takes
+// the items it released out of the feed.
+func (q *Queue) Remove(id uuid.UUID, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	content, removedContent := removeEntry(q.state.Content, id)
+	announcements, removedAnnouncement := removeEntry(q.state.Announcements, id)
+	q.state.Content, q.state.Announcements = content, announcements
+
+	feed := q.state.Feed[:0]
+	after, removedItems := "", 0
+	for _, item := range q.state.Feed {
+		if item.ContentID != id {
+			feed = append(feed, item)
+			after = item.FeedID
+			continue
+		}
+		q.state.Removed = append(q.state.Removed, &removedFeedItem{FeedID: item.FeedID, After: after})
+		removedItems++
+	}
+	q.state.Feed = feed
+	if extra := len(q.state.Removed) - MaxFeedLength; extra > 0 {
+		q.state.Removed = append([]*removedFeedItem(nil), q.state.Removed[extra:]...)
+	}
+
+	if !removedContent && !removedAnnouncement && removedItems == 0 {
+		return nil
+	}
+	event := &HistoryEvent{ContentID: id, Type: pb.HistoryEventType_REMOVED, At: now, Trigger: "RemoveContent"}
+	if removedItems > 0 {
+		event.Detail = "taken out of the feed"
+	}
+	q.record(event)
+	return q.save()
+}
+
+// Enqueue schedules content already in the queue for release at the next
+// opportunity.
+func (q *Queue) Enqueue(id uuid.UUID, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e := findEntry(q.state.Content, id)
+	if e == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownContent, id)
+	}
+	if !e.Queued {
+		e.Queued, e.QueuedBy = true, "QueueItem"
+	}
+	q.record(&HistoryEvent{ContentID: id, Type: pb.HistoryEventType_QUEUED, At: now, Trigger: "QueueItem"})
+	return q.save()
+}
+
+// Release adds everything due at now to the feed, announcements first, and
+// drops expired content. It returns the items it added.
+func (q *Queue) Release(now time.Time) ([]*ContentItem, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var released []*ContentItem
+	release := func(e *queueEntry, trigger, detail string) {
+		item := &ContentItem{FeedID: uuid.NewString(), ContentID: e.ID, AddedAt: now, ContentType: e.Type}
+		released = append(released, item)
+		event := &HistoryEvent{ContentID: e.ID, Type: pb.HistoryEventType_RE_RELEASED, At: now, Trigger: trigger, FeedID: item.FeedID, Detail: detail}
+		if e.ReleasedAt.IsZero() {
+			event.Type = pb.HistoryEventType_RELEASED
+		}
+		q.record(event)
+		e.ReleasedAt, e.Queued, e.QueuedBy = now, false, ""
+	}
+	for _, e := range q.state.Announcements {
+		if e.ReleasedAt.IsZero() {
+			release(e, "AddAnnouncement", "announcement")
+		}
+	}
+	content := q.state.Content[:0]
+	pruned := false
+	for _, e := range q.state.Content {
+		if e.expired(now) {
+			q.record(&HistoryEvent{ContentID: e.ID, Type: pb.HistoryEventType_EXPIRED, At: now, Trigger: TriggerSchedule,
+				Detail: "expired at " + e.Expires.Format(time.RFC3339)})
+			pruned = true
+			continue
+		}
+		if trigger, detail, ok := e.due(now); ok {
+			release(e, trigger, detail)
+		}
+		content = append(content, e)
+	}
+	q.state.Content = content
+
+	if len(released) == 0 && !pruned {
+		return nil, nil
+	}
+	q.state.Feed = append(q.state.Feed, released...)
+	if extra := len(q.state.Feed) - MaxFeedLength; extra > 0 {
+		q.state.Feed = append([]*ContentItem(nil), q.state.Feed[extra:]...)
+	}
+	if len(released) > 0 {
+		close(q.released)
+		q.released = make(chan struct{})
+	}
+	return released, q.save()
+}
+
+// LastFeedID returns the ID of the most recent feed item, or "" if the feed
+// is empty.
+func (q *Queue) LastFeedID() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.state.Feed) == 0 {
+		return ""
+	}
+	return q.state.Feed[len(q.state.Feed)-1].FeedID
+}
+
+// Since returns the feed items added after the one with the given ID, oldest
+// first, or the whole feed if the ID is "". If that item was removed, it
+// returns the items added after it that are still in the feed. The returned
+// channel is closed the next time items are added.
+func (q *Queue) Since(feedID string) ([]*ContentItem, <-chan struct{}, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	start, ok := q.resume(feedID)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownFeedID, feedID)
+	}
+	var items []*ContentItem
+	for _, item := range q.state.Feed[start:] {
+		copied := *item
+		items = append(items, &copied)
+	}
+	return items, q.released, nil
+}
+
+// resume returns the index of the first feed item after the one with the
+// given ID, following removed items back to the item they followed. The
+// caller must hold q.mu.
+func (q *Queue) resume(feedID string) (int, bool) {
+	// Each removed item is followed at most once.
+	for hops := 0; hops <= len(q.state.Removed); hops++ {
+		if feedID == "" {
+			return 0, true
+		}
+		for i, item := range q.state.Feed {
+			if item.FeedID == feedID {
+				return i + 1, true
+			}
+		}
+		found := false
+		for _, removed := range q.state.Removed {
+			if removed.FeedID == feedID {
+				feedID, found = removed.After, true
+				break
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// Latest returns up to count of the most recent feed items, newest first.
+func (q *Queue) Latest(count int) []*ContentItem {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var latest []*ContentItem
+	for i := len(q.state.Feed) - 1; i >= 0 && len(latest) < count; i-- {
+		item := *q.state.Feed[i]
+		latest = append(latest, &item)
+	}
+	return latest
+}
+
+// Active returns the IDs of announcements and content that have been
+// released and haven't expired, in the order they were added.
+func (q *Queue) Active(now time.Time) []uuid.UUID {
+	return q.ids(now, func(e *queueEntry) bool { return !e.ReleasedAt.IsZero() })
+}
+
+// All returns the IDs of every announcement and unexpired piece of content,
+// released or waiting, in the order they were added.
+func (q *Queue) All(now time.Time) []uuid.UUID {
+	return q.ids(now, func(*queueEntry) bool { return true })
+}
+
+func (q *Queue) ids(now time.Time, include func(*queueEntry) bool) []uuid.UUID {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var ids []uuid.UUID
+	for _, e := range q.state.Announcements {
+		if include(e) {
+			ids = append(ids, e.ID)
+		}
+	}
+	for _, e := range q.state.Content {
+		if !e.expired(now) && include(e) {
+			ids = append(ids, e.ID)
+		}
+	}
+	return ids
+}
+
+// save writes the queue to its file, via a temporary file so a crash never
+// leaves it half written. The caller must hold q.mu.
+func (q *Queue) save() error {
+	if q.path == "" {
+		return nil
+	}
+	if err := q.appendHistory(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(&q.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path)
+}
+
+func (e *queueEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// due reports whether the entry should be released at now, and if so what
+// triggered the release and why.
+func (e *queueEntry) due(now time.Time) (trigger, detail string, ok bool) {
+	switch {
+	case e.Queued:
+		trigger = e.QueuedBy
+		if trigger == "" {
+			trigger = "QueueItem"
+		}
+		return trigger, "queued for immediate release", true
+	case e.ReleasedAt.IsZero():
+		return "AddContent", "first release", true
+	case e.Wait <= 0:
+		return "", "", false
+	case !e.Until.IsZero() && now.After(e.Until):
+		return "", "", false
+	case now.Before(e.ReleasedAt.Add(e.Wait)):
+		return "", "", false
+	}
+	return TriggerSchedule, "wait of " + e.Wait.String() + " elapsed", true
+}
+
+func findEntry(entries []*queueEntry, id uuid.UUID) *queueEntry {
+	for _, e := range entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func removeEntry(entries []*queueEntry, id uuid.UUID) ([]*queueEntry, bool) {
+	for i, e := range entries {
+		if e.ID == id {
+			return append(entries[:i:i], entries[i+1:]...), true
+		}
+	}
+	return entries, false
+}
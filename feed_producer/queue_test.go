@@ -0,0 +1,198 @@
+package feedproducer
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+)
+
+func releasedIDs(items []*ContentItem) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, item := range items {
+		ids = append(ids, item.ContentID)
+	}
+	return ids
+}
+
+func TestQueueSchedule(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	once, repeat, expiring, announcement := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	q, err := OpenQueue("")
+	if err != nil {
+		t.Fatalf("OpenQueue() returned err: %v", err)
+	}
+	for _, c := range []*Content{
+		{Type: pb.ContentType_ARTICLE, ID: once},
+		{Type: pb.ContentType_ARTICLE, ID: repeat, Wait: time.Hour, Until: now.Add(90 * time.Minute)},
+		{Type: pb.ContentType_POINT_OF_INTEREST, ID: expiring, Wait: time.Hour, Expires: now.Add(30 * time.Minute)},
+	} {
+		if err := q.Add(c, now); err != nil {
+			t.Fatalf("Add() returned err: %v", err)
+		}
+	}
+	if err := q.AddAnnouncement(announcement, now); err != nil {
+		t.Fatalf("AddAnnouncement() returned err: %v", err)
+	}
+
+	for _, step := range []struct {
+		at   time.Time
+		want []uuid.UUID
+	}{
+		{now, []uuid.UUID{announcement, once, repeat, expiring}},
+		{now.Add(30 * time.Minute), nil},
+		{now.Add(time.Hour), []uuid.UUID{repeat}},
+		{now.Add(2 * time.Hour), nil},
+	} {
+		released, err := q.Release(step.at)
+		if err != nil {
+			t.Fatalf("Release(%s) returned err: %v", step.at, err)
+		}
+		if diff := cmp.Diff(step.want, releasedIDs(released)); diff != "" {
+			t.Errorf("Release(%s) diff:\n%s\n", step.at, diff)
+		}
+	}
+
+	if err := q.Enqueue(once, now.Add(3*time.Hour)); err != nil {
+		t.Fatalf("Enqueue() returned err: %v", err)
+	}
+	released, err := q.Release(now.Add(3 * time.Hour))
+	if err != nil {
+		t.Fatalf("Release() returned err: %v", err)
+	}
+	if diff := cmp.Diff([]uuid.UUID{once}, releasedIDs(released)); diff != "" {
+		t.Errorf("Release() after Enqueue() diff:\n%s\n", diff)
+	}
+	if err := q.Enqueue(expiring, now.Add(3*time.Hour)); err == nil {
+		t.Error("Enqueue() of expired content returned nil err")
+	}
+
+	wantLatest := []uuid.UUID{once, repeat, expiring}
+	if diff := cmp.Diff(wantLatest, releasedIDs(q.Latest(3))); diff != "" {
+		t.Errorf("Latest() diff:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]uuid.UUID{announcement, once, repeat}, q.All(now.Add(3*time.Hour))); diff != "" {
+		t.Errorf("All() diff:\n%s\n", diff)
+	}
+}
+
+func TestQueueAddUpdates(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	id := uuid.New()
+	q, _ := OpenQueue("")
+	if err := q.Add(&Content{ID: id, Wait: time.Hour}, now); err != nil {
+		t.Fatalf("Add() returned err: %v", err)
+	}
+	if _, err := q.Release(now); err != nil {
+		t.Fatalf("Release() returned err: %v", err)
+	}
+	// Updating the wait keeps the last release time, so the content is due
+	// sooner but isn't released again straight away.
+	if err := q.Add(&Content{ID: id, Wait: 10 * time.Minute}, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Add() returned err: %v", err)
+	}
+	for _, step := range []struct {
+		at   time.Time
+		want []uuid.UUID
+	}{
+		{now.Add(time.Minute), nil},
+		{now.Add(10 * time.Minute), []uuid.UUID{id}},
+	} {
+		released, err := q.Release(step.at)
+		if err != nil {
+			t.Fatalf("Release(%s) returned err: %v", step.at, err)
+		}
+		if diff := cmp.Diff(step.want, releasedIDs(released)); diff != "" {
+			t.Errorf("Release(%s) diff:\n%s\n", step.at, diff)
+		}
+	}
+	if diff := cmp.Diff([]uuid.UUID{id}, q.All(now)); diff != "" {
+		t.Errorf("All() diff:\n%s\n", diff)
+	}
+}
+
+func TestQueuePersists(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "queue.json")
+	waiting, released := uuid.New(), uuid.New()
+
+	q, err := OpenQueue(path)
+	if err != nil {
+		t.Fatalf("OpenQueue() returned err: %v", err)
+	}
+	if err := q.Add(&Content{ID: released}, now); err != nil {
+		t.Fatalf("Add() returned err: %v", err)
+	}
+	if _, err := q.Release(now); err != nil {
+		t.Fatalf("Release() returned err: %v", err)
+	}
+	if err := q.AddAnnouncement(waiting, now); err != nil {
+		t.Fatalf("AddAnnouncement() returned err: %v", err)
+	}
+
+	reopened, err := OpenQueue(path)
+	if err != nil {
+		t.Fatalf("OpenQueue() returned err: %v", err)
+	}
+	if diff := cmp.Diff([]uuid.UUID{released}, reopened.Active(now)); diff != "" {
+		t.Errorf("Active() after reopening diff:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]uuid.UUID{waiting, released}, reopened.All(now)); diff != "" {
+		t.Errorf("All() after reopening diff:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(q.Latest(10), reopened.Latest(10)); diff != "" {
+		t.Errorf("Latest() after reopening diff:\n%s\n", diff)
+	}
+}
+
+func TestQueueRemove(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	first, taken, last := uuid.New(), uuid.New(), uuid.New()
+	q, _ := OpenQueue("")
+	var feedIDs []string
+	for _, id := range []uuid.UUID{first, taken, last} {
+		if err := q.Add(&Content{ID: id}, now); err != nil {
+			t.Fatalf("Add() returned err: %v", err)
+		}
+		if _, err := q.Release(now); err != nil {
+			t.Fatalf("Release() returned err: %v", err)
+		}
+		feedIDs = append(feedIDs, q.LastFeedID())
+	}
+
+	if err := q.Remove(taken, now); err != nil {
+		t.Fatalf("Remove() returned err: %v", err)
+	}
+	if diff := cmp.Diff([]uuid.UUID{last, first}, releasedIDs(q.Latest(10))); diff != "" {
+		t.Errorf("Latest() after Remove() diff:\n%s\n", diff)
+	}
+	// Resuming after the removed item carries on from the item after it.
+	since, _, err := q.Since(feedIDs[1])
+	if err != nil {
+		t.Fatalf("Since() removed item returned err: %v", err)
+	}
+	if diff := cmp.Diff([]uuid.UUID{last}, releasedIDs(since)); diff != "" {
+		t.Errorf("Since() removed item diff:\n%s\n", diff)
+	}
+
+	// Removing the item it followed too leaves the whole feed to resume from.
+	if err := q.Remove(first, now); err != nil {
+		t.Fatalf("Remove() returned err: %v", err)
+	}
+	since, _, err = q.Since(feedIDs[1])
+	if err != nil {
+		t.Fatalf("Since() removed item returned err: %v", err)
+	}
+	if diff := cmp.Diff([]uuid.UUID{last}, releasedIDs(since)); diff != "" {
+		t.Errorf("Since() item after removed item diff:\n%s\n", diff)
+	}
+	if _, _, err := q.Since("no-such-item"); !errors.Is(err, ErrUnknownFeedID) {
+		t.Errorf("Since() unknown item returned %v, want ErrUnknownFeedID", err)
+	}
+}
@@ -0,0 +1,295 @@
+package feedproducer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+	graphpb "github.com/geomodulus/citygraph/pb"
+)
+
+// Server is a reference implementation of the FeedProducer service. It
+// schedules releases with a Queue and looks up vertex types in the graph.
+// It only accepts content whose type is mapped in ContentTypes and matches
+// the type of the content's vertex.
+type Server struct {
+	pb.UnimplementedFeedProducerServer
+
+	Graph citygraph.GraphClient
+	Queue *Queue
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+}
+
+func NewServer(graph citygraph.GraphClient, queue *Queue) *Server {
+	return &Server{Graph: graph, Queue: queue, Now: time.Now}
+}
+
+// Run releases due content every interval until ctx is done.
+func (s *Server) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Queue.Release(s.now()); err != nil {
+			log.Printf("feed producer: release: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) AddAnnouncement(ctx context.Context, req *pb.AddAnnouncementRequest) (*emptypb.Empty, error) {
+	id, err := parseID(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Queue.AddAnnouncement(id, s.now()); err != nil {
+		return nil, status.Errorf(codes.Internal, "add announcement %s: %v", id, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) AddContent(ctx context.Context, req *pb.AddContentRequest) (*emptypb.Empty, error) {
+	id, err := parseID(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ContentTypes.VertexType(req.ContentType); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "content %s: %v", id, err)
+	}
+	types, err := s.vertexTypes(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "look up vertex type: %v", err)
+	}
+	vertexType, ok := types[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "content %s: no such vertex", id)
+	}
+	if ct, err := ContentTypes.ContentType(vertexType); err != nil || ct != req.ContentType {
+		return nil, status.Errorf(codes.InvalidArgument, "content %s: vertex type %q is not %s content", id, vertexType, req.ContentType)
+	}
+	content := &Content{
+		Type:             req.ContentType,
+		ID:               id,
+		Wait:             req.Wait.AsDuration(),
+		ImmediateRelease: req.ImmediateRelease,
+	}
+	if req.Until != nil {
+		content.Until = req.Until.AsTime()
+	}
+	if req.Expires != nil {
+		content.Expires = req.Expires.AsTime()
+	}
+	if content.Wait < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "content %s: negative wait %s", id, content.Wait)
+	}
+	if err := s.Queue.Add(content, s.now()); err != nil {
+		return nil, status.Errorf(codes.Internal, "add content %s: %v", id, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) RemoveContent(ctx context.Context, req *pb.RemoveContentRequest) (*emptypb.Empty, error) {
+	id, err := parseID(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Queue.Remove(id, s.now()); err != nil {
+		return nil, status.Errorf(codes.Internal, "remove content %s: %v", id, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ReadLatest returns the latest items, or with a filter, the latest items
+// matching it among the MaxFeedLength the queue keeps.
+func (s *Server) ReadLatest(ctx context.Context, req *pb.ReadLatestRequest) (*pb.ReadLatestResponse, error) {
+	if req.Count < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "negative count %d", req.Count)
+	}
+	filter, err := newFeedFilter(req.Filter)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "filter: %v", err)
+	}
+	latest, err := s.latest(ctx, int(req.Count), filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "filter feed: %v", err)
+	}
+	resp := &pb.ReadLatestResponse{}
+	for _, item := range latest {
+		resp.Latest = append(resp.Latest, item.proto())
+	}
+	return resp, nil
+}
+
+// filterPageSize is the least number of feed items ReadLatest filters at
+// once, to keep graph requests down for small counts.
+const filterPageSize = 100
+
+func (s *Server) latest(ctx context.Context, count int, filter *FeedFilter) ([]*ContentItem, error) {
+	if filter.IsEmpty() {
+		return s.Queue.Latest(count), nil
+	}
+	pageSize := count
+	if pageSize < filterPageSize {
+		pageSize = filterPageSize
+	}
+	all := s.Queue.Latest(MaxFeedLength)
+	var latest []*ContentItem
+	for start := 0; start < len(all) && len(latest) < count; start += pageSize {
+		end := start + pageSize
+		if end > len(all) {
+			end = len(all)
+		}
+		matched, err := filter.Filter(ctx, s.Graph, all[start:end])
+		if err != nil {
+			return nil, err
+		}
+		latest = append(latest, matched...)
+	}
+	if len(latest) > count {
+		latest = latest[:count]
+	}
+	return latest, nil
+}
+
+func (s *Server) QueueItem(ctx context.Context, req *pb.QueueItemRequest) (*emptypb.Empty, error) {
+	id, err := parseID(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Queue.Enqueue(id, s.now()); errors.Is(err, ErrUnknownContent) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "queue item %s: %v", id, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// WatchFeed sends feed items as they're released until the client goes
+// away. A client resuming after a removed item carries on from the next item
+// still in the feed. One resuming after an item that has aged out of the
+// feed gets OutOfRange and should start over from ReadLatest. Announcements
+// are sent whatever content types the client asks for.
+func (s *Server) WatchFeed(req *pb.WatchFeedRequest, stream pb.FeedProducer_WatchFeedServer) error {
+	cursor := req.AfterFeedId
+	if cursor == "" {
+		cursor = s.Queue.LastFeedID()
+	}
+	for {
+		items, released, err := s.Queue.Since(cursor)
+		if errors.Is(err, ErrUnknownFeedID) {
+			return status.Error(codes.OutOfRange, err.Error())
+		} else if err != nil {
+			return err
+		}
+		for _, item := range items {
+			cursor = item.FeedID
+			if !matchesTypes(req.ContentTypes, item) {
+				continue
+			}
+			if err := stream.Send(item.proto()); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-released:
+		}
+	}
+}
+
+func (s *Server) ReadHistory(ctx context.Context, req *pb.ReadHistoryRequest) (*pb.ReadHistoryResponse, error) {
+	id, err := parseID(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	var since time.Time
+	if req.Since != nil {
+		since = req.Since.AsTime()
+	}
+	resp := &pb.ReadHistoryResponse{}
+	for _, event := range s.Queue.History(id, since) {
+		resp.Events = append(resp.Events, event.proto())
+	}
+	return resp, nil
+}
+
+func (s *Server) ListActiveReleases(_ *emptypb.Empty, stream pb.FeedProducer_ListActiveReleasesServer) error {
+	return s.sendReleases(stream, s.Queue.Active(s.now()))
+}
+
+func (s *Server) ListAllReleases(_ *emptypb.Empty, stream pb.FeedProducer_ListAllReleasesServer) error {
+	return s.sendReleases(stream, s.Queue.All(s.now()))
+}
+
+// sendReleases streams a ReleaseItem for each ID that's still a vertex in the
+// graph. Vertices deleted since their content was added are skipped.
+func (s *Server) sendReleases(stream interface {
+	Context() context.Context
+	Send(*pb.ReleaseItem) error
+}, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	types, err := s.vertexTypes(stream.Context(), ids)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "look up vertex types: %v", err)
+	}
+	for _, id := range ids {
+		t, ok := types[id]
+		if !ok {
+			continue
+		}
+		if err := stream.Send(&pb.ReleaseItem{ContentId: id.String(), VertexType: t}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) vertexTypes(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	query := make([]*graphpb.Uuid, 0, len(ids))
+	for _, id := range ids {
+		query = append(query, citygraph.UUID(id))
+	}
+	vertices, err := s.Graph.GetVertices(ctx, citygraph.NewSpecificVertexQuery(query...))
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[uuid.UUID]string, len(vertices))
+	for _, v := range vertices {
+		id, err := uuid.FromBytes(v.GetId().GetValue())
+		if err != nil {
+			return nil, err
+		}
+		types[id] = v.GetT().GetValue()
+	}
+	return types, nil
+}
+
+func (s *Server) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func parseID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "id %q: %v", id, err)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,179 @@
+package feedproducer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/feed_producer/pb"
+	"github.com/geomodulus/citygraph/graphtest"
+	graphpb "github.com/geomodulus/citygraph/pb"
+)
+
+func TestServer(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	article, place, deleted, waiting := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	fakeGraph := &graphtest.FakeGraphClient{
+		GetVerticesResps: [][]*graphpb.Vertex{
+			// Each vertex is looked up as it's added.
+			{{Id: citygraph.UUID(article), T: citygraph.ArticleType}},
+			{{Id: citygraph.UUID(place), T: &citygraph.PlaceType}},
+			{{Id: citygraph.UUID(deleted), T: citygraph.ArticleType}},
+			{{Id: citygraph.UUID(waiting), T: citygraph.ArticleType}},
+			// The deleted article is gone by the time releases are listed.
+			{
+				{Id: citygraph.UUID(article), T: citygraph.ArticleType},
+				{Id: citygraph.UUID(place), T: &citygraph.PlaceType},
+			},
+		},
+	}
+	queue, _ := OpenQueue("")
+	server := &Server{Graph: fakeGraph, Queue: queue, Now: func() time.Time { return now }}
+	client := newTestClient(t, server)
+	ctx := context.Background()
+
+	for _, c := range []*Content{
+		{Type: pb.ContentType_ARTICLE, ID: article},
+		{Type: pb.ContentType_POINT_OF_INTEREST, ID: place},
+		{Type: pb.ContentType_ARTICLE, ID: deleted},
+	} {
+		if err := client.AddContent(ctx, c); err != nil {
+			t.Fatalf("client.AddContent() returned err: %v", err)
+		}
+	}
+	if _, err := queue.Release(now); err != nil {
+		t.Fatalf("queue.Release() returned err: %v", err)
+	}
+	if err := client.AddContent(ctx, &Content{Type: pb.ContentType_ARTICLE, ID: waiting}); err != nil {
+		t.Fatalf("client.AddContent() returned err: %v", err)
+	}
+
+	got, err := client.ListActiveReleases(ctx)
+	if err != nil {
+		t.Fatalf("client.ListActiveReleases() returned err: %v", err)
+	}
+	want := []*Release{
+		{ContentID: article, VertexType: citygraph.ArticleType.Value, ContentType: pb.ContentType_ARTICLE},
+		{ContentID: place, VertexType: citygraph.PlaceType.Value, ContentType: pb.ContentType_POINT_OF_INTEREST},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("client.ListActiveReleases() diff:\n%s\n", diff)
+	}
+	wantQuery := []*graphpb.VertexQuery{citygraph.NewSpecificVertexQuery(
+		citygraph.UUID(article), citygraph.UUID(place), citygraph.UUID(deleted),
+	)}
+	if diff := cmp.Diff(wantQuery, fakeGraph.GetVerticesReqs[4:], protocmp.Transform()); diff != "" {
+		t.Errorf("server sent get vertices req diff:\n%s\n", diff)
+	}
+
+	latest, err := client.ReadLatest(ctx, 2)
+	if err != nil {
+		t.Fatalf("client.ReadLatest() returned err: %v", err)
+	}
+	if diff := cmp.Diff([]uuid.UUID{deleted, place}, releasedIDs(latest)); diff != "" {
+		t.Errorf("client.ReadLatest() diff:\n%s\n", diff)
+	}
+
+	if err := client.QueueItem(ctx, uuid.New()); status.Code(err) != codes.NotFound {
+		t.Errorf("client.QueueItem() of unknown content returned %v, want NotFound", err)
+	}
+	if _, err := server.AddContent(ctx, &pb.AddContentRequest{Id: "not-a-uuid"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("server.AddContent() with bad id returned %v, want InvalidArgument", err)
+	}
+	if err := client.AddContent(ctx, &Content{Type: pb.ContentType_SPORTS, ID: uuid.New()}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("client.AddContent() of unmapped content type returned %v, want InvalidArgument", err)
+	}
+}
+
+func TestServerAddContentChecksVertexType(t *testing.T) {
+	ctx := context.Background()
+	graph := graphtest.NewMemoryGraphClient()
+	place := uuid.New()
+	if err := graph.CreateVertex(ctx, citygraph.UUID(place), &citygraph.PlaceType); err != nil {
+		t.Fatal(err)
+	}
+	queue, _ := OpenQueue("")
+	client := newTestClient(t, NewServer(graph, queue))
+
+	if err := client.AddContent(ctx, &Content{Type: pb.ContentType_ARTICLE, ID: place}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("client.AddContent() of a place as ARTICLE returned %v, want InvalidArgument", err)
+	}
+	if err := client.AddContent(ctx, &Content{Type: pb.ContentType_ARTICLE, ID: uuid.New()}); status.Code(err) != codes.NotFound {
+		t.Errorf("client.AddContent() of missing content returned %v, want NotFound", err)
+	}
+	if err := client.AddContent(ctx, &Content{Type: pb.ContentType_POINT_OF_INTEREST, ID: place}); err != nil {
+		t.Errorf("client.AddContent() of a place as POINT_OF_INTEREST returned err: %v", err)
+	}
+	if diff := cmp.Diff([]uuid.UUID{place}, queue.All(time.Now())); diff != "" {
+		t.Errorf("queue content diff:\n%s\n", diff)
+	}
+}
+
+func TestServerWatchFeed(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	queue, _ := OpenQueue("")
+	server := &Server{Queue: queue, Now: func() time.Time { return now }}
+	client := newTestClient(t, server)
+	ctx := context.Background()
+
+	before, article, place, later, announcement := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	release := func(contents ...*Content) {
+		t.Helper()
+		for _, c := range contents {
+			if err := queue.Add(c, now); err != nil {
+				t.Fatalf("queue.Add() returned err: %v", err)
+			}
+		}
+		if _, err := queue.Release(now); err != nil {
+			t.Fatalf("queue.Release() returned err: %v", err)
+		}
+	}
+	release(&Content{Type: pb.ContentType_ARTICLE, ID: before})
+	resumeFrom := queue.LastFeedID()
+	if err := queue.AddAnnouncement(announcement, now); err != nil {
+		t.Fatalf("queue.AddAnnouncement() returned err: %v", err)
+	}
+	release(
+		&Content{Type: pb.ContentType_POINT_OF_INTEREST, ID: place},
+		&Content{Type: pb.ContentType_ARTICLE, ID: article},
+	)
+
+	// The watcher resumes after the first release, so it sees the second
+	// whenever it subscribes, then waits for the third. Announcements have no
+	// content type but pass the filter.
+	watchCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	var got []uuid.UUID
+	err := client.WatchFeed(watchCtx, resumeFrom, []pb.ContentType{pb.ContentType_ARTICLE}, func(item *ContentItem) error {
+		if item.ContentType != pb.ContentType_ARTICLE && !item.IsAnnouncement() {
+			t.Errorf("client.WatchFeed() sent %s item, want only ARTICLE and announcements", item.ContentType)
+		}
+		got = append(got, item.ContentID)
+		switch len(got) {
+		case 2:
+			release(&Content{Type: pb.ContentType_ARTICLE, ID: later})
+		case 3:
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("client.WatchFeed() returned %v, want context.Canceled", err)
+	}
+	if diff := cmp.Diff([]uuid.UUID{announcement, article, later}, got); diff != "" {
+		t.Errorf("client.WatchFeed() diff:\n%s\n", diff)
+	}
+
+	err = client.WatchFeed(ctx, "no-such-item", nil, func(*ContentItem) error { return nil })
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("client.WatchFeed() after unknown item returned %v, want OutOfRange", err)
+	}
+}
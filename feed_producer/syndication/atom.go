@@ -0,0 +1,93 @@
+package syndication
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName  xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang     string       `xml:"xml:lang,attr,omitempty"`
+	ID       string       `xml:"id"`
+	Title    string       `xml:"title"`
+	Subtitle string       `xml:"subtitle,omitempty"`
+	Updated  string       `xml:"updated"`
+	Author   *atomPerson  `xml:"author"`
+	Links    []*atomLink  `xml:"link"`
+	Entries  []*atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Updated   string        `xml:"updated"`
+	Published string        `xml:"published"`
+	Authors   []*atomPerson `xml:"author"`
+	Links     []*atomLink   `xml:"link"`
+	Summary   string        `xml:"summary,omitempty"`
+	Content   *atomContent  `xml:"content"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// WriteAtom writes the items as an Atom 1.0 document. Items without authors
+// are credited to Feed.Author, or to the feed's title if that's empty, since
+// Atom requires every entry to have an author.
+func WriteAtom(w io.Writer, feed *Feed, items []*Item) error {
+	author := feed.Author
+	if author == "" {
+		author = feed.Title
+	}
+	doc := &atomFeed{
+		Lang:     feed.Language,
+		ID:       feed.FeedURL,
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		Updated:  feed.updated(items).Format(time.RFC3339),
+		Author:   &atomPerson{Name: author},
+	}
+	if doc.ID == "" {
+		doc.ID = feed.Link
+	}
+	if feed.Link != "" {
+		doc.Links = append(doc.Links, &atomLink{Href: feed.Link, Rel: "alternate", Type: "text/html"})
+	}
+	if feed.FeedURL != "" {
+		doc.Links = append(doc.Links, &atomLink{Href: feed.FeedURL, Rel: "self", Type: "application/atom+xml"})
+	}
+	for _, item := range items {
+		entry := &atomEntry{
+			ID:        item.GUID(),
+			Title:     item.Title,
+			Updated:   item.Updated.Format(time.RFC3339),
+			Published: item.Published.Format(time.RFC3339),
+			Summary:   item.Summary,
+			Content:   &atomContent{Type: "html", Body: string(item.Content)},
+		}
+		for _, a := range item.Authors {
+			entry.Authors = append(entry.Authors, &atomPerson{Name: a})
+		}
+		if item.Link != "" {
+			entry.Links = append(entry.Links, &atomLink{Href: item.Link, Rel: "alternate", Type: "text/html"})
+		}
+		if item.ImageURL != "" {
+			entry.Links = append(entry.Links, &atomLink{Href: item.ImageURL, Rel: "enclosure", Type: imageType(item.ImageURL)})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return writeXML(w, doc)
+}
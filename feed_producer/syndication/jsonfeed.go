@@ -0,0 +1,74 @@
+package syndication
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// JSONFeedVersion identifies the JSON Feed version WriteJSONFeed writes.
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+type jsonFeed struct {
+	Version     string            `json:"version"`
+	Title       string            `json:"title"`
+	HomePageURL string            `json:"home_page_url,omitempty"`
+	FeedURL     string            `json:"feed_url,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Language    string            `json:"language,omitempty"`
+	Authors     []*jsonFeedAuthor `json:"authors,omitempty"`
+	Items       []*jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string            `json:"id"`
+	URL           string            `json:"url,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	ContentHTML   string            `json:"content_html"`
+	Summary       string            `json:"summary,omitempty"`
+	Image         string            `json:"image,omitempty"`
+	DatePublished string            `json:"date_published"`
+	DateModified  string            `json:"date_modified,omitempty"`
+	Authors       []*jsonFeedAuthor `json:"authors,omitempty"`
+}
+
+// WriteJSONFeed writes the items as a JSON Feed 1.1 document.
+func WriteJSONFeed(w io.Writer, feed *Feed, items []*Item) error {
+	doc := &jsonFeed{
+		Version:     JSONFeedVersion,
+		Title:       feed.Title,
+		HomePageURL: feed.Link,
+		FeedURL:     feed.FeedURL,
+		Description: feed.Description,
+		Language:    feed.Language,
+		Items:       []*jsonFeedItem{},
+	}
+	if feed.Author != "" {
+		doc.Authors = []*jsonFeedAuthor{{Name: feed.Author}}
+	}
+	for _, item := range items {
+		entry := &jsonFeedItem{
+			ID:            item.GUID(),
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentHTML:   string(item.Content),
+			Summary:       item.Summary,
+			Image:         item.ImageURL,
+			DatePublished: item.Published.Format(time.RFC3339),
+		}
+		if item.Updated.After(item.Published) {
+			entry.DateModified = item.Updated.Format(time.RFC3339)
+		}
+		for _, a := range item.Authors {
+			entry.Authors = append(entry.Authors, &jsonFeedAuthor{Name: a})
+		}
+		doc.Items = append(doc.Items, entry)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,126 @@
+package syndication
+
+import (
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"time"
+)
+
+type rssDoc struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	MediaNS string     `xml:"xmlns:media,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	Language      string     `xml:"language,omitempty"`
+	LastBuildDate string     `xml:"lastBuildDate,omitempty"`
+	Self          *atomLink  `xml:"atom:link,omitempty"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link,omitempty"`
+	Description rssCDATA      `xml:"description"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Creators    []string      `xml:"dc:creator"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+	Media       *rssMedia     `xml:"media:content"`
+}
+
+type rssCDATA struct {
+	Text string `xml:",cdata"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL string `xml:"url,attr"`
+	// Length is required by RSS 2.0 but unknown for graph images, so it's
+	// always 0 as the RSS Advisory Board recommends.
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssMedia struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Medium string `xml:"medium,attr"`
+}
+
+// WriteRSS writes the items as an RSS 2.0 document. Item images are attached
+// both as enclosures and as Media RSS content.
+func WriteRSS(w io.Writer, feed *Feed, items []*Item) error {
+	doc := &rssDoc{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		MediaNS: "http://search.yahoo.com/mrss/",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       feed.Title,
+			Link:        feed.Link,
+			Description: feed.Description,
+			Language:    feed.Language,
+		},
+	}
+	if updated := feed.updated(items); !updated.IsZero() {
+		doc.Channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+	if feed.FeedURL != "" {
+		doc.Channel.Self = &atomLink{Href: feed.FeedURL, Rel: "self", Type: "application/rss+xml"}
+	}
+	for _, item := range items {
+		entry := &rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: rssCDATA{string(item.Content)},
+			GUID:        rssGUID{Value: item.GUID()},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			Creators:    item.Authors,
+		}
+		if item.ImageURL != "" {
+			t := imageType(item.ImageURL)
+			entry.Enclosure = &rssEnclosure{URL: item.ImageURL, Type: t}
+			entry.Media = &rssMedia{URL: item.ImageURL, Type: t, Medium: "image"}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, entry)
+	}
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// imageType guesses an image's MIME type from its URL, defaulting to JPEG.
+func imageType(imageURL string) string {
+	if u, err := url.Parse(imageURL); err == nil {
+		if t := mime.TypeByExtension(path.Ext(u.Path)); t != "" {
+			return t
+		}
+	}
+	return "image/jpeg"
+}
@@ -0,0 +1,248 @@
+// Package syndication renders the live feed as RSS 2.0, Atom 1.0 and JSON
+// Feed 1.1 documents for readers' feed apps.
+package syndication
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph"
+	feedproducer "github.com/geomodulus/citygraph/feed_producer"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+// Feed describes the feed as a whole.
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the absolute URL of the site the feed belongs to.
+	Link string
+	// FeedURL is the absolute URL the feed document is served from.
+	FeedURL  string
+	Language string
+	// Author is credited for items with no authors of their own.
+	Author string
+	// Updated is when the feed last changed. If it's zero, the writers use
+	// the time the most recent item was added.
+	Updated time.Time
+}
+
+// Item is a feed entry joined with its content's graph properties.
+type Item struct {
+	FeedID     string
+	ContentID  uuid.UUID
+	VertexType string
+	AddedAt    time.Time
+
+	Title    string
+	Summary  string
+	ImageURL string
+	Authors  []string
+	Address  string
+	// Source is the publication a web link points to.
+	Source string
+	// Link is the content's canonical URL.
+	Link      string
+	Published time.Time
+	Updated   time.Time
+	// Content is the item body, rendered by the template for VertexType.
+	Content template.HTML
+}
+
+// GUID identifies the item's content across feed documents.
+func (i *Item) GUID() string {
+	return "urn:uuid:" + i.ContentID.String()
+}
+
+// DefaultTemplates render item bodies for articles, points of interest,
+// newswire content and web links. Other vertex types use FallbackTemplate.
+var DefaultTemplates = map[string]*template.Template{
+	citygraph.ArticleType.Value:      articleTemplate,
+	citygraph.PlaceType.Value:        placeTemplate,
+	citygraph.NewsWireArticle.Value:  bulletinTemplate,
+	citygraph.NewsWireBulletin.Value: bulletinTemplate,
+	citygraph.WebLinkType.Value:      webLinkTemplate,
+}
+
+var (
+	articleTemplate = template.Must(template.New("article").Parse(
+		`{{if .ImageURL}}<p><img src="{{.ImageURL}}" alt=""></p>{{end}}` +
+			`{{if .Summary}}<p>{{.Summary}}</p>{{end}}` +
+			`{{if .Authors}}<p>By {{range $i, $a := .Authors}}{{if $i}}, {{end}}{{$a}}{{end}}</p>{{end}}` +
+			`{{if .Link}}<p><a href="{{.Link}}">Read the full story</a></p>{{end}}`))
+	placeTemplate = template.Must(template.New("place").Parse(
+		`{{if .ImageURL}}<p><img src="{{.ImageURL}}" alt=""></p>{{end}}` +
+			`{{if .Summary}}<p>{{.Summary}}</p>{{end}}` +
+			`{{if .Address}}<p>{{.Address}}</p>{{end}}` +
+			`{{if .Link}}<p><a href="{{.Link}}">See it on the map</a></p>{{end}}`))
+	bulletinTemplate = template.Must(template.New("bulletin").Parse(
+		`{{if .Summary}}<p>{{.Summary}}</p>{{end}}` +
+			`{{if .Authors}}<p>Via {{range $i, $a := .Authors}}{{if $i}}, {{end}}{{$a}}{{end}}</p>{{end}}`))
+	webLinkTemplate = template.Must(template.New("web-link").Parse(
+		`{{if .ImageURL}}<p><img src="{{.ImageURL}}" alt=""></p>{{end}}` +
+			`{{if .Summary}}<p>{{.Summary}}</p>{{end}}` +
+			`{{if .Link}}<p><a href="{{.Link}}">Read it{{if .Source}} at {{.Source}}{{end}}</a></p>{{end}}`))
+	// FallbackTemplate renders items whose vertex type has no template.
+	FallbackTemplate = template.Must(template.New("fallback").Parse(
+		`{{if .Summary}}<p>{{.Summary}}</p>{{end}}`))
+)
+
+// Source is the part of feedproducer.FeedClient a Renderer reads from.
+type Source interface {
+	ReadLatest(ctx context.Context, count int) ([]*feedproducer.ContentItem, error)
+	ReadFiltered(ctx context.Context, count int, filter *feedproducer.FeedFilter) ([]*feedproducer.ContentItem, error)
+}
+
+// Renderer builds feed items from the live feed and the graph.
+type Renderer struct {
+	Feed  Source
+	Graph citygraph.GraphClient
+	// URLs builds links for content that has a canonical_path but no
+	// canonical_url. It may be nil.
+	URLs *citygraph.URLBuilder
+	// Templates render item bodies by vertex type. It defaults to
+	// DefaultTemplates.
+	Templates map[string]*template.Template
+	// Filter, if set, renders a local or topical feed instead of the whole
+	// feed.
+	Filter *feedproducer.FeedFilter
+}
+
+func NewRenderer(feed Source, graph citygraph.GraphClient) *Renderer {
+	return &Renderer{Feed: feed, Graph: graph, Templates: DefaultTemplates}
+}
+
+// Items returns up to count of the latest feed items, newest first. Content
+// that appears in the feed more than once is only listed at its latest
+// appearance, and content whose vertex is gone is left out.
+func (r *Renderer) Items(ctx context.Context, count int) ([]*Item, error) {
+	var latest []*feedproducer.ContentItem
+	var err error
+	if r.Filter.IsEmpty() {
+		latest, err = r.Feed.ReadLatest(ctx, count)
+	} else {
+		latest, err = r.Feed.ReadFiltered(ctx, count, r.Filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	seen := map[uuid.UUID]bool{}
+	var entries []*feedproducer.ContentItem
+	var ids []*pb.Uuid
+	for _, entry := range latest {
+		if seen[entry.ContentID] {
+			continue
+		}
+		seen[entry.ContentID] = true
+		entries = append(entries, entry)
+		ids = append(ids, citygraph.UUID(entry.ContentID))
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	all, err := r.Graph.GetAllVertexProperties(ctx, citygraph.NewSpecificVertexQuery(ids...))
+	if err != nil {
+		return nil, err
+	}
+	vertices := make(map[uuid.UUID]*pb.VertexProperties, len(all))
+	for _, v := range all {
+		id, err := uuid.FromBytes(v.GetVertex().GetId().GetValue())
+		if err != nil {
+			return nil, err
+		}
+		vertices[id] = v
+	}
+
+	var items []*Item
+	for _, entry := range entries {
+		v, ok := vertices[entry.ContentID]
+		if !ok {
+			continue
+		}
+		item, err := r.item(entry, v)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (r *Renderer) item(entry *feedproducer.ContentItem, v *pb.VertexProperties) (*Item, error) {
+	item := &Item{
+		FeedID:     entry.FeedID,
+		ContentID:  entry.ContentID,
+		VertexType: v.GetVertex().GetT().GetValue(),
+		AddedAt:    entry.AddedAt,
+	}
+	var published, updated citygraph.Date
+	var canonicalPath string
+	fields := map[string]interface{}{
+		citygraph.PropertyNameDisplayName:   &item.Title,
+		"h2":                                &item.Summary,
+		citygraph.PropertyNameImgURL:        &item.ImageURL,
+		"creators":                          &item.Authors,
+		"street_address":                    &item.Address,
+		"source":                            &item.Source,
+		"published_on":                      &published,
+		citygraph.PropertyNameUpdatedAt:     &updated,
+		citygraph.PropertyNameCanonicalURL:  &item.Link,
+		citygraph.PropertyNameCanonicalPath: &canonicalPath,
+	}
+	for _, prop := range v.GetProps() {
+		field, ok := fields[prop.GetName().GetValue()]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal([]byte(prop.GetValue().GetValue()), field); err != nil {
+			return nil, fmt.Errorf("content %s: %s: %w", entry.ContentID, prop.GetName().GetValue(), err)
+		}
+	}
+	if item.Link == "" && canonicalPath != "" && r.URLs != nil {
+		item.Link = r.URLs.URL(canonicalPath)
+	}
+	item.Published = published.Time
+	if item.Published.IsZero() {
+		item.Published = entry.AddedAt
+	}
+	item.Updated = updated.Time
+	if item.Updated.Before(item.Published) {
+		item.Updated = item.Published
+	}
+
+	templates := r.Templates
+	if templates == nil {
+		templates = DefaultTemplates
+	}
+	tmpl, ok := templates[item.VertexType]
+	if !ok {
+		tmpl = FallbackTemplate
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, item); err != nil {
+		return nil, fmt.Errorf("content %s: render %s: %w", entry.ContentID, tmpl.Name(), err)
+	}
+	item.Content = template.HTML(b.String())
+	return item, nil
+}
+
+// updated returns when the feed last changed.
+func (f *Feed) updated(items []*Item) time.Time {
+	updated := f.Updated
+	if !updated.IsZero() {
+		return updated
+	}
+	for _, item := range items {
+		if item.AddedAt.After(updated) {
+			updated = item.AddedAt
+		}
+	}
+	return updated
+}
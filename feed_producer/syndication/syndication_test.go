@@ -0,0 +1,262 @@
+package syndication
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/geomodulus/citygraph"
+	feedproducer "github.com/geomodulus/citygraph/feed_producer"
+	fpb "github.com/geomodulus/citygraph/feed_producer/pb"
+	"github.com/geomodulus/citygraph/graphtest"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+func prop(name string, value interface{}) *pb.NamedProperty {
+	b, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return &pb.NamedProperty{Name: &pb.Identifier{Value: name}, Value: &pb.Json{Value: string(b)}}
+}
+
+func testItems(t *testing.T) []*Item {
+	t.Helper()
+	addedAt := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	article, place, gone := uuid.New(), uuid.New(), uuid.New()
+	feed := &feedproducer.FakeFeedClient{
+		ReadLatestResps: [][]*feedproducer.ContentItem{{
+			{FeedID: "3", ContentID: article, AddedAt: addedAt.Add(2 * time.Hour)},
+			{FeedID: "2", ContentID: place, AddedAt: addedAt.Add(time.Hour)},
+			{FeedID: "1", ContentID: article, AddedAt: addedAt},
+			{FeedID: "0", ContentID: gone, AddedAt: addedAt},
+		}},
+	}
+	graph := &graphtest.FakeGraphClient{
+		GetAllVertexPropertiesResps: [][]*pb.VertexProperties{{{
+			Vertex: &pb.Vertex{Id: citygraph.UUID(article), T: citygraph.ArticleType},
+			Props: []*pb.NamedProperty{
+				prop("display_name", "Big news & more"),
+				prop("h2", "The summary"),
+				prop("img_url", "https://some.url/image.png"),
+				prop("creators", []string{"Jane Doe", "John Roe"}),
+				prop("published_on", "2023-02-28T09:00:00-05:00"),
+				prop("canonical_url", "https://torontoverse.com/articles/abc/big-news"),
+			},
+		}, {
+			Vertex: &pb.Vertex{Id: citygraph.UUID(place), T: &citygraph.PlaceType},
+			Props: []*pb.NamedProperty{
+				prop("display_name", "Some Cafe"),
+				prop("street_address", "123 Sesame St W"),
+				prop("canonical_path", "/places/some-cafe"),
+			},
+		}}},
+	}
+	r := NewRenderer(feed, graph)
+	r.URLs, _ = citygraph.NewURLBuilder("https://torontoverse.com")
+	items, err := r.Items(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("Items() returned err: %v", err)
+	}
+	wantQuery := []*pb.VertexQuery{citygraph.NewSpecificVertexQuery(
+		citygraph.UUID(article), citygraph.UUID(place), citygraph.UUID(gone),
+	)}
+	if diff := cmp.Diff(wantQuery, graph.GetAllVertexPropertiesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("Items() sent get all vertex properties req diff:\n%s\n", diff)
+	}
+	return items
+}
+
+func TestItems(t *testing.T) {
+	items := testItems(t)
+	published := time.Date(2023, 2, 28, 14, 0, 0, 0, time.UTC)
+	want := []*Item{{
+		FeedID:     "3",
+		VertexType: citygraph.ArticleType.Value,
+		AddedAt:    time.Date(2023, 3, 1, 14, 0, 0, 0, time.UTC),
+		Title:      "Big news & more",
+		Summary:    "The summary",
+		ImageURL:   "https://some.url/image.png",
+		Authors:    []string{"Jane Doe", "John Roe"},
+		Link:       "https://torontoverse.com/articles/abc/big-news",
+		Published:  published,
+		Updated:    published,
+		Content: `<p><img src="https://some.url/image.png" alt=""></p><p>The summary</p>` +
+			`<p>By Jane Doe, John Roe</p><p><a href="https://torontoverse.com/articles/abc/big-news">Read the full story</a></p>`,
+	}, {
+		FeedID:     "2",
+		VertexType: citygraph.PlaceType.Value,
+		AddedAt:    time.Date(2023, 3, 1, 13, 0, 0, 0, time.UTC),
+		Title:      "Some Cafe",
+		Address:    "123 Sesame St W",
+		Link:       "https://torontoverse.com/places/some-cafe",
+		Published:  time.Date(2023, 3, 1, 13, 0, 0, 0, time.UTC),
+		Updated:    time.Date(2023, 3, 1, 13, 0, 0, 0, time.UTC),
+		Content:    `<p>123 Sesame St W</p><p><a href="https://torontoverse.com/places/some-cafe">See it on the map</a></p>`,
+	}}
+	opts := cmp.Options{
+		cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) }),
+		cmp.FilterPath(func(p cmp.Path) bool { return p.Last().String() == ".ContentID" }, cmp.Ignore()),
+	}
+	if diff := cmp.Diff(want, items, opts); diff != "" {
+		t.Errorf("Items() diff:\n%s\n", diff)
+	}
+}
+
+var testFeed = &Feed{
+	Title:       "Torontoverse",
+	Description: "The latest from Toronto",
+	Link:        "https://torontoverse.com/",
+	FeedURL:     "https://torontoverse.com/feed",
+	Language:    "en-CA",
+}
+
+func TestWriteRSS(t *testing.T) {
+	items := testItems(t)
+	var b bytes.Buffer
+	if err := WriteRSS(&b, testFeed, items); err != nil {
+		t.Fatalf("WriteRSS() returned err: %v", err)
+	}
+	var doc struct {
+		Channel struct {
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Title       string `xml:"title"`
+				Description string `xml:"description"`
+				GUID        string `xml:"guid"`
+				PubDate     string `xml:"pubDate"`
+				Enclosure   struct {
+					URL  string `xml:"url,attr"`
+					Type string `xml:"type,attr"`
+				} `xml:"enclosure"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("WriteRSS() wrote invalid XML: %v\n%s", err, b.String())
+	}
+	if got, want := doc.Channel.LastBuildDate, "Wed, 01 Mar 2023 14:00:00 +0000"; got != want {
+		t.Errorf("WriteRSS() lastBuildDate = %q, want %q", got, want)
+	}
+	if got := len(doc.Channel.Items); got != 2 {
+		t.Fatalf("WriteRSS() wrote %d items, want 2", got)
+	}
+	first := doc.Channel.Items[0]
+	if first.Title != "Big news & more" || first.Description != string(items[0].Content) || first.GUID != items[0].GUID() {
+		t.Errorf("WriteRSS() wrote first item %+v", first)
+	}
+	if first.PubDate != "Tue, 28 Feb 2023 09:00:00 -0500" {
+		t.Errorf("WriteRSS() first item pubDate = %q", first.PubDate)
+	}
+	if first.Enclosure.URL != "https://some.url/image.png" || first.Enclosure.Type != "image/png" {
+		t.Errorf("WriteRSS() first item enclosure = %+v", first.Enclosure)
+	}
+	for _, want := range []string{
+		`<media:content url="https://some.url/image.png" type="image/png" medium="image"></media:content>`,
+		`<dc:creator>Jane Doe</dc:creator>`,
+		`<atom:link href="https://torontoverse.com/feed" rel="self" type="application/rss+xml"></atom:link>`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("WriteRSS() output missing %s:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	items := testItems(t)
+	var b bytes.Buffer
+	if err := WriteAtom(&b, testFeed, items); err != nil {
+		t.Fatalf("WriteAtom() returned err: %v", err)
+	}
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Author  string   `xml:"author>name"`
+		Entries []struct {
+			ID      string   `xml:"id"`
+			Authors []string `xml:"author>name"`
+			Content string   `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("WriteAtom() wrote invalid XML: %v\n%s", err, b.String())
+	}
+	if doc.ID != testFeed.FeedURL || doc.Updated != "2023-03-01T14:00:00Z" || doc.Author != "Torontoverse" {
+		t.Errorf("WriteAtom() wrote feed id %q, updated %q, author %q", doc.ID, doc.Updated, doc.Author)
+	}
+	if got := len(doc.Entries); got != 2 {
+		t.Fatalf("WriteAtom() wrote %d entries, want 2", got)
+	}
+	if diff := cmp.Diff([]string{"Jane Doe", "John Roe"}, doc.Entries[0].Authors); diff != "" {
+		t.Errorf("WriteAtom() first entry authors diff:\n%s\n", diff)
+	}
+	if doc.Entries[1].Content != string(items[1].Content) {
+		t.Errorf("WriteAtom() second entry content = %q, want %q", doc.Entries[1].Content, items[1].Content)
+	}
+}
+
+func TestWriteJSONFeed(t *testing.T) {
+	items := testItems(t)
+	var b bytes.Buffer
+	if err := WriteJSONFeed(&b, testFeed, items); err != nil {
+		t.Fatalf("WriteJSONFeed() returned err: %v", err)
+	}
+	var got jsonFeed
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSONFeed() wrote invalid JSON: %v", err)
+	}
+	want := jsonFeed{
+		Version:     JSONFeedVersion,
+		Title:       "Torontoverse",
+		HomePageURL: "https://torontoverse.com/",
+		FeedURL:     "https://torontoverse.com/feed",
+		Description: "The latest from Toronto",
+		Language:    "en-CA",
+		Items: []*jsonFeedItem{{
+			ID:            items[0].GUID(),
+			URL:           "https://torontoverse.com/articles/abc/big-news",
+			Title:         "Big news & more",
+			ContentHTML:   string(items[0].Content),
+			Summary:       "The summary",
+			Image:         "https://some.url/image.png",
+			DatePublished: "2023-02-28T09:00:00-05:00",
+			Authors:       []*jsonFeedAuthor{{Name: "Jane Doe"}, {Name: "John Roe"}},
+		}, {
+			ID:            items[1].GUID(),
+			URL:           "https://torontoverse.com/places/some-cafe",
+			Title:         "Some Cafe",
+			ContentHTML:   string(items[1].Content),
+			DatePublished: "2023-03-01T13:00:00Z",
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("WriteJSONFeed() diff:\n%s\n", diff)
+	}
+}
+
+func TestItemsFiltered(t *testing.T) {
+	area := uuid.New()
+	feed := &feedproducer.FakeFeedClient{ReadFilteredResps: [][]*feedproducer.ContentItem{{}}}
+	r := NewRenderer(feed, &graphtest.FakeGraphClient{})
+	r.Filter = &feedproducer.FeedFilter{AreaIDs: []uuid.UUID{area}}
+	items, err := r.Items(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("Items() returned err: %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("Items() returned %d items, want 0", len(items))
+	}
+	want := []*fpb.ReadLatestRequest{{Count: 10, Filter: &fpb.FeedFilter{AreaIds: []string{area.String()}}}}
+	if diff := cmp.Diff(want, feed.ReadFilteredReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("Items() sent read filtered req diff:\n%s\n", diff)
+	}
+}
@@ -0,0 +1,128 @@
+package geojson
+
+import "math"
+
+// bounds accumulates the extent of the positions it's given.
+type bounds struct {
+	west, south, east, north float64
+	empty                    bool
+}
+
+func newBounds() *bounds {
+	return &bounds{
+		west: math.Inf(1), south: math.Inf(1),
+		east: math.Inf(-1), north: math.Inf(-1),
+		empty: true,
+	}
+}
+
+func (b *bounds) add(pos Position) {
+	if len(pos) < 2 {
+		return
+	}
+	b.west, b.east = math.Min(b.west, pos[0]), math.Max(b.east, pos[0])
+	b.south, b.north = math.Min(b.south, pos[1]), math.Max(b.north, pos[1])
+	b.empty = false
+}
+
+func (b *bounds) addGeometry(g *Geometry) {
+	if g == nil {
+		return
+	}
+	var rings [][]Position
+	switch g.Type {
+	case TypePoint:
+		b.add(g.Point)
+	case TypeMultiPoint:
+		rings = [][]Position{g.MultiPoint}
+	case TypeLineString:
+		rings = [][]Position{g.LineString}
+	case TypeMultiLineString:
+		rings = g.MultiLineString
+	case TypePolygon:
+		rings = g.Polygon
+	case TypeMultiPolygon:
+		for _, poly := range g.MultiPolygon {
+			rings = append(rings, poly...)
+		}
+	case TypeGeometryCollection:
+		for _, child := range g.Geometries {
+			b.addGeometry(child)
+		}
+	}
+	for _, ring := range rings {
+		for _, pos := range ring {
+			b.add(pos)
+		}
+	}
+}
+
+func (b *bounds) bbox() BBox {
+	if b.empty {
+		return nil
+	}
+	return BBox{b.west, b.south, b.east, b.north}
+}
+
+func (g *Geometry) Bounds() BBox {
+	b := newBounds()
+	b.addGeometry(g)
+	return b.bbox()
+}
+
+func (f *Feature) Bounds() BBox {
+	b := newBounds()
+	b.addGeometry(f.Geometry)
+	return b.bbox()
+}
+
+func (fc *FeatureCollection) Bounds() BBox {
+	b := newBounds()
+	for _, f := range fc.Features {
+		if f != nil {
+			b.addGeometry(f.Geometry)
+		}
+	}
+	return b.bbox()
+}
+
+// rewind reverses polygon rings wound against the right-hand rule.
+func rewind(g *Geometry) {
+	if g == nil {
+		return
+	}
+	switch g.Type {
+	case TypePolygon:
+		rewindPolygon(g.Polygon)
+	case TypeMultiPolygon:
+		for _, poly := range g.MultiPolygon {
+			rewindPolygon(poly)
+		}
+	case TypeGeometryCollection:
+		for _, child := range g.Geometries {
+			rewind(child)
+		}
+	}
+}
+
+func rewindPolygon(rings [][]Position) {
+	for i, ring := range rings {
+		if area := wound(ring); area != 0 && (area > 0) != (i == 0) {
+			for l, r := 0, len(ring)-1; l < r; l, r = l+1, r-1 {
+				ring[l], ring[r] = ring[r], ring[l]
+			}
+		}
+	}
+}
+
+func (g *Geometry) Rewind() { rewind(g) }
+
+func (f *Feature) Rewind() { rewind(f.Geometry) }
+
+func (fc *FeatureCollection) Rewind() {
+	for _, f := range fc.Features {
+		if f != nil {
+			rewind(f.Geometry)
+		}
+	}
+}
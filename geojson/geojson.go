@@ -0,0 +1,318 @@
+// Package geojson provides typed GeoJSON models following RFC 7946, with
+// validation and bounding box computation.
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// GeoJSON object types.
+const (
+	TypePoint              = "Point"
+	TypeMultiPoint         = "MultiPoint"
+	TypeLineString         = "LineString"
+	TypeMultiLineString    = "MultiLineString"
+	TypePolygon            = "Polygon"
+	TypeMultiPolygon       = "MultiPolygon"
+	TypeGeometryCollection = "GeometryCollection"
+	TypeFeature            = "Feature"
+	TypeFeatureCollection  = "FeatureCollection"
+)
+
+// Object is a decoded GeoJSON object: a *Geometry, *Feature or
+// *FeatureCollection.
+type Object interface {
+	// Validate checks the object against RFC 7946, returning every problem
+	// found joined into one error.
+	Validate() error
+	// Bounds returns the 2D bounding box of every position in the object,
+	// or nil if it has none.
+	Bounds() BBox
+	// Rewind reverses any polygon rings wound against the right-hand rule:
+	// exterior rings counterclockwise, holes clockwise.
+	Rewind()
+}
+
+// Position is a longitude, latitude and optional altitude.
+type Position []float64
+
+// BBox is a bounding box: the west, south, east and north edges, with the
+// minimum and maximum altitude after south and north for 3D boxes.
+type BBox []float64
+
+// Geometry is any GeoJSON geometry. Only the coordinates field matching Type
+// is used.
+type Geometry struct {
+	Type            string
+	Point           Position
+	MultiPoint      []Position
+	LineString      []Position
+	MultiLineString [][]Position
+	Polygon         [][]Position
+	MultiPolygon    [][][]Position
+	Geometries      []*Geometry
+	BBox            BBox
+}
+
+// Feature is a GeoJSON feature. ID, if set, is a string or number.
+type Feature struct {
+	ID         interface{}            `json:"id,omitempty"`
+	Type       string                 `json:"type"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+	BBox       BBox                   `json:"bbox,omitempty"`
+	// Extra holds foreign members, such as vendor extensions, so they
+	// survive a round trip.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+var featureMembers = []string{"id", "type", "geometry", "properties", "bbox"}
+
+// NewFeature returns a feature with the given geometry and no properties.
+func NewFeature(geometry *Geometry) *Feature {
+	return &Feature{Type: TypeFeature, Geometry: geometry}
+}
+
+// FeatureCollection is a GeoJSON feature collection.
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+	BBox     BBox       `json:"bbox,omitempty"`
+	// Extra holds foreign members, such as an id or vendor extensions, so
+	// they survive a round trip.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+var featureCollectionMembers = []string{"type", "features", "bbox"}
+
+// NewFeatureCollection returns a collection of the given features.
+func NewFeatureCollection(features ...*Feature) *FeatureCollection {
+	if features == nil {
+		features = []*Feature{}
+	}
+	return &FeatureCollection{Type: TypeFeatureCollection, Features: features}
+}
+
+// Decode decodes any GeoJSON object.
+func Decode(b []byte) (Object, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(b, &head); err != nil {
+		return nil, err
+	}
+	var obj Object
+	switch head.Type {
+	case TypeFeature:
+		obj = &Feature{}
+	case TypeFeatureCollection:
+		obj = &FeatureCollection{}
+	case TypePoint, TypeMultiPoint, TypeLineString, TypeMultiLineString, TypePolygon, TypeMultiPolygon, TypeGeometryCollection:
+		obj = &Geometry{}
+	case "":
+		return nil, errors.New("geojson: missing type")
+	default:
+		return nil, fmt.Errorf("geojson: unknown type %q", head.Type)
+	}
+	if err := json.Unmarshal(b, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// FromValue returns v as a GeoJSON object. v may already be an Object,
+// encoded JSON, or any value that encodes to GeoJSON such as a
+// map[string]interface{}.
+func FromValue(v interface{}) (Object, error) {
+	switch v := v.(type) {
+	case Object:
+		return v, nil
+	case json.RawMessage:
+		return Decode(v)
+	case []byte:
+		return Decode(v)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(b)
+}
+
+// coordinates returns the field holding g's coordinates, or nil for a
+// geometry collection or unknown type.
+func (g *Geometry) coordinates() interface{} {
+	switch g.Type {
+	case TypePoint:
+		return &g.Point
+	case TypeMultiPoint:
+		return &g.MultiPoint
+	case TypeLineString:
+		return &g.LineString
+	case TypeMultiLineString:
+		return &g.MultiLineString
+	case TypePolygon:
+		return &g.Polygon
+	case TypeMultiPolygon:
+		return &g.MultiPolygon
+	}
+	return nil
+}
+
+func (g Geometry) MarshalJSON() ([]byte, error) {
+	fields := map[string]interface{}{"type": g.Type}
+	if g.Type == TypeGeometryCollection {
+		geometries := g.Geometries
+		if geometries == nil {
+			geometries = []*Geometry{}
+		}
+		fields["geometries"] = geometries
+	} else if coords := g.coordinates(); coords != nil {
+		fields["coordinates"] = coords
+	} else {
+		return nil, fmt.Errorf("geojson: unknown geometry type %q", g.Type)
+	}
+	if g.BBox != nil {
+		fields["bbox"] = g.BBox
+	}
+	return json.Marshal(fields)
+}
+
+func (g *Geometry) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+		Geometries  []*Geometry     `json:"geometries"`
+		BBox        BBox            `json:"bbox"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*g = Geometry{Type: raw.Type, Geometries: raw.Geometries, BBox: raw.BBox}
+	if g.Type == TypeGeometryCollection {
+		if g.Geometries == nil {
+			return errors.New("geojson: GeometryCollection missing geometries")
+		}
+		return nil
+	}
+	coords := g.coordinates()
+	if coords == nil {
+		return fmt.Errorf("geojson: unknown geometry type %q", g.Type)
+	}
+	if len(raw.Coordinates) == 0 {
+		return fmt.Errorf("geojson: %s missing coordinates", g.Type)
+	}
+	if err := json.Unmarshal(raw.Coordinates, coords); err != nil {
+		return fmt.Errorf("geojson: %s coordinates: %w", g.Type, err)
+	}
+	return nil
+}
+
+func (f Feature) MarshalJSON() ([]byte, error) {
+	type feature Feature
+	b, err := json.Marshal(feature(f))
+	if err != nil {
+		return nil, err
+	}
+	return withForeignMembers(b, f.Extra, featureMembers)
+}
+
+func (f *Feature) UnmarshalJSON(b []byte) error {
+	type feature Feature
+	var decoded feature
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	extra, err := foreignMembers(b, featureMembers)
+	if err != nil {
+		return err
+	}
+	*f = Feature(decoded)
+	f.Extra = extra
+	return nil
+}
+
+func (fc FeatureCollection) MarshalJSON() ([]byte, error) {
+	type featureCollection FeatureCollection
+	b, err := json.Marshal(featureCollection(fc))
+	if err != nil {
+		return nil, err
+	}
+	return withForeignMembers(b, fc.Extra, featureCollectionMembers)
+}
+
+func (fc *FeatureCollection) UnmarshalJSON(b []byte) error {
+	type featureCollection FeatureCollection
+	var decoded featureCollection
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	extra, err := foreignMembers(b, featureCollectionMembers)
+	if err != nil {
+		return err
+	}
+	*fc = FeatureCollection(decoded)
+	fc.Extra = extra
+	return nil
+}
+
+// foreignMembers returns the members of the JSON object b that aren't among
+// known, or nil if there are none.
+func foreignMembers(b []byte, known []string) (map[string]json.RawMessage, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+	for _, k := range known {
+		delete(members, k)
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members, nil
+}
+
+// withForeignMembers adds the members of extra that aren't among known to
+// the end of the encoded JSON object b, in name order.
+func withForeignMembers(b []byte, extra map[string]json.RawMessage, known []string) ([]byte, error) {
+	names := make([]string, 0, len(extra))
+	for k := range extra {
+		if !contains(known, k) {
+			names = append(names, k)
+		}
+	}
+	if len(names) == 0 {
+		return b, nil
+	}
+	sort.Strings(names)
+	out := bytes.NewBuffer(b[:len(b)-1])
+	for _, k := range names {
+		name, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(extra[k])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteByte(',')
+		out.Write(name)
+		out.WriteByte(':')
+		out.Write(value)
+	}
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package geojson
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeRoundTrip(t *testing.T) {
+	in := `{"type":"FeatureCollection","features":[` +
+		`{"id":"stop-1","type":"Feature","geometry":{"coordinates":[-79.38,43.65],"type":"Point"},"properties":{"name":"Union"}},` +
+		`{"type":"Feature","geometry":{"geometries":[{"coordinates":[[-79.4,43.6],[-79.3,43.7]],"type":"LineString"}],"type":"GeometryCollection"},"properties":null}` +
+		`]}`
+	obj, err := Decode([]byte(in))
+	if err != nil {
+		t.Fatalf("Decode() returned err: %v", err)
+	}
+	fc, ok := obj.(*FeatureCollection)
+	if !ok {
+		t.Fatalf("Decode() = %T, want *FeatureCollection", obj)
+	}
+	if err := fc.Validate(); err != nil {
+		t.Errorf("Validate() returned err: %v", err)
+	}
+	if diff := cmp.Diff(BBox{-79.4, 43.6, -79.3, 43.7}, fc.Bounds()); diff != "" {
+		t.Errorf("Bounds() diff:\n%s\n", diff)
+	}
+	out, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned err: %v", err)
+	}
+	if string(out) != in {
+		t.Errorf("json.Marshal() = %s, want %s", out, in)
+	}
+}
+
+func TestForeignMembersRoundTrip(t *testing.T) {
+	in := `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":{"coordinates":[-79.38,43.65],"type":"Point"},"properties":{},"title":"Union","x-vendor":{"layer":2}}` +
+		`],"id":"stops","x-source":"ttc"}`
+	obj, err := Decode([]byte(in))
+	if err != nil {
+		t.Fatalf("Decode() returned err: %v", err)
+	}
+	fc := obj.(*FeatureCollection)
+	if diff := cmp.Diff(map[string]json.RawMessage{"id": json.RawMessage(`"stops"`), "x-source": json.RawMessage(`"ttc"`)}, fc.Extra); diff != "" {
+		t.Errorf("FeatureCollection.Extra diff:\n%s\n", diff)
+	}
+	out, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned err: %v", err)
+	}
+	if string(out) != in {
+		t.Errorf("json.Marshal() = %s, want %s", out, in)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name, in, want string
+	}{
+		{"range", `{"type":"Point","coordinates":[200,43]}`, "longitude 200 is out of range"},
+		{"short line", `{"type":"LineString","coordinates":[[0,0]]}`, "want at least 2"},
+		{"unclosed", `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`, "ring is not closed"},
+		{"clockwise", `{"type":"Polygon","coordinates":[[[0,0],[0,1],[1,1],[1,0],[0,0]]]}`, "exterior ring is clockwise"},
+		{"hole", `{"type":"Polygon","coordinates":[[[0,0],[3,0],[3,3],[0,3],[0,0]],[[1,1],[2,1],[2,2],[1,2],[1,1]]]}`, "hole is counterclockwise"},
+		{"features", `{"type":"FeatureCollection"}`, "features: is required"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			obj, err := Decode([]byte(tc.in))
+			if err != nil {
+				t.Fatalf("Decode() returned err: %v", err)
+			}
+			if err := obj.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRewind(t *testing.T) {
+	obj, err := FromValue(map[string]interface{}{
+		"type": "Polygon",
+		"coordinates": [][][]float64{
+			{{0, 0}, {0, 3}, {3, 3}, {3, 0}, {0, 0}},
+			{{1, 1}, {2, 1}, {2, 2}, {1, 2}, {1, 1}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromValue() returned err: %v", err)
+	}
+	obj.Rewind()
+	if err := obj.Validate(); err != nil {
+		t.Errorf("Validate() after Rewind() returned err: %v", err)
+	}
+}
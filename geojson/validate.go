@@ -0,0 +1,174 @@
+package geojson
+
+import (
+	"errors"
+	"fmt"
+)
+
+// problems collects validation errors, each prefixed with the path to the
+// offending member.
+type problems []error
+
+func (p *problems) addf(path, format string, args ...interface{}) {
+	*p = append(*p, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+func (p *problems) position(path string, pos Position) {
+	if len(pos) < 2 || len(pos) > 3 {
+		p.addf(path, "position has %d values, want 2 or 3", len(pos))
+		return
+	}
+	if pos[0] < -180 || pos[0] > 180 {
+		p.addf(path, "longitude %g is out of range [-180, 180]", pos[0])
+	}
+	if pos[1] < -90 || pos[1] > 90 {
+		p.addf(path, "latitude %g is out of range [-90, 90]", pos[1])
+	}
+}
+
+func (p *problems) positions(path string, positions []Position) {
+	for i, pos := range positions {
+		p.position(fmt.Sprintf("%s[%d]", path, i), pos)
+	}
+}
+
+func (p *problems) lineString(path string, line []Position) {
+	if len(line) < 2 {
+		p.addf(path, "line has %d positions, want at least 2", len(line))
+	}
+	p.positions(path, line)
+}
+
+func (p *problems) polygon(path string, rings [][]Position) {
+	for i, ring := range rings {
+		ringPath := fmt.Sprintf("%s[%d]", path, i)
+		p.positions(ringPath, ring)
+		if len(ring) < 4 {
+			p.addf(ringPath, "ring has %d positions, want at least 4", len(ring))
+			continue
+		}
+		if !samePosition(ring[0], ring[len(ring)-1]) {
+			p.addf(ringPath, "ring is not closed")
+			continue
+		}
+		if wantCCW := i == 0; wound(ring) != 0 && (wound(ring) > 0) != wantCCW {
+			if wantCCW {
+				p.addf(ringPath, "exterior ring is clockwise, want counterclockwise")
+			} else {
+				p.addf(ringPath, "hole is counterclockwise, want clockwise")
+			}
+		}
+	}
+}
+
+func (p *problems) bbox(path string, bbox BBox) {
+	if bbox != nil && len(bbox) != 4 && len(bbox) != 6 {
+		p.addf(path, "bbox has %d values, want 4 or 6", len(bbox))
+	}
+}
+
+func (p *problems) geometry(path string, g *Geometry) {
+	switch g.Type {
+	case TypePoint:
+		p.position(path+".coordinates", g.Point)
+	case TypeMultiPoint:
+		p.positions(path+".coordinates", g.MultiPoint)
+	case TypeLineString:
+		p.lineString(path+".coordinates", g.LineString)
+	case TypeMultiLineString:
+		for i, line := range g.MultiLineString {
+			p.lineString(fmt.Sprintf("%s.coordinates[%d]", path, i), line)
+		}
+	case TypePolygon:
+		p.polygon(path+".coordinates", g.Polygon)
+	case TypeMultiPolygon:
+		for i, poly := range g.MultiPolygon {
+			p.polygon(fmt.Sprintf("%s.coordinates[%d]", path, i), poly)
+		}
+	case TypeGeometryCollection:
+		for i, child := range g.Geometries {
+			childPath := fmt.Sprintf("%s.geometries[%d]", path, i)
+			if child == nil {
+				p.addf(childPath, "geometry is null")
+				continue
+			}
+			p.geometry(childPath, child)
+		}
+	default:
+		p.addf(path+".type", "unknown geometry type %q", g.Type)
+	}
+	p.bbox(path+".bbox", g.BBox)
+}
+
+func (p *problems) feature(path string, f *Feature) {
+	if f.Type != TypeFeature {
+		p.addf(path+".type", "type is %q, want %q", f.Type, TypeFeature)
+	}
+	switch f.ID.(type) {
+	case nil, string, float64, int, int64:
+	default:
+		p.addf(path+".id", "id must be a string or number")
+	}
+	if f.Geometry != nil {
+		p.geometry(path+".geometry", f.Geometry)
+	}
+	p.bbox(path+".bbox", f.BBox)
+}
+
+func (g *Geometry) Validate() error {
+	var p problems
+	p.geometry("geometry", g)
+	return errors.Join(p...)
+}
+
+func (f *Feature) Validate() error {
+	var p problems
+	p.feature("feature", f)
+	return errors.Join(p...)
+}
+
+func (fc *FeatureCollection) Validate() error {
+	var p problems
+	if fc.Type != TypeFeatureCollection {
+		p.addf("type", "type is %q, want %q", fc.Type, TypeFeatureCollection)
+	}
+	if fc.Features == nil {
+		p.addf("features", "is required")
+	}
+	for i, f := range fc.Features {
+		path := fmt.Sprintf("features[%d]", i)
+		if f == nil {
+			p.addf(path, "feature is null")
+			continue
+		}
+		p.feature(path, f)
+	}
+	p.bbox("bbox", fc.BBox)
+	return errors.Join(p...)
+}
+
+func samePosition(a, b Position) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// wound returns twice the signed area of a closed ring: positive when it is
+// wound counterclockwise, negative when clockwise.
+func wound(ring []Position) float64 {
+	var area float64
+	for i := 0; i+1 < len(ring); i++ {
+		a, b := ring[i], ring[i+1]
+		if len(a) < 2 || len(b) < 2 {
+			return 0
+		}
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+	return area
+}
@@ -1,6 +1,8 @@
 package citygraph
 
 import (
+	"encoding/json"
+
 	"github.com/google/uuid"
 
 	"github.com/geomodulus/citygraph/pb"
@@ -23,6 +25,9 @@ type GeoJSONDataset struct {
 	Render  string    `json:"render,omitempty"`
 	Source  *Source   `json:"source,omitempty"`
 	Sources []*Source `json:"sources,omitempty"`
+	// Data holds the dataset's GeoJSON when it was loaded from a bundle
+	// rather than fetched from URL.
+	Data json.RawMessage `json:"-"`
 }
 
 func (d *GeoJSONDataset) UUID() (uuid.UUID, error) {
@@ -17,9 +17,11 @@ type FakeGraphClient struct {
 	CreateVertexFromTypeResps   []*pb.Uuid
 	GetVerticesReqs             []*pb.VertexQuery
 	GetVerticesResps            [][]*pb.Vertex
+	DeleteVerticesReqs          []*pb.VertexQuery
 	GetVertexPropertiesReqs     []*pb.VertexPropertyQuery
 	GetVertexPropertiesResps    [][]*pb.VertexProperty
 	SetVertexPropertiesReqs     []*pb.SetVertexPropertiesRequest
+	DeleteVertexPropertiesReqs  []*pb.VertexPropertyQuery
 	GetAllVertexPropertiesReqs  []*pb.VertexQuery
 	GetAllVertexPropertiesResps [][]*pb.VertexProperties
 
@@ -68,7 +70,11 @@ func (f *FakeGraphClient) GetVertices(ctx context.Context, query *pb.VertexQuery
 	return vtxs, nil
 }
 
-func (c *FakeGraphClient) DeleteVertices(ctx context.Context, query *pb.VertexQuery) error {
+func (f *FakeGraphClient) DeleteVertices(ctx context.Context, query *pb.VertexQuery) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.DeleteVerticesReqs = append(f.DeleteVerticesReqs, query)
 	return nil
 }
 
@@ -97,6 +103,14 @@ func (f *FakeGraphClient) SetVertexProperties(ctx context.Context, query *pb.Ver
 	return nil
 }
 
+func (f *FakeGraphClient) DeleteVertexProperties(ctx context.Context, query *pb.VertexQuery, name string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.DeleteVertexPropertiesReqs = append(f.DeleteVertexPropertiesReqs, &pb.VertexPropertyQuery{Inner: query, Name: &pb.Identifier{Value: name}})
+	return nil
+}
+
 func (f *FakeGraphClient) GetAllVertexProperties(ctx context.Context, query *pb.VertexQuery) ([]*pb.VertexProperties, error) {
 	f.Lock()
 	defer f.Unlock()
@@ -0,0 +1,443 @@
+package graphtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/geomodulus/citygraph"
+	"github.com/geomodulus/citygraph/pb"
+)
+
+// MemoryGraphClient is a GraphClient that keeps the graph in memory, for tests
+// that read back what they write. It supports specific, range and pipe
+// queries, and behaves like IndraDB where it matters to callers: creating a
+// vertex that exists or an edge between missing vertices does nothing,
+// deleting a vertex deletes its edges, and ranges are ordered by ID. A query
+// limit of 0 means no limit.
+type MemoryGraphClient struct {
+	mu       sync.Mutex
+	vertices map[uuid.UUID]*memoryVertex
+	edges    map[memoryEdgeKey]*memoryEdge
+}
+
+type memoryVertex struct {
+	t     string
+	props map[string]string
+}
+
+type memoryEdgeKey struct {
+	out uuid.UUID
+	t   string
+	in  uuid.UUID
+}
+
+type memoryEdge struct {
+	created time.Time
+	props   map[string]string
+}
+
+func NewMemoryGraphClient() *MemoryGraphClient {
+	return &MemoryGraphClient{
+		vertices: map[uuid.UUID]*memoryVertex{},
+		edges:    map[memoryEdgeKey]*memoryEdge{},
+	}
+}
+
+func toUUID(id *pb.Uuid) (uuid.UUID, error) {
+	return uuid.FromBytes(id.GetValue())
+}
+
+func (m *MemoryGraphClient) CreateVertex(ctx context.Context, id *pb.Uuid, t *pb.Identifier) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, err := toUUID(id)
+	if err != nil {
+		return err
+	}
+	if _, ok := m.vertices[u]; !ok {
+		m.vertices[u] = &memoryVertex{t: t.GetValue(), props: map[string]string{}}
+	}
+	return nil
+}
+
+func (m *MemoryGraphClient) CreateVertexFromType(ctx context.Context, t *pb.Identifier) (*pb.Uuid, error) {
+	id := citygraph.UUID(citygraph.NewID())
+	return id, m.CreateVertex(ctx, id, t)
+}
+
+func (m *MemoryGraphClient) GetVertices(ctx context.Context, query *pb.VertexQuery) ([]*pb.Vertex, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids, err := m.vertexIDs(query)
+	if err != nil {
+		return nil, err
+	}
+	var vertices []*pb.Vertex
+	for _, id := range ids {
+		vertices = append(vertices, m.vertex(id))
+	}
+	return vertices, nil
+}
+
+func (m *MemoryGraphClient) DeleteVertices(ctx context.Context, query *pb.VertexQuery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids, err := m.vertexIDs(query)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		delete(m.vertices, id)
+		for key := range m.edges {
+			if key.out == id || key.in == id {
+				delete(m.edges, key)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MemoryGraphClient) GetVertexProperties(ctx context.Context, query *pb.VertexQuery, name string) ([]*pb.VertexProperty, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids, err := m.vertexIDs(query)
+	if err != nil {
+		return nil, err
+	}
+	var props []*pb.VertexProperty
+	for _, id := range ids {
+		if value, ok := m.vertices[id].props[name]; ok {
+			props = append(props, &pb.VertexProperty{Id: citygraph.UUID(id), Value: &pb.Json{Value: value}})
+		}
+	}
+	return props, nil
+}
+
+func (m *MemoryGraphClient) SetVertexProperties(ctx context.Context, query *pb.VertexQuery, name string, jsonValue interface{}) error {
+	b, err := json.Marshal(jsonValue)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids, err := m.vertexIDs(query)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		m.vertices[id].props[name] = string(b)
+	}
+	return nil
+}
+
+func (m *MemoryGraphClient) DeleteVertexProperties(ctx context.Context, query *pb.VertexQuery, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids, err := m.vertexIDs(query)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		delete(m.vertices[id].props, name)
+	}
+	return nil
+}
+
+func (m *MemoryGraphClient) GetAllVertexProperties(ctx context.Context, query *pb.VertexQuery) ([]*pb.VertexProperties, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids, err := m.vertexIDs(query)
+	if err != nil {
+		return nil, err
+	}
+	var all []*pb.VertexProperties
+	for _, id := range ids {
+		all = append(all, &pb.VertexProperties{Vertex: m.vertex(id), Props: namedProps(m.vertices[id].props)})
+	}
+	return all, nil
+}
+
+func (m *MemoryGraphClient) CreateEdge(ctx context.Context, outbound *pb.Uuid, t *pb.Identifier, inbound *pb.Uuid) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, err := edgeKey(&pb.EdgeKey{OutboundId: outbound, T: t, InboundId: inbound})
+	if err != nil {
+		return err
+	}
+	_, outOK := m.vertices[key.out]
+	_, inOK := m.vertices[key.in]
+	if _, ok := m.edges[key]; ok || !outOK || !inOK {
+		return nil
+	}
+	m.edges[key] = &memoryEdge{created: time.Now(), props: map[string]string{}}
+	return nil
+}
+
+func (m *MemoryGraphClient) DeleteEdges(ctx context.Context, query *pb.EdgeQuery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys, err := m.edgeKeys(query)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		delete(m.edges, key)
+	}
+	return nil
+}
+
+func (m *MemoryGraphClient) GetEdges(ctx context.Context, query *pb.EdgeQuery) ([]*pb.Edge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys, err := m.edgeKeys(query)
+	if err != nil {
+		return nil, err
+	}
+	var edges []*pb.Edge
+	for _, key := range keys {
+		edges = append(edges, m.edge(key))
+	}
+	return edges, nil
+}
+
+func (m *MemoryGraphClient) GetEdgeProperties(ctx context.Context, query *pb.EdgeQuery, name string) ([]*pb.EdgeProperty, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys, err := m.edgeKeys(query)
+	if err != nil {
+		return nil, err
+	}
+	var props []*pb.EdgeProperty
+	for _, key := range keys {
+		if value, ok := m.edges[key].props[name]; ok {
+			props = append(props, &pb.EdgeProperty{Key: m.edge(key).Key, Value: &pb.Json{Value: value}})
+		}
+	}
+	return props, nil
+}
+
+func (m *MemoryGraphClient) SetEdgeProperties(ctx context.Context, query *pb.EdgeQuery, name string, jsonValue interface{}) error {
+	b, err := json.Marshal(jsonValue)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys, err := m.edgeKeys(query)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		m.edges[key].props[name] = string(b)
+	}
+	return nil
+}
+
+func (m *MemoryGraphClient) GetAllEdgeProperties(ctx context.Context, query *pb.EdgeQuery) ([]*pb.EdgeProperties, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys, err := m.edgeKeys(query)
+	if err != nil {
+		return nil, err
+	}
+	var all []*pb.EdgeProperties
+	for _, key := range keys {
+		all = append(all, &pb.EdgeProperties{Edge: m.edge(key), Props: namedProps(m.edges[key].props)})
+	}
+	return all, nil
+}
+
+func (m *MemoryGraphClient) NewBulkSender(ctx context.Context) (citygraph.BulkSender, error) {
+	return nil, errors.New("graphtest: MemoryGraphClient doesn't support bulk inserts")
+}
+
+func (m *MemoryGraphClient) vertex(id uuid.UUID) *pb.Vertex {
+	return &pb.Vertex{Id: citygraph.UUID(id), T: &pb.Identifier{Value: m.vertices[id].t}}
+}
+
+func (m *MemoryGraphClient) edge(key memoryEdgeKey) *pb.Edge {
+	return &pb.Edge{
+		Key:             &pb.EdgeKey{OutboundId: citygraph.UUID(key.out), T: &pb.Identifier{Value: key.t}, InboundId: citygraph.UUID(key.in)},
+		CreatedDatetime: timestamppb.New(m.edges[key].created),
+	}
+}
+
+// vertexIDs returns the IDs of the existing vertices matching query.
+func (m *MemoryGraphClient) vertexIDs(query *pb.VertexQuery) ([]uuid.UUID, error) {
+	switch q := query.GetQuery().(type) {
+	case *pb.VertexQuery_Specific:
+		var ids []uuid.UUID
+		for _, id := range q.Specific.GetIds() {
+			u, err := toUUID(id)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := m.vertices[u]; ok {
+				ids = append(ids, u)
+			}
+		}
+		return ids, nil
+	case *pb.VertexQuery_Range:
+		r := q.Range
+		var start uuid.UUID
+		if r.GetStartId() != nil {
+			u, err := toUUID(r.GetStartId())
+			if err != nil {
+				return nil, err
+			}
+			start = u
+		}
+		var ids []uuid.UUID
+		for id, v := range m.vertices {
+			if bytes.Compare(id[:], start[:]) >= 0 && (r.GetT() == nil || v.t == r.GetT().GetValue()) {
+				ids = append(ids, id)
+			}
+		}
+		sortIDs(ids)
+		return limitIDs(ids, r.GetLimit()), nil
+	case *pb.VertexQuery_Pipe:
+		p := q.Pipe
+		keys, err := m.edgeKeys(p.GetInner())
+		if err != nil {
+			return nil, err
+		}
+		seen := map[uuid.UUID]bool{}
+		var ids []uuid.UUID
+		for _, key := range keys {
+			id := key.in
+			if p.GetDirection() == pb.EdgeDirection_OUTBOUND {
+				id = key.out
+			}
+			v, ok := m.vertices[id]
+			if seen[id] || !ok || (p.GetT() != nil && v.t != p.GetT().GetValue()) {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		return limitIDs(ids, p.GetLimit()), nil
+	default:
+		return nil, fmt.Errorf("graphtest: unsupported vertex query %T", q)
+	}
+}
+
+// edgeKeys returns the keys of the existing edges matching query.
+func (m *MemoryGraphClient) edgeKeys(query *pb.EdgeQuery) ([]memoryEdgeKey, error) {
+	switch q := query.GetQuery().(type) {
+	case *pb.EdgeQuery_Specific:
+		var keys []memoryEdgeKey
+		for _, k := range q.Specific.GetKeys() {
+			key, err := edgeKey(k)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := m.edges[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+		return keys, nil
+	case *pb.EdgeQuery_Pipe:
+		p := q.Pipe
+		ids, err := m.vertexIDs(p.GetInner())
+		if err != nil {
+			return nil, err
+		}
+		from := map[uuid.UUID]bool{}
+		for _, id := range ids {
+			from[id] = true
+		}
+		var keys []memoryEdgeKey
+		for key, e := range m.edges {
+			end := key.in
+			if p.GetDirection() == pb.EdgeDirection_OUTBOUND {
+				end = key.out
+			}
+			switch {
+			case !from[end]:
+			case p.GetT() != nil && key.t != p.GetT().GetValue():
+			case p.GetLow() != nil && e.created.Before(p.GetLow().AsTime()):
+			case p.GetHigh() != nil && e.created.After(p.GetHigh().AsTime()):
+			default:
+				keys = append(keys, key)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := keys[i], keys[j]
+			if c := bytes.Compare(a.out[:], b.out[:]); c != 0 {
+				return c < 0
+			}
+			if a.t != b.t {
+				return a.t < b.t
+			}
+			return bytes.Compare(a.in[:], b.in[:]) < 0
+		})
+		return limitKeys(keys, p.GetLimit()), nil
+	default:
+		return nil, fmt.Errorf("graphtest: unsupported edge query %T", q)
+	}
+}
+
+func edgeKey(k *pb.EdgeKey) (memoryEdgeKey, error) {
+	out, err := toUUID(k.GetOutboundId())
+	if err != nil {
+		return memoryEdgeKey{}, err
+	}
+	in, err := toUUID(k.GetInboundId())
+	if err != nil {
+		return memoryEdgeKey{}, err
+	}
+	return memoryEdgeKey{out: out, t: k.GetT().GetValue(), in: in}, nil
+}
+
+func namedProps(props map[string]string) []*pb.NamedProperty {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	named := make([]*pb.NamedProperty, 0, len(names))
+	for _, name := range names {
+		named = append(named, &pb.NamedProperty{Name: &pb.Identifier{Value: name}, Value: &pb.Json{Value: props[name]}})
+	}
+	return named
+}
+
+func sortIDs(ids []uuid.UUID) {
+	sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+}
+
+func limitIDs(ids []uuid.UUID, n uint32) []uuid.UUID {
+	if n > 0 && uint32(len(ids)) > n {
+		return ids[:n]
+	}
+	return ids
+}
+
+func limitKeys(keys []memoryEdgeKey, n uint32) []memoryEdgeKey {
+	if n > 0 && uint32(len(keys)) > n {
+		return keys[:n]
+	}
+	return keys
+}
@@ -0,0 +1,277 @@
+package citygraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JSONSchema is the subset of JSON Schema used to describe property values:
+// type, enum, format, minLength, minimum, maximum, items, minItems,
+// properties, required and additionalProperties. Formats are uri, uuid, date
+// and date-time, and don't apply to empty strings, which the Store writes for
+// unset values.
+type JSONSchema struct {
+	Type                 schemaTypes            `json:"type,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+}
+
+// schemaTypes is a JSON Schema type keyword, either a single type name or a
+// list of them.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return fmt.Errorf("type must be a string or list of strings: %w", err)
+	}
+	*t = many
+	return nil
+}
+
+func (t schemaTypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+var (
+	schemaTypeNames = map[string]bool{
+		"null": true, "boolean": true, "number": true, "integer": true,
+		"string": true, "array": true, "object": true,
+	}
+	schemaFormats = map[string]bool{"uri": true, "uuid": true, "date": true, "date-time": true}
+)
+
+// ParseJSONSchema parses a schema, returning an error if it uses a type or
+// format outside the supported subset.
+func ParseJSONSchema(s string) (*JSONSchema, error) {
+	var schema JSONSchema
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&schema); err != nil {
+		return nil, fmt.Errorf("json schema: %w", err)
+	}
+	if err := schema.check(""); err != nil {
+		return nil, fmt.Errorf("json schema: %w", err)
+	}
+	return &schema, nil
+}
+
+// MustParseJSONSchema is like ParseJSONSchema but panics if s is invalid.
+func MustParseJSONSchema(s string) *JSONSchema {
+	schema, err := ParseJSONSchema(s)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+func (s *JSONSchema) check(path string) error {
+	for _, t := range s.Type {
+		if !schemaTypeNames[t] {
+			return fmt.Errorf("%sunknown type %q", pathPrefix(path), t)
+		}
+	}
+	if s.Format != "" && !schemaFormats[s.Format] {
+		return fmt.Errorf("%sunsupported format %q", pathPrefix(path), s.Format)
+	}
+	if s.Items != nil {
+		if err := s.Items.check(joinPath(path, "[]")); err != nil {
+			return err
+		}
+	}
+	for name, prop := range s.Properties {
+		if err := prop.check(joinPath(path, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks a JSON encoded value against the schema, returning a
+// *FieldError for each problem found. Field names are paths within the value,
+// prefixed with field.
+func (s *JSONSchema) Validate(field string, value json.RawMessage) []*FieldError {
+	var v interface{}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return []*FieldError{{Field: field, Problem: "is not valid JSON: " + err.Error()}}
+	}
+	var problems []*FieldError
+	s.validate(field, v, &problems)
+	return problems
+}
+
+func (s *JSONSchema) validate(path string, v interface{}, problems *[]*FieldError) {
+	addf := func(path, format string, args ...interface{}) {
+		*problems = append(*problems, &FieldError{Field: path, Problem: fmt.Sprintf(format, args...)})
+	}
+	if len(s.Type) > 0 && !s.Type.match(v) {
+		addf(path, "is %s, want %s", jsonType(v), strings.Join(s.Type, " or "))
+		return
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(normalizeJSON(e), v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			b, _ := json.Marshal(v)
+			addf(path, "%s is not one of the allowed values", b)
+		}
+	}
+	switch v := v.(type) {
+	case string:
+		if s.MinLength != nil && len([]rune(v)) < *s.MinLength {
+			addf(path, "is shorter than %d characters", *s.MinLength)
+		}
+		if v != "" && s.Format != "" {
+			if err := checkFormat(s.Format, v); err != nil {
+				addf(path, "%q is not a valid %s: %v", v, s.Format, err)
+			}
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			addf(path, "%v is less than %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			addf(path, "%v is greater than %v", v, *s.Maximum)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			addf(path, "has %d items, want at least %d", len(v), *s.MinItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(path+"["+strconv.Itoa(i)+"]", item, problems)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				addf(joinPath(path, name), "is required")
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			switch {
+			case ok:
+				prop.validate(joinPath(path, name), v[name], problems)
+			case s.AdditionalProperties != nil && !*s.AdditionalProperties:
+				addf(joinPath(path, name), "is not allowed")
+			}
+		}
+	}
+}
+
+func (t schemaTypes) match(v interface{}) bool {
+	actual := jsonType(v)
+	for _, want := range t {
+		if want == actual || want == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type name of a decoded JSON value.
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// normalizeJSON round trips v through JSON so enum values written in Go
+// compare equal to decoded ones.
+func normalizeJSON(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var n interface{}
+	if err := json.Unmarshal(b, &n); err != nil {
+		return v
+	}
+	return n
+}
+
+func checkFormat(format, v string) error {
+	switch format {
+	case "uri":
+		u, err := url.Parse(v)
+		if err != nil {
+			return err
+		}
+		if !u.IsAbs() || (u.Host == "" && u.Opaque == "") {
+			return fmt.Errorf("not absolute")
+		}
+	case "uuid":
+		_, err := uuid.Parse(v)
+		return err
+	case "date":
+		_, err := time.Parse("2006-01-02", v)
+		return err
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		return err
+	}
+	return nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func pathPrefix(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + ": "
+}
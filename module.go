@@ -3,17 +3,18 @@ package citygraph
 import (
 	"encoding/base64"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/google/uuid"
 
 	"github.com/geomodulus/citygraph/pb"
+	"github.com/geomodulus/citygraph/slug"
 )
 
 var ModuleType = &pb.Identifier{Value: "module"}
 
 type Module struct {
+	LoadedFrom   string                 `json:"-"`
 	ID           string                 `json:"id"`
 	Name         string                 `json:"display_name"`
 	Headline     string                 `json:"headline_html,omitempty"`
@@ -22,11 +23,17 @@ type Module struct {
 	Format       string                 `json:"format"`
 	Categories   []string               `json:"categories"`
 	Creators     []string               `json:"creators"`
-	Camera       map[string]interface{} `json:"camera,omitempty"`
-	PubDate      string                 `json:"pub_date"`
-	LastUpdated  string                 `json:"last_updated,omitempty"`
+	Camera       *Camera                `json:"camera,omitempty"`
+	PubDate      Date                   `json:"pub_date"`
+	LastUpdated  Date                   `json:"last_updated,omitempty"`
 	CodeCredit   string                 `json:"code_credit"`
 	Teaser       map[string]interface{} `json:"teaser,omitempty"`
+
+	// Body, JSFunc and TeaserJSFunc are read from separate files when a
+	// module bundle is loaded with LoadModule.
+	Body         string `json:"-"`
+	JSFunc       string `json:"-"`
+	TeaserJSFunc string `json:"-"`
 }
 
 func (a *Module) UUID() (uuid.UUID, error) {
@@ -54,17 +61,17 @@ func (a *Module) SlugID() (string, error) {
 }
 
 func (a *Module) SlugTitle() string {
-	titleParts := strings.Split(a.Name, " ")
-	if len(titleParts) > 5 {
-		titleParts = titleParts[:5]
-	}
-	cutAndMashed := strings.ToLower(strings.Join(titleParts, "-"))
-	sanitized := strings.TrimRight(
-		url.PathEscape(punctRE.ReplaceAllString(cutAndMashed, "")),
-		".",
-	)
+	return slug.Title(a.Name)
+}
 
-	return sanitized
+// AllSlugTitles returns the slug the older ASCII-only rules made from the
+// module's name, if it differs, and its current slug title, so links built
+// with either can still be recognized.
+func (a *Module) AllSlugTitles() []string {
+	if legacy := slug.Legacy(a.Name); legacy != a.SlugTitle() {
+		return []string{legacy, a.SlugTitle()}
+	}
+	return []string{a.SlugTitle()}
 }
 
 func (a *Module) VertexQuery() (*pb.VertexQuery, error) {
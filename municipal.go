@@ -41,6 +41,9 @@ var (
 
 	//HasConstituency points from a municipality to it's electoral constituencies.
 	HasConstituency = pb.Identifier{Value: "has-constituency"}
+
+	// LocatedIn points from a vertex to a ward or neighbourhood containing it.
+	LocatedIn = pb.Identifier{Value: "located-in"}
 )
 
 // Toronto Beaches
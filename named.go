@@ -0,0 +1,27 @@
+package citygraph
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph/slug"
+)
+
+// Namespaces seeding the name-based IDs of vertices identified by name.
+var (
+	categoryNamespace = uuid.MustParse("5b0c8a3e-6f1d-5e2a-9c47-0d3e8b1f2a64")
+	authorNamespace   = uuid.MustParse("c3e1f4a2-8d7b-5c16-a0e9-4b2d6f8a1c37")
+)
+
+// CategoryID returns the ID of the news-category vertex for the category
+// name. Names with the same slug, such as "City Hall" and "city hall", share
+// a vertex.
+func CategoryID(name string) uuid.UUID {
+	return uuid.NewSHA1(categoryNamespace, []byte(slug.Key(name)))
+}
+
+// AuthorID returns the ID of the news-author vertex for the author's name.
+// Like categories, names with the same slug share a vertex, matching
+// AuthorPath.
+func AuthorID(name string) uuid.UUID {
+	return uuid.NewSHA1(authorNamespace, []byte(slug.Key(name)))
+}
@@ -13,6 +13,7 @@ var (
 	NewsCategory     = pb.Identifier{Value: "news-category"}
 	NewsImage        = pb.Identifier{Value: "news-image"}
 	NewsGeoJSON      = pb.Identifier{Value: "news-geojson"}
+	NewsRevision     = pb.Identifier{Value: "news-revision"}
 
 	// Edge types
 	Published      = pb.Identifier{Value: "published"}       // author or publisher -> publish -> item.
@@ -22,5 +23,6 @@ var (
 	IllustratedBy  = pb.Identifier{Value: "illustrated-by"}
 	CoversTopic    = pb.Identifier{Value: "covers-topic"}
 	Featuring      = pb.Identifier{Value: "featuring"}
+	HasRevision    = pb.Identifier{Value: "has-revision"} // item -> has revision -> prior snapshot of the item.
 )
 
@@ -3,13 +3,13 @@ package citygraph
 import (
 	"encoding/base64"
 	"fmt"
-	"net/url"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/geomodulus/citygraph/pb"
+	"github.com/geomodulus/citygraph/slug"
 )
 
 var (
@@ -134,19 +134,43 @@ func (p *Place) UUID() (uuid.UUID, error) {
 	return id, nil
 }
 
-func (p *Place) SlugTitle() string {
-	useName := p.Name
-	if p.ShortName != "" {
-		useName = p.ShortName
+func (p *Place) SlugID() (string, error) {
+	id, err := p.UUID()
+	if err != nil {
+		return "", err
+	}
+	idBytes, err := id.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if _, err := b.WriteString(base64.URLEncoding.EncodeToString(idBytes)[:22]); err != nil {
+		return "", fmt.Errorf("base64 encode: %v", err)
 	}
-	titleParts := strings.Split(useName, " ")
-	if len(titleParts) > 5 {
-		titleParts = titleParts[:5]
+	return b.String(), err
+}
+
+func (p *Place) SlugTitle() string {
+	return slug.Title(p.slugName())
+}
+
+// AllSlugTitles returns the slug the older ASCII-only rules made from the
+// place's name, if it differs, and its current slug title, so links built
+// with either can still be recognized.
+func (p *Place) AllSlugTitles() []string {
+	if legacy := slug.LegacyPlace(p.slugName()); legacy != p.SlugTitle() {
+		return []string{legacy, p.SlugTitle()}
 	}
-	cutAndMashed := strings.ToLower(strings.Join(titleParts, "-"))
-	sanitized := url.PathEscape(punctRE.ReplaceAllString(cutAndMashed, ""))
+	return []string{p.SlugTitle()}
+}
 
-	return sanitized
+// slugName is the name slug titles are made from: the short name, if the
+// place has one.
+func (p *Place) slugName() string {
+	if p.ShortName != "" {
+		return p.ShortName
+	}
+	return p.Name
 }
 
 func (p *Place) VertexQuery() (*pb.VertexQuery, error) {
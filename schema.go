@@ -0,0 +1,293 @@
+package citygraph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/geomodulus/citygraph/pb"
+)
+
+// ErrUnknownVertexType is returned for a vertex type with no schema.
+var ErrUnknownVertexType = errors.New("vertex type has no schema")
+
+// PropertySchema describes a property carried by vertices of some type.
+type PropertySchema struct {
+	Name string
+	// Required properties must be set on every vertex of the type, and can't
+	// be deleted or set to null.
+	Required bool
+	// Value, if set, is the JSON Schema the property's value must match.
+	Value *JSONSchema
+}
+
+// VertexSchema declares a vertex type and the properties its vertices carry.
+type VertexSchema struct {
+	Type       *pb.Identifier
+	Properties []*PropertySchema
+	// Open schemas accept properties they don't declare, for types whose
+	// vertices are written outside this package.
+	Open bool
+}
+
+// Property returns the schema of the named property, or nil if it isn't
+// declared.
+func (s *VertexSchema) Property(name string) *PropertySchema {
+	for _, p := range s.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// CheckProperty checks a JSON encoded property value, returning a
+// *ValidationError listing every problem found.
+func (s *VertexSchema) CheckProperty(name string, value json.RawMessage) error {
+	var problems []*FieldError
+	s.checkProperty(name, value, &problems)
+	return s.err(problems)
+}
+
+// Check checks a vertex's properties, keyed by name, including that every
+// required property is set. It returns a *ValidationError listing every
+// problem found.
+func (s *VertexSchema) Check(props map[string]json.RawMessage) error {
+	var problems []*FieldError
+	for _, p := range s.Properties {
+		if _, ok := props[p.Name]; p.Required && !ok {
+			problems = append(problems, &FieldError{Field: p.Name, Problem: "is required"})
+		}
+	}
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s.checkProperty(name, props[name], &problems)
+	}
+	return s.err(problems)
+}
+
+func (s *VertexSchema) checkProperty(name string, value json.RawMessage, problems *[]*FieldError) {
+	p := s.Property(name)
+	switch {
+	case p == nil && s.Open:
+		return
+	case p == nil:
+		*problems = append(*problems, &FieldError{Field: name, Problem: "is not a property of " + s.Type.GetValue()})
+		return
+	case p.Required && string(value) == "null":
+		*problems = append(*problems, &FieldError{Field: name, Problem: "is required"})
+		return
+	case p.Value != nil:
+		*problems = append(*problems, p.Value.Validate(name, value)...)
+	}
+}
+
+func (s *VertexSchema) err(problems []*FieldError) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Kind: s.Type.GetValue(), Fields: problems}
+}
+
+// SchemaRegistry holds the schema of each vertex type.
+type SchemaRegistry struct {
+	schemas map[string]*VertexSchema
+}
+
+// NewSchemaRegistry returns a registry of the given schemas. It's an error to
+// declare a type twice, or a property twice within a type.
+func NewSchemaRegistry(schemas ...*VertexSchema) (*SchemaRegistry, error) {
+	r := &SchemaRegistry{schemas: map[string]*VertexSchema{}}
+	for _, s := range schemas {
+		if err := r.register(s); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func MustNewSchemaRegistry(schemas ...*VertexSchema) *SchemaRegistry {
+	r, err := NewSchemaRegistry(schemas...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (r *SchemaRegistry) register(s *VertexSchema) error {
+	t := s.Type.GetValue()
+	if t == "" {
+		return errors.New("schema has no vertex type")
+	}
+	if _, ok := r.schemas[t]; ok {
+		return fmt.Errorf("vertex type %q is declared twice", t)
+	}
+	seen := map[string]bool{}
+	for _, p := range s.Properties {
+		if seen[p.Name] {
+			return fmt.Errorf("vertex type %q declares property %q twice", t, p.Name)
+		}
+		seen[p.Name] = true
+	}
+	r.schemas[t] = s
+	return nil
+}
+
+// Lookup returns the schema of the given vertex type.
+func (r *SchemaRegistry) Lookup(vertexType string) (*VertexSchema, error) {
+	s, ok := r.schemas[vertexType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownVertexType, vertexType)
+	}
+	return s, nil
+}
+
+// Types lists the declared vertex types in alphabetical order.
+func (r *SchemaRegistry) Types() []string {
+	types := make([]string, 0, len(r.schemas))
+	for t := range r.schemas {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Reusable property value schemas.
+var (
+	nameValue     = MustParseJSONSchema(`{"type": "string", "minLength": 1}`)
+	stringValue   = MustParseJSONSchema(`{"type": "string"}`)
+	stringsValue  = MustParseJSONSchema(`{"type": ["array", "null"], "items": {"type": "string"}}`)
+	urlValue      = MustParseJSONSchema(`{"type": "string", "format": "uri"}`)
+	uuidValue     = MustParseJSONSchema(`{"type": "string", "format": "uuid"}`)
+	dateValue     = MustParseJSONSchema(`{"type": "string", "format": "date-time"}`)
+	boolValue     = MustParseJSONSchema(`{"type": "boolean"}`)
+	objectValue   = MustParseJSONSchema(`{"type": ["object", "null"]}`)
+	cameraValue   = MustParseJSONSchema(`{"type": "object"}`)
+	locationValue = MustParseJSONSchema(`{
+		"type": "object",
+		"required": ["lng", "lat"],
+		"properties": {
+			"lng": {"type": "number", "minimum": -180, "maximum": 180},
+			"lat": {"type": "number", "minimum": -90, "maximum": 90}
+		}
+	}`)
+)
+
+// pageProperties are carried by articles and modules.
+func pageProperties() []*PropertySchema {
+	return []*PropertySchema{
+		{Name: PropertyNameDisplayName, Required: true, Value: nameValue},
+		{Name: "headline_html", Value: stringValue},
+		{Name: "slug_id", Value: stringValue},
+		{Name: "slug_title", Value: stringValue},
+		{Name: "slug_titles", Value: stringsValue},
+		{Name: "creators", Value: stringsValue},
+		{Name: "camera", Value: cameraValue},
+		{Name: PropertyNameFormat, Value: stringValue},
+		{Name: "published_on", Value: dateValue},
+		{Name: PropertyNameUpdatedAt, Value: dateValue},
+		{Name: "categories", Value: stringsValue},
+		{Name: "code_credit", Value: stringValue},
+		{Name: "h2", Value: stringValue},
+		{Name: PropertyNameImgURL, Value: urlValue},
+		{Name: "teaser", Value: objectValue},
+		{Name: "teaser_function", Value: stringValue},
+		{Name: PropertyNameBodyText, Value: stringValue},
+		{Name: PropertyNameJSFunc, Value: stringValue},
+		{Name: PropertyNameCanonicalPath, Value: stringValue},
+		{Name: PropertyNameCanonicalURL, Value: urlValue},
+	}
+}
+
+// openSchema declares a type whose properties are written outside this
+// package, so only its display name is checked.
+func openSchema(t *pb.Identifier) *VertexSchema {
+	return &VertexSchema{
+		Type:       t,
+		Properties: []*PropertySchema{{Name: PropertyNameDisplayName, Value: stringValue}},
+		Open:       true,
+	}
+}
+
+// Schemas declares every vertex type in the graph. Types written by the db
+// package are closed: their schemas list every property the Store writes.
+var Schemas = MustNewSchemaRegistry(
+	&VertexSchema{
+		Type: ArticleType,
+		Properties: append(pageProperties(),
+			&PropertySchema{Name: "past_names", Value: stringsValue},
+			&PropertySchema{Name: "pitch", Value: MustParseJSONSchema(fmt.Sprintf(`{"type": "number", "minimum": 0, "maximum": %d}`, MaxPitch))},
+			&PropertySchema{Name: "is_live", Value: boolValue},
+		),
+	},
+	&VertexSchema{Type: ModuleType, Properties: pageProperties()},
+	&VertexSchema{
+		Type: &NewsGeoJSON,
+		Properties: []*PropertySchema{
+			{Name: PropertyNameSource, Value: objectValue},
+			{Name: "sources", Value: MustParseJSONSchema(`{"type": ["array", "null"], "items": {"type": "object"}}`)},
+			{Name: PropertyNameGeoJSONURL, Value: urlValue},
+			{Name: PropertyNameGeoJSONFeature, Value: MustParseJSONSchema(`{"type": "object", "required": ["type"]}`)},
+		},
+	},
+	&VertexSchema{
+		Type: &NewsRevision,
+		Properties: []*PropertySchema{
+			{Name: "revision_of", Required: true, Value: uuidValue},
+			{Name: "created_at", Required: true, Value: dateValue},
+			{Name: "properties", Required: true, Value: MustParseJSONSchema(`{"type": "object"}`)},
+		},
+	},
+	&VertexSchema{
+		Type: AnnouncementType,
+		Properties: []*PropertySchema{
+			{Name: PropertyNameDisplayName, Required: true, Value: nameValue},
+			{Name: "body_html", Required: true, Value: nameValue},
+			{Name: "link", Value: urlValue},
+			{Name: "severity", Required: true, Value: MustParseJSONSchema(`{"enum": ["info", "warning", "critical"]}`)},
+			{Name: "start", Value: dateValue},
+			{Name: "end", Value: dateValue},
+			{Name: "geometry", Value: MustParseJSONSchema(`{"type": "object", "required": ["type"]}`)},
+		},
+	},
+	&VertexSchema{
+		Type: WebLinkType,
+		Properties: []*PropertySchema{
+			{Name: PropertyNameDisplayName, Required: true, Value: nameValue},
+			{Name: "url", Required: true, Value: MustParseJSONSchema(`{"type": "string", "format": "uri", "minLength": 1}`)},
+			{Name: PropertyNameCanonicalURL, Value: urlValue},
+			{Name: "h2", Value: stringValue},
+			{Name: PropertyNameImgURL, Value: urlValue},
+			{Name: "source", Value: stringValue},
+			{Name: "opengraph", Value: MustParseJSONSchema(`{"type": "object"}`)},
+		},
+	},
+	&VertexSchema{
+		Type: &PlaceType,
+		Properties: []*PropertySchema{
+			{Name: PropertyNameDisplayName, Value: stringValue},
+			{Name: "location", Value: locationValue},
+			{Name: "camera", Value: cameraValue},
+			{Name: "street_address", Value: stringValue},
+		},
+		Open: true,
+	},
+	openSchema(&NewsPublisher),
+	openSchema(&NewsArticle),
+	openSchema(&NewsWireArticle),
+	openSchema(&NewsWireBulletin),
+	openSchema(&NewsAuthor),
+	openSchema(&NewsCategory),
+	openSchema(&NewsImage),
+	openSchema(&CityPrimary),
+	openSchema(&CityIntersection),
+	openSchema(&CityNeighbourhood),
+	openSchema(&CityMayorOffice),
+	openSchema(&CityWard),
+	openSchema(&Beach),
+)
@@ -0,0 +1,97 @@
+package citygraph
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema := MustParseJSONSchema(`{
+		"type": "object",
+		"required": ["name", "location"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"url": {"type": "string", "format": "uri"},
+			"tags": {"type": "array", "minItems": 1, "items": {"type": "string"}},
+			"kind": {"enum": ["park", "beach"]},
+			"location": {
+				"type": "object",
+				"properties": {
+					"lng": {"type": "number", "minimum": -180, "maximum": 180},
+					"lat": {"type": "number", "minimum": -90, "maximum": 90}
+				}
+			}
+		}
+	}`)
+
+	for _, tc := range []struct {
+		value string
+		want  []string
+	}{
+		{`{"name": "Woodbine", "location": {"lng": -79.3, "lat": 43.66}, "url": "", "kind": "beach"}`, nil},
+		{`{"name": "", "url": "/beaches", "tags": [], "kind": "lake", "extra": 1}`, []string{
+			"b.location", "b.extra", "b.kind", "b.name", "b.tags", "b.url",
+		}},
+		{`{"name": "Woodbine", "location": {"lng": -279.3, "lat": "43.66"}, "tags": ["sand", 7]}`, []string{
+			"b.location.lat", "b.location.lng", "b.tags[1]",
+		}},
+		{`"Woodbine"`, []string{"b"}},
+	} {
+		var got []string
+		for _, f := range schema.Validate("b", json.RawMessage(tc.value)) {
+			got = append(got, f.Field)
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("Validate(%s) field errors diff:\n%s\n", tc.value, diff)
+		}
+	}
+}
+
+func TestParseJSONSchemaRejectsUnsupported(t *testing.T) {
+	for _, s := range []string{
+		`{"type": "text"}`,
+		`{"type": "string", "format": "email"}`,
+		`{"type": "string", "pattern": "^a"}`,
+		`{"properties": {"a": {"type": ["string", "int"]}}}`,
+	} {
+		if _, err := ParseJSONSchema(s); err == nil {
+			t.Errorf("ParseJSONSchema(%s) returned nil err", s)
+		}
+	}
+}
+
+func TestSchemasCheck(t *testing.T) {
+	s, err := Schemas.Lookup(AnnouncementType.Value)
+	if err != nil {
+		t.Fatalf("Schemas.Lookup(announcement) returned err: %v", err)
+	}
+	err = s.Check(map[string]json.RawMessage{
+		PropertyNameDisplayName: json.RawMessage(`"Line 1 closed"`),
+		"severity":              json.RawMessage(`"urgent"`),
+		"link":                  json.RawMessage(`"/alerts"`),
+		"colour":                json.RawMessage(`"red"`),
+	})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Check() returned %v, want *ValidationError", err)
+	}
+	var got []string
+	for _, f := range verr.Fields {
+		got = append(got, f.Field)
+	}
+	want := []string{"body_html", "colour", "link", "severity"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Check() field errors diff:\n%s\n", diff)
+	}
+
+	if _, err := Schemas.Lookup("no-such-type"); !errors.Is(err, ErrUnknownVertexType) {
+		t.Errorf("Schemas.Lookup(no-such-type) returned %v, want ErrUnknownVertexType", err)
+	}
+	if _, err := NewSchemaRegistry(openSchema(&Beach), openSchema(&Beach)); err == nil {
+		t.Errorf("NewSchemaRegistry() with a type declared twice returned nil err")
+	}
+}
@@ -0,0 +1,124 @@
+// Package slug turns headlines and names into the readable slugs used in
+// Torontoverse URLs and property names.
+//
+// Slugs are lowercase words of letters and digits joined by dashes. Accented
+// letters are transliterated to their unaccented forms, apostrophes are
+// dropped so "Joe's" becomes "joes", and every other run of punctuation,
+// dashes or whitespace separates words.
+package slug
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxWords is the number of words kept in a title slug.
+const MaxWords = 5
+
+// replacements transliterates letters that don't decompose into a base letter
+// and combining accents.
+var replacements = map[rune]string{
+	'ß': "ss",
+	'æ': "ae", 'Æ': "ae",
+	'œ': "oe", 'Œ': "oe",
+	'ø': "o", 'Ø': "o",
+	'ł': "l", 'Ł': "l",
+	'đ': "d", 'Đ': "d",
+	'ð': "d", 'Ð': "d",
+	'þ': "th", 'Þ': "th",
+	'ı': "i",
+}
+
+func isApostrophe(r rune) bool {
+	switch r {
+	case '\'', '’', '‘', 'ʼ', '`':
+		return true
+	}
+	return false
+}
+
+// Words splits s into the transliterated, lowercase words a slug is made of.
+func Words(s string) []string {
+	var (
+		words []string
+		word  strings.Builder
+	)
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range norm.NFKD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r) || isApostrophe(r):
+			// Accents split off by decomposition and apostrophes are dropped
+			// without breaking the word.
+		case replacements[r] != "":
+			word.WriteString(replacements[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// Title returns the slug for a headline or name: its first MaxWords words,
+// escaped for use as a URL path segment.
+func Title(s string) string {
+	words := Words(s)
+	if len(words) > MaxWords {
+		words = words[:MaxWords]
+	}
+	return url.PathEscape(strings.Join(words, "-"))
+}
+
+// Key returns the slug for every word of s, for use in property names.
+func Key(s string) string {
+	return strings.Join(Words(s), "-")
+}
+
+// legacyPunctRE matches the characters the ASCII-only slug rules removed.
+var legacyPunctRE = regexp.MustCompile(`([^\w\s-_\.~])`)
+
+// Legacy returns the title slug produced by the ASCII-only rules used before
+// this package, so links built with them can still be recognized.
+func Legacy(s string) string {
+	return strings.TrimRight(LegacyPlace(s), ".")
+}
+
+// LegacyPlace is Legacy as it was applied to place names, which kept
+// trailing dots.
+func LegacyPlace(s string) string {
+	titleParts := strings.Split(s, " ")
+	if len(titleParts) > MaxWords {
+		titleParts = titleParts[:MaxWords]
+	}
+	cutAndMashed := strings.ToLower(strings.Join(titleParts, "-"))
+	return url.PathEscape(legacyPunctRE.ReplaceAllString(cutAndMashed, ""))
+}
+
+// Collisions returns the slugs shared by more than one item, given each
+// item's slug keyed by its ID. Each slug maps to the sorted IDs using it.
+func Collisions(slugs map[string]string) map[string][]string {
+	bySlug := map[string][]string{}
+	for id, s := range slugs {
+		bySlug[s] = append(bySlug[s], id)
+	}
+	collisions := map[string][]string{}
+	for s, ids := range bySlug {
+		if len(ids) > 1 {
+			sort.Strings(ids)
+			collisions[s] = ids
+		}
+	}
+	return collisions
+}
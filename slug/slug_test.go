@@ -0,0 +1,53 @@
+package slug
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTitle(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"Café Landwer", "cafe-landwer"},
+		{"Le métro de Montréal à l'heure", "le-metro-de-montreal-a"},
+		{"Toronto’s new bike lanes", "torontos-new-bike-lanes"},
+		{"Bloor—Danforth line closed", "bloor-danforth-line-closed"},
+		{"Bars & Restaurants", "bars-restaurants"},
+		{"St. Lawrence Market.", "st-lawrence-market"},
+		{"Straße  Øresund", "strasse-oresund"},
+		{"One two three four five six", "one-two-three-four-five"},
+	} {
+		if got := Title(tc.in); got != tc.want {
+			t.Errorf("Title(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	if got, want := Key("One two three four five six"), "one-two-three-four-five-six"; got != want {
+		t.Errorf("Key() = %q, want %q", got, want)
+	}
+}
+
+func TestLegacy(t *testing.T) {
+	if got, want := Legacy("Café Landwer opens."), "caf-landwer-opens"; got != want {
+		t.Errorf("Legacy() = %q, want %q", got, want)
+	}
+	if got, want := LegacyPlace("Café Landwer Inc."), "caf-landwer-inc."; got != want {
+		t.Errorf("LegacyPlace() = %q, want %q", got, want)
+	}
+}
+
+func TestCollisions(t *testing.T) {
+	got := Collisions(map[string]string{
+		"b": Title("Café Landwer"),
+		"a": Title("Cafe Landwer"),
+		"c": Title("Cafe Landwer Queen West"),
+	})
+	want := map[string][]string{"cafe-landwer": {"a", "b"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Collisions() diff:\n%s\n", diff)
+	}
+}
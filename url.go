@@ -0,0 +1,126 @@
+package citygraph
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/geomodulus/citygraph/slug"
+)
+
+// Canonical link property names.
+var (
+	// PropertyNameCanonicalPath identifies a property containing the
+	// site-relative path of the page for this vertex.
+	PropertyNameCanonicalPath = "canonical_path"
+	// PropertyNameCanonicalURL identifies a property containing the absolute
+	// URL of the page for this vertex.
+	PropertyNameCanonicalURL = "canonical_url"
+)
+
+// Path returns the canonical path of the module's page.
+func (a *Module) Path() (string, error) {
+	slugID, err := a.SlugID()
+	if err != nil {
+		return "", fmt.Errorf("slug id: %v", err)
+	}
+	return fmt.Sprintf("/modules/%s/%s", slugID, a.SlugTitle()), nil
+}
+
+// Path returns the canonical path of the place's page.
+func (p *Place) Path() (string, error) {
+	slugID, err := p.SlugID()
+	if err != nil {
+		return "", fmt.Errorf("slug id: %v", err)
+	}
+	return fmt.Sprintf("/places/%s/%s", slugID, p.SlugTitle()), nil
+}
+
+// LocationPath returns the canonical path of the page for one of the place's
+// locations.
+func (p *Place) LocationPath(loc *PlaceLocation) (string, error) {
+	placePath, err := p.Path()
+	if err != nil {
+		return "", err
+	}
+	slugID, err := loc.SlugID()
+	if err != nil {
+		return "", fmt.Errorf("slug id: %v", err)
+	}
+	return fmt.Sprintf("%s/%s", placePath, slugID), nil
+}
+
+// AuthorPath returns the canonical path of the page listing an author's
+// articles.
+func AuthorPath(author string) string {
+	return "/authors/" + slug.Key(author)
+}
+
+// CategoryPath returns the canonical path of the page listing a category's
+// articles.
+func CategoryPath(category string) string {
+	return "/categories/" + slug.Key(category)
+}
+
+// URLBuilder turns canonical paths into absolute URLs on the site.
+type URLBuilder struct {
+	base string
+}
+
+// NewURLBuilder returns a URLBuilder for the site at baseURL, which must be an
+// absolute http or https URL, optionally with a path prefix.
+func NewURLBuilder(baseURL string) (*URLBuilder, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("base url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("base url %q: want an absolute http(s) URL without query or fragment", baseURL)
+	}
+	return &URLBuilder{base: strings.TrimSuffix(u.String(), "/")}, nil
+}
+
+// URL returns the absolute URL of a canonical path.
+func (b *URLBuilder) URL(path string) string {
+	return b.base + path
+}
+
+func (b *URLBuilder) ArticleURL(a *Article) (string, error) {
+	path, err := a.Path()
+	if err != nil {
+		return "", err
+	}
+	return b.URL(path), nil
+}
+
+func (b *URLBuilder) ModuleURL(m *Module) (string, error) {
+	path, err := m.Path()
+	if err != nil {
+		return "", err
+	}
+	return b.URL(path), nil
+}
+
+func (b *URLBuilder) PlaceURL(p *Place) (string, error) {
+	path, err := p.Path()
+	if err != nil {
+		return "", err
+	}
+	return b.URL(path), nil
+}
+
+func (b *URLBuilder) LocationURL(p *Place, loc *PlaceLocation) (string, error) {
+	path, err := p.LocationPath(loc)
+	if err != nil {
+		return "", err
+	}
+	return b.URL(path), nil
+}
+
+func (b *URLBuilder) AuthorURL(author string) string {
+	return b.URL(AuthorPath(author))
+}
+
+func (b *URLBuilder) CategoryURL(category string) string {
+	return b.URL(CategoryPath(category))
+}
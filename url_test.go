@@ -0,0 +1,65 @@
+package citygraph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestURLBuilder(t *testing.T) {
+	b, err := NewURLBuilder("https://torontoverse.com/")
+	if err != nil {
+		t.Fatalf("NewURLBuilder() returned err: %v", err)
+	}
+
+	article := &Article{ID: NewID().String(), Name: "Bike lanes come to Bloor"}
+	articleURL, err := b.ArticleURL(article)
+	if err != nil {
+		t.Fatalf("ArticleURL() returned err: %v", err)
+	}
+	if !strings.HasPrefix(articleURL, "https://torontoverse.com/articles/") || !strings.HasSuffix(articleURL, "/bike-lanes-come-to-bloor") {
+		t.Errorf("ArticleURL() = %q", articleURL)
+	}
+
+	module := &Module{ID: NewID().String(), Name: "Ward map"}
+	moduleURL, err := b.ModuleURL(module)
+	if err != nil {
+		t.Fatalf("ModuleURL() returned err: %v", err)
+	}
+	if !strings.HasPrefix(moduleURL, "https://torontoverse.com/modules/") || !strings.HasSuffix(moduleURL, "/ward-map") {
+		t.Errorf("ModuleURL() = %q", moduleURL)
+	}
+
+	place := &Place{ID: NewID().String(), Name: "Café Landwer", ShortName: "Landwer"}
+	placeURL, err := b.PlaceURL(place)
+	if err != nil {
+		t.Fatalf("PlaceURL() returned err: %v", err)
+	}
+	loc := &PlaceLocation{ID: NewID().String()}
+	locURL, err := b.LocationURL(place, loc)
+	if err != nil {
+		t.Fatalf("LocationURL() returned err: %v", err)
+	}
+	placeSlugID, _ := place.SlugID()
+	slugID, _ := loc.SlugID()
+	for got, want := range map[string]string{
+		placeURL:                   "https://torontoverse.com/places/" + placeSlugID + "/landwer",
+		locURL:                     "https://torontoverse.com/places/" + placeSlugID + "/landwer/" + slugID,
+		b.AuthorURL("Raoul Duke"):  "https://torontoverse.com/authors/raoul-duke",
+		b.CategoryURL("Open Data"): "https://torontoverse.com/categories/open-data",
+	} {
+		if got != want {
+			t.Errorf("URLBuilder built %q, want %q", got, want)
+		}
+	}
+
+	namesake := &Place{ID: NewID().String(), Name: "Café Landwer", ShortName: "Landwer"}
+	if namesakeURL, _ := b.PlaceURL(namesake); namesakeURL == placeURL {
+		t.Errorf("PlaceURL() built %q for two places with the same name", placeURL)
+	}
+
+	for _, bad := range []string{"/relative", "ftp://torontoverse.com", "https://torontoverse.com/?a=b"} {
+		if _, err := NewURLBuilder(bad); err == nil {
+			t.Errorf("NewURLBuilder(%q) returned no error", bad)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package citygraph
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldError describes a problem with a single field of a content struct.
+// Field uses the field's JSON name.
+type FieldError struct {
+	Field   string
+	Problem string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Problem
+}
+
+// ValidationError collects every field-level problem found when validating
+// a content struct.
+type ValidationError struct {
+	// Kind names the type of content validated, eg. "article".
+	Kind string
+	ID   string
+	// Fields lists each problem found, in field order.
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		problems = append(problems, f.Error())
+	}
+	subject := e.Kind
+	if e.ID != "" {
+		subject += " " + e.ID
+	}
+	return fmt.Sprintf("invalid %s: %s", subject, strings.Join(problems, "; "))
+}
+
+// Unwrap returns the individual field errors so they can be inspected with
+// errors.As.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		errs = append(errs, f)
+	}
+	return errs
+}
+
+// validator accumulates field errors for one content struct.
+type validator struct {
+	fields []*FieldError
+}
+
+func (v *validator) addf(field, format string, args ...interface{}) {
+	v.fields = append(v.fields, &FieldError{Field: field, Problem: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) uuid(field, value string) {
+	if value == "" {
+		v.addf(field, "is required")
+		return
+	}
+	if _, err := uuid.Parse(value); err != nil {
+		v.addf(field, "%q is not a valid UUID", value)
+	}
+}
+
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf(field, "is required")
+	}
+}
+
+// absURL checks that value, if set, is an absolute http or https URL.
+func (v *validator) absURL(field, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addf(field, "%q is not an absolute http(s) URL", value)
+	}
+}
+
+// dates checks that lastUpdated, if set, isn't before pubDate.
+func (v *validator) dates(pubDate, lastUpdated Date) {
+	if !pubDate.IsZero() && !lastUpdated.IsZero() && lastUpdated.Before(pubDate.Time) {
+		v.addf("last_updated", "%s is before pub_date %s", lastUpdated, pubDate)
+	}
+}
+
+func (v *validator) lngLat(field string, loc LngLat) {
+	if loc.Lng < -180 || loc.Lng > 180 {
+		v.addf(field+".lng", "%g is out of range [-180, 180]", loc.Lng)
+	}
+	if loc.Lat < -90 || loc.Lat > 90 {
+		v.addf(field+".lat", "%g is out of range [-90, 90]", loc.Lat)
+	}
+}
+
+func (v *validator) images(field string, images []*Image) {
+	for i, img := range images {
+		if img == nil {
+			continue
+		}
+		v.absURL(fmt.Sprintf("%s[%d].url", field, i), img.URL)
+	}
+}
+
+// nested adds the field errors from validating a child struct, prefixing
+// their field names.
+func (v *validator) nested(prefix string, err error) {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		for _, f := range verr.Fields {
+			v.fields = append(v.fields, &FieldError{Field: prefix + "." + f.Field, Problem: f.Problem})
+		}
+	} else if err != nil {
+		v.addf(prefix, "%v", err)
+	}
+}
+
+// promotion checks that the article's promotion window is consistent.
+func (v *validator) promotion(a *Article) {
+	if !a.PromoAt.IsZero() && !a.PromoUntil.IsZero() && a.PromoUntil.Before(a.PromoAt) {
+		v.addf("promo_until", "%s is before promo_start %s", a.PromoUntil.Format(time.RFC3339), a.PromoAt.Format(time.RFC3339))
+	}
+	if !a.PromoAt.IsZero() && !a.PromoExpires.IsZero() && a.PromoExpires.Before(a.PromoAt) {
+		v.addf("promo_expires", "%s is before promo_start %s", a.PromoExpires.Format(time.RFC3339), a.PromoAt.Format(time.RFC3339))
+	}
+	if !a.PromoUntil.IsZero() && !a.PromoExpires.IsZero() && a.PromoExpires.Before(a.PromoUntil) {
+		v.addf("promo_until", "%s is after promo_expires %s", a.PromoUntil.Format(time.RFC3339), a.PromoExpires.Format(time.RFC3339))
+	}
+	if a.PromoWait.Duration < 0 {
+		v.addf("promo_wait", "%s is negative", a.PromoWait)
+	}
+}
+
+func (v *validator) err(kind, id string) error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Kind: kind, ID: id, Fields: v.fields}
+}
+
+// Validate checks the article for problems that would make it unsafe to
+// write to the graph, returning a *ValidationError listing all of them.
+func (a *Article) Validate() error {
+	v := &validator{}
+	v.uuid("id", a.ID)
+	v.required("display_name", a.Name)
+	v.required("headline_html", a.Headline)
+	v.absURL("img_url", a.FeatureImage)
+	v.dates(a.PubDate, a.LastUpdated)
+	if a.Pitch < 0 || a.Pitch > MaxPitch {
+		v.addf("pitch", "%g is out of range [0, %d]", a.Pitch, MaxPitch)
+	}
+	if a.Camera != nil {
+		v.nested("camera", a.Camera.Validate())
+	}
+	v.promotion(a)
+	for i, related := range a.Related {
+		v.uuid(fmt.Sprintf("related[%d]", i), related)
+	}
+	for i, dataset := range a.GeoJSONDatasets {
+		if dataset == nil {
+			continue
+		}
+		v.nested(fmt.Sprintf("geojson_datasets[%d]", i), dataset.Validate())
+	}
+	return v.err("article", a.ID)
+}
+
+// ValidatePromotion checks only the article's promotion window, returning a
+// *ValidationError listing every problem found.
+func (a *Article) ValidatePromotion() error {
+	v := &validator{}
+	v.promotion(a)
+	return v.err("article", a.ID)
+}
+
+// Validate checks the module for problems that would make it unsafe to write
+// to the graph, returning a *ValidationError listing all of them.
+func (m *Module) Validate() error {
+	v := &validator{}
+	v.uuid("id", m.ID)
+	v.required("display_name", m.Name)
+	v.absURL("img_url", m.FeatureImage)
+	v.dates(m.PubDate, m.LastUpdated)
+	if m.Camera != nil {
+		v.nested("camera", m.Camera.Validate())
+	}
+	return v.err("module", m.ID)
+}
+
+// Validate checks the place and each of its locations, returning a
+// *ValidationError listing every problem found.
+func (p *Place) Validate() error {
+	v := &validator{}
+	v.uuid("id", p.ID)
+	v.required("name", p.Name)
+	v.absURL("url", p.URL)
+	v.lngLat("location", p.Location)
+	v.images("images", p.Images)
+	for i, loc := range p.Locations {
+		if loc == nil {
+			continue
+		}
+		v.nested(fmt.Sprintf("locations[%d]", i), loc.Validate())
+	}
+	return v.err("place", p.ID)
+}
+
+// Validate checks the location, returning a *ValidationError listing every
+// problem found.
+func (p *PlaceLocation) Validate() error {
+	v := &validator{}
+	v.uuid("id", p.ID)
+	v.lngLat("location", p.Location)
+	v.images("images", p.Images)
+	return v.err("place location", p.ID)
+}
+
+// Validate checks the announcement, including its geometry, returning a
+// *ValidationError listing every problem found.
+func (a *Announcement) Validate() error {
+	v := &validator{}
+	v.uuid("id", a.ID)
+	v.required("display_name", a.Title)
+	v.required("body_html", a.BodyHTML)
+	v.absURL("link", a.Link)
+	if !a.Severity.valid() {
+		v.addf("severity", "%q is not one of info, warning or critical", a.Severity)
+	}
+	if !a.Start.IsZero() && !a.End.IsZero() && !a.End.After(a.Start) {
+		v.addf("end", "%s is not after start %s", a.End.Format(time.RFC3339), a.Start.Format(time.RFC3339))
+	}
+	if a.Geometry != nil {
+		v.nested("geometry", a.Geometry.Validate())
+	}
+	return v.err("announcement", a.ID)
+}
+
+// Validate checks the web link, returning a *ValidationError listing every
+// problem found. The ID must be the one derived from the canonical URL.
+func (w *WebLink) Validate() error {
+	v := &validator{}
+	v.uuid("id", w.ID)
+	v.required("url", w.URL)
+	v.absURL("url", w.URL)
+	if canonical, err := CanonicalizeURL(w.URL); err == nil {
+		if canonical != w.URL {
+			v.addf("url", "%q is not canonical, want %q", w.URL, canonical)
+		} else if id := WebLinkID(canonical).String(); w.ID != "" && w.ID != id {
+			v.addf("id", "%q does not match the URL, want %q", w.ID, id)
+		}
+	}
+	v.required("display_name", w.Title)
+	v.absURL("img_url", w.ImageURL)
+	return v.err("web link", w.ID)
+}
+
+// Validate checks the dataset and its sources, returning a *ValidationError
+// listing every problem found.
+func (d *GeoJSONDataset) Validate() error {
+	v := &validator{}
+	v.uuid("id", d.ID)
+	v.absURL("url", d.URL)
+	if d.Source != nil {
+		v.absURL("source.url", d.Source.URL)
+		v.absURL("source.license_url", d.Source.LicenseURL)
+	}
+	for i, src := range d.Sources {
+		if src == nil {
+			continue
+		}
+		v.absURL(fmt.Sprintf("sources[%d].url", i), src.URL)
+		v.absURL(fmt.Sprintf("sources[%d].license_url", i), src.LicenseURL)
+	}
+	return v.err("geojson dataset", d.ID)
+}
@@ -0,0 +1,111 @@
+package citygraph
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestArticleValidate(t *testing.T) {
+	valid := &Article{
+		ID:           NewID().String(),
+		Name:         "Some headline",
+		Headline:     "<p>Some headline</p>",
+		FeatureImage: "https://some.url/image.png",
+		PubDate:      MustParseDate("2023-03-01"),
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() on a valid article returned err: %v", err)
+	}
+
+	promoAt := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	invalid := &Article{
+		ID:           "not-a-uuid",
+		FeatureImage: "/images/relative.png",
+		PubDate:      MustParseDate("2023-03-01"),
+		LastUpdated:  MustParseDate("2023-02-28"),
+		PromoAt:      promoAt,
+		PromoUntil:   promoAt.Add(-time.Hour),
+		GeoJSONDatasets: []*GeoJSONDataset{{
+			ID:  NewID().String(),
+			URL: "data.geojson",
+		}},
+	}
+	err := invalid.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() returned %v, want *ValidationError", err)
+	}
+	var got []string
+	for _, f := range verr.Fields {
+		got = append(got, f.Field)
+	}
+	want := []string{"id", "display_name", "headline_html", "img_url", "last_updated", "promo_until", "geojson_datasets[0].url"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Validate() field errors diff:\n%s\n", diff)
+	}
+
+	var ferr *FieldError
+	if !errors.As(err, &ferr) || ferr.Field != "id" {
+		t.Errorf("errors.As(err, *FieldError) = %v, want the id field error", ferr)
+	}
+}
+
+func TestPlaceValidate(t *testing.T) {
+	place := &Place{
+		ID:       NewID().String(),
+		Name:     "Some place",
+		Location: LngLat{Lng: -79.38, Lat: 43.65},
+		Locations: []*PlaceLocation{{
+			ID:       NewID().String(),
+			Location: LngLat{Lng: 43.65, Lat: -179.38},
+		}},
+	}
+	err := place.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() returned %v, want *ValidationError", err)
+	}
+	if len(verr.Fields) != 1 || verr.Fields[0].Field != "locations[0].location.lat" {
+		t.Errorf("Validate() = %v, want a single locations[0].location.lat error", err)
+	}
+}
+
+func TestAnnouncementValidate(t *testing.T) {
+	start := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	valid := &Announcement{
+		ID:       NewID().String(),
+		Title:    "Line 1 closed",
+		BodyHTML: "<p>Shuttle buses are running.</p>",
+		Severity: SeverityWarning,
+		Start:    start,
+		End:      start.Add(time.Hour),
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() on a valid announcement returned err: %v", err)
+	}
+
+	invalid := &Announcement{
+		ID:       NewID().String(),
+		Title:    "Line 1 closed",
+		Link:     "/alerts/line-1",
+		Severity: "urgent",
+		Start:    start,
+		End:      start,
+	}
+	err := invalid.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() returned %v, want *ValidationError", err)
+	}
+	var got []string
+	for _, f := range verr.Fields {
+		got = append(got, f.Field)
+	}
+	want := []string{"body_html", "link", "severity", "end"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Validate() field errors diff:\n%s\n", diff)
+	}
+}
@@ -0,0 +1,318 @@
+package citygraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/geomodulus/citygraph/pb"
+)
+
+// ValidatingClient wraps a GraphClient, checking property writes against the
+// schema of each vertex they touch. Writes that violate a schema are
+// rejected with a *ValidationError naming the vertex type and property.
+//
+// Edges are checked against the Edges rules in the same way, and edges with
+// an inverse are created and deleted along with it.
+//
+// Vertex types are remembered from CreateVertex calls made through the
+// client and otherwise looked up with GetVertices. Writes to vertices that
+// don't exist yet aren't checked. Bulk inserts are passed through unchecked.
+type ValidatingClient struct {
+	GraphClient
+	Schemas *SchemaRegistry
+	// Edges holds the edge rules. If it's nil, edges aren't checked and
+	// inverses aren't maintained.
+	Edges *EdgeRegistry
+	// Strict rejects vertices, property writes and edges of types with no
+	// schema or rules. By default they're passed through.
+	Strict bool
+
+	mu    sync.Mutex
+	types map[uuid.UUID]string
+}
+
+// NewValidatingClient returns a client checking writes to graph against
+// schemas, usually Schemas, and the EdgeRules.
+func NewValidatingClient(graph GraphClient, schemas *SchemaRegistry) *ValidatingClient {
+	return &ValidatingClient{GraphClient: graph, Schemas: schemas, Edges: EdgeRules}
+}
+
+func (c *ValidatingClient) CreateVertex(ctx context.Context, id *pb.Uuid, t *pb.Identifier) error {
+	if err := c.checkType(t.GetValue()); err != nil {
+		return err
+	}
+	if err := c.GraphClient.CreateVertex(ctx, id, t); err != nil {
+		return err
+	}
+	if u, err := uuid.FromBytes(id.GetValue()); err == nil {
+		c.remember(u, t.GetValue())
+	}
+	return nil
+}
+
+func (c *ValidatingClient) CreateVertexFromType(ctx context.Context, t *pb.Identifier) (*pb.Uuid, error) {
+	if err := c.checkType(t.GetValue()); err != nil {
+		return nil, err
+	}
+	id, err := c.GraphClient.CreateVertexFromType(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	if u, err := uuid.FromBytes(id.GetValue()); err == nil {
+		c.remember(u, t.GetValue())
+	}
+	return id, nil
+}
+
+func (c *ValidatingClient) DeleteVertices(ctx context.Context, query *pb.VertexQuery) error {
+	if err := c.GraphClient.DeleteVertices(ctx, query); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	specific := query.GetSpecific()
+	if specific == nil {
+		// There's no telling which vertices a range or pipe query matched.
+		c.types = nil
+		return nil
+	}
+	for _, id := range specific.GetIds() {
+		if u, err := uuid.FromBytes(id.GetValue()); err == nil {
+			delete(c.types, u)
+		}
+	}
+	return nil
+}
+
+func (c *ValidatingClient) SetVertexProperties(ctx context.Context, query *pb.VertexQuery, name string, jsonValue interface{}) error {
+	b, err := json.Marshal(jsonValue)
+	if err != nil {
+		return err
+	}
+	if err := c.check(ctx, query, func(s *VertexSchema) error { return s.CheckProperty(name, b) }); err != nil {
+		return err
+	}
+	return c.GraphClient.SetVertexProperties(ctx, query, name, jsonValue)
+}
+
+// DeleteVertexProperties rejects deleting a property the schema requires.
+func (c *ValidatingClient) DeleteVertexProperties(ctx context.Context, query *pb.VertexQuery, name string) error {
+	if err := c.check(ctx, query, func(s *VertexSchema) error {
+		if p := s.Property(name); p != nil && p.Required {
+			return &ValidationError{Kind: s.Type.GetValue(), Fields: []*FieldError{{Field: name, Problem: "is required and can't be deleted"}}}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return DeleteVertexProperties(ctx, c.GraphClient, query, name)
+}
+
+// CreateEdge checks the edge against the rules for its type and creates it,
+// along with its inverse if it has one.
+func (c *ValidatingClient) CreateEdge(ctx context.Context, outbound *pb.Uuid, t *pb.Identifier, inbound *pb.Uuid) error {
+	if c.Edges == nil {
+		return c.GraphClient.CreateEdge(ctx, outbound, t, inbound)
+	}
+	if err := c.checkEdge(ctx, outbound, t, inbound); err != nil {
+		return err
+	}
+	if err := c.GraphClient.CreateEdge(ctx, outbound, t, inbound); err != nil {
+		return err
+	}
+	if inverse := c.Edges.Inverse(t.GetValue()); inverse != nil {
+		if err := c.GraphClient.CreateEdge(ctx, inbound, inverse, outbound); err != nil {
+			return fmt.Errorf("create inverse %s edge: %w", inverse.GetValue(), err)
+		}
+	}
+	return nil
+}
+
+// DeleteEdges deletes the matching edges along with their inverses.
+func (c *ValidatingClient) DeleteEdges(ctx context.Context, query *pb.EdgeQuery) error {
+	if c.Edges == nil {
+		return c.GraphClient.DeleteEdges(ctx, query)
+	}
+	keys, err := c.inverseKeys(ctx, query)
+	if err != nil {
+		return err
+	}
+	if err := c.GraphClient.DeleteEdges(ctx, query); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		if err := c.GraphClient.DeleteEdges(ctx, NewSpecificEdgeQuery(keys...)); err != nil {
+			return fmt.Errorf("delete inverse edges: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkEdge checks an edge against the rules for its type. Edges whose
+// vertices don't exist yet aren't checked.
+func (c *ValidatingClient) checkEdge(ctx context.Context, outbound *pb.Uuid, t *pb.Identifier, inbound *pb.Uuid) error {
+	if !c.Edges.Declared(t.GetValue()) {
+		if c.Strict {
+			return fmt.Errorf("%w: %q", ErrUnknownEdgeType, t.GetValue())
+		}
+		return nil
+	}
+	types, err := c.vertexTypes(ctx, NewSpecificVertexQuery(outbound, inbound))
+	if err != nil {
+		return err
+	}
+	var source, target string
+	var sourceFound, targetFound bool
+	for _, vt := range types {
+		if bytes.Equal(vt.id[:], outbound.GetValue()) {
+			source, sourceFound = vt.t, true
+		}
+		if bytes.Equal(vt.id[:], inbound.GetValue()) {
+			target, targetFound = vt.t, true
+		}
+	}
+	if !sourceFound || !targetFound {
+		return nil
+	}
+	return c.Edges.Check(source, t.GetValue(), target)
+}
+
+// inverseKeys returns the keys of the inverses of the edges matching query
+// that aren't matched themselves.
+func (c *ValidatingClient) inverseKeys(ctx context.Context, query *pb.EdgeQuery) ([]*pb.EdgeKey, error) {
+	var keys []*pb.EdgeKey
+	if specific := query.GetSpecific(); specific != nil {
+		keys = specific.GetKeys()
+	} else {
+		if t := query.GetPipe().GetT(); t != nil && c.Edges.Inverse(t.GetValue()) == nil {
+			return nil, nil
+		}
+		edges, err := c.GraphClient.GetEdges(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range edges {
+			keys = append(keys, e.GetKey())
+		}
+	}
+	matched := map[string]bool{}
+	for _, k := range keys {
+		matched[edgeKeyString(k.GetOutboundId(), k.GetT(), k.GetInboundId())] = true
+	}
+	var inverses []*pb.EdgeKey
+	for _, k := range keys {
+		inverse := c.Edges.Inverse(k.GetT().GetValue())
+		if inverse == nil {
+			continue
+		}
+		s := edgeKeyString(k.GetInboundId(), inverse, k.GetOutboundId())
+		if matched[s] {
+			continue
+		}
+		matched[s] = true
+		inverses = append(inverses, &pb.EdgeKey{OutboundId: k.GetInboundId(), T: inverse, InboundId: k.GetOutboundId()})
+	}
+	return inverses, nil
+}
+
+func edgeKeyString(outbound *pb.Uuid, t *pb.Identifier, inbound *pb.Uuid) string {
+	return fmt.Sprintf("%x-%s->%x", outbound.GetValue(), t.GetValue(), inbound.GetValue())
+}
+
+// check runs fn against the schema of each vertex matching query.
+func (c *ValidatingClient) check(ctx context.Context, query *pb.VertexQuery, fn func(*VertexSchema) error) error {
+	types, err := c.vertexTypes(ctx, query)
+	if err != nil {
+		return err
+	}
+	for _, vt := range types {
+		if err := c.checkType(vt.t); err != nil {
+			return err
+		}
+		s, err := c.Schemas.Lookup(vt.t)
+		if err != nil {
+			continue
+		}
+		if err := fn(s); err != nil {
+			if verr, ok := err.(*ValidationError); ok {
+				verr.ID = vt.id.String()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ValidatingClient) checkType(t string) error {
+	if !c.Strict {
+		return nil
+	}
+	_, err := c.Schemas.Lookup(t)
+	return err
+}
+
+// maxCachedVertexTypes bounds the number of vertex types a ValidatingClient
+// remembers. The cache is reset when it's full.
+const maxCachedVertexTypes = 10000
+
+type vertexType struct {
+	id uuid.UUID
+	t  string
+}
+
+// vertexTypes returns the ID and type of each existing vertex matching query.
+func (c *ValidatingClient) vertexTypes(ctx context.Context, query *pb.VertexQuery) ([]vertexType, error) {
+	var types []vertexType
+	lookup := query
+	if specific := query.GetSpecific(); specific != nil {
+		var missing []*pb.Uuid
+		c.mu.Lock()
+		for _, id := range specific.GetIds() {
+			u, err := uuid.FromBytes(id.GetValue())
+			if err != nil {
+				c.mu.Unlock()
+				return nil, fmt.Errorf("vertex id: %w", err)
+			}
+			if t, ok := c.types[u]; ok {
+				types = append(types, vertexType{u, t})
+			} else {
+				missing = append(missing, id)
+			}
+		}
+		c.mu.Unlock()
+		if len(missing) == 0 {
+			return types, nil
+		}
+		lookup = NewSpecificVertexQuery(missing...)
+	}
+	vertices, err := c.GraphClient.GetVertices(ctx, lookup)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vertices {
+		u, err := uuid.FromBytes(v.GetId().GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("vertex id: %w", err)
+		}
+		c.remember(u, v.GetT().GetValue())
+		types = append(types, vertexType{u, v.GetT().GetValue()})
+	}
+	return types, nil
+}
+
+func (c *ValidatingClient) remember(id uuid.UUID, t string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.types[id]; !ok && len(c.types) >= maxCachedVertexTypes {
+		c.types = nil
+	}
+	if c.types == nil {
+		c.types = map[uuid.UUID]string{}
+	}
+	c.types[id] = t
+}
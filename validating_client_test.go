@@ -0,0 +1,162 @@
+package citygraph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/geomodulus/citygraph/pb"
+)
+
+func TestValidatingClientSetVertexProperties(t *testing.T) {
+	ctx := context.Background()
+	created, existing := UUID(NewID()), UUID(NewID())
+	fake := &FakeGraphClient{
+		GetVerticesResps: [][]*pb.Vertex{{{Id: existing, T: AnnouncementType}}},
+	}
+	client := NewValidatingClient(fake, Schemas)
+
+	if err := client.CreateVertex(ctx, created, WebLinkType); err != nil {
+		t.Fatalf("client.CreateVertex() returned err: %v", err)
+	}
+	cq := NewSpecificVertexQuery(created)
+	if err := client.SetVertexProperties(ctx, cq, "url", "https://example.com/"); err != nil {
+		t.Errorf("client.SetVertexProperties(url) returned err: %v", err)
+	}
+	err := client.SetVertexProperties(ctx, cq, "pitch", 30)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Kind != WebLinkType.Value || verr.Fields[0].Field != "pitch" {
+		t.Errorf("client.SetVertexProperties(pitch) returned %v, want a web-link pitch error", err)
+	}
+
+	eq := NewSpecificVertexQuery(existing)
+	if err := client.SetVertexProperties(ctx, eq, "severity", "urgent"); !errors.As(err, &verr) || verr.Fields[0].Field != "severity" {
+		t.Errorf("client.SetVertexProperties(severity) returned %v, want a severity error", err)
+	}
+	if err := client.SetVertexProperties(ctx, eq, "severity", SeverityCritical); err != nil {
+		t.Errorf("client.SetVertexProperties(severity) returned err: %v", err)
+	}
+	if err := client.DeleteVertexProperties(ctx, eq, "body_html"); !errors.As(err, &verr) {
+		t.Errorf("client.DeleteVertexProperties(body_html) returned %v, want *ValidationError", err)
+	}
+	if err := client.DeleteVertexProperties(ctx, eq, "link"); err != nil {
+		t.Errorf("client.DeleteVertexProperties(link) returned err: %v", err)
+	}
+
+	// The existing vertex's type is looked up once, and only valid writes
+	// reach the graph.
+	wantGetVerticesReqs := []*pb.VertexQuery{eq}
+	if diff := cmp.Diff(wantGetVerticesReqs, fake.GetVerticesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("client sent get vertices req diff:\n%s\n", diff)
+	}
+	var got []string
+	for _, req := range fake.SetVertexPropertiesReqs {
+		got = append(got, req.GetQ().GetName().GetValue())
+	}
+	if diff := cmp.Diff([]string{"url", "severity"}, got); diff != "" {
+		t.Errorf("client set properties diff:\n%s\n", diff)
+	}
+	if len(fake.DeleteVertexPropertiesReqs) != 1 {
+		t.Errorf("client sent %d delete vertex properties reqs, want 1", len(fake.DeleteVertexPropertiesReqs))
+	}
+}
+
+func TestValidatingClientStrict(t *testing.T) {
+	ctx := context.Background()
+	fake := &FakeGraphClient{}
+	client := NewValidatingClient(fake, Schemas)
+	id := UUID(NewID())
+
+	unknown := &pb.Identifier{Value: "no-such-type"}
+	client.Strict = true
+	if err := client.CreateVertex(ctx, id, unknown); !errors.Is(err, ErrUnknownVertexType) {
+		t.Errorf("client.CreateVertex() returned %v, want ErrUnknownVertexType", err)
+	}
+	client.Strict = false
+	if err := client.CreateVertex(ctx, id, unknown); err != nil {
+		t.Fatalf("client.CreateVertex() returned err: %v", err)
+	}
+	if err := client.SetVertexProperties(ctx, NewSpecificVertexQuery(id), "anything", 1); err != nil {
+		t.Errorf("client.SetVertexProperties() on a type with no schema returned err: %v", err)
+	}
+}
+
+func TestValidatingClientEdges(t *testing.T) {
+	ctx := context.Background()
+	publisher, article, category := UUID(NewID()), UUID(NewID()), UUID(NewID())
+	fake := &FakeGraphClient{
+		GetVerticesResps: [][]*pb.Vertex{{
+			{Id: publisher, T: &NewsPublisher},
+			{Id: article, T: ArticleType},
+		}, {
+			{Id: category, T: &NewsCategory},
+		}},
+	}
+	client := NewValidatingClient(fake, Schemas)
+
+	if err := client.CreateEdge(ctx, publisher, &Published, article); err != nil {
+		t.Fatalf("client.CreateEdge(published) returned err: %v", err)
+	}
+	if err := client.CreateEdge(ctx, article, &Published, publisher); !errors.Is(err, ErrEdgeNotAllowed) {
+		t.Errorf("client.CreateEdge(article -published-> publisher) returned %v, want ErrEdgeNotAllowed", err)
+	}
+	if err := client.CreateEdge(ctx, category, &ItemAbout, article); !errors.Is(err, ErrEdgeNotAllowed) {
+		t.Errorf("client.CreateEdge(category -item-about-> article) returned %v, want ErrEdgeNotAllowed", err)
+	}
+	published := &pb.EdgeKey{OutboundId: publisher, T: &Published, InboundId: article}
+	publishedBy := &pb.EdgeKey{OutboundId: article, T: &PublishedBy, InboundId: publisher}
+	if diff := cmp.Diff([]*pb.EdgeKey{published, publishedBy}, fake.CreateEdgeReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("client sent create edge req diff:\n%s\n", diff)
+	}
+
+	// Deleting one of a pair by key deletes the other too; deleting both
+	// doesn't delete either twice.
+	if err := client.DeleteEdges(ctx, NewSpecificEdgeQuery(publishedBy)); err != nil {
+		t.Fatalf("client.DeleteEdges() returned err: %v", err)
+	}
+	if err := client.DeleteEdges(ctx, NewSpecificEdgeQuery(published, publishedBy)); err != nil {
+		t.Fatalf("client.DeleteEdges() returned err: %v", err)
+	}
+	// Deleting by pipe query looks up the matching edges first.
+	fake.GetEdgesResps = [][]*pb.Edge{{{Key: published}}}
+	pipe := NewPipeEdgeQuery(NewSpecificVertexQuery(article), pb.EdgeDirection_INBOUND, &Published)
+	if err := client.DeleteEdges(ctx, pipe); err != nil {
+		t.Fatalf("client.DeleteEdges() returned err: %v", err)
+	}
+	wantDeleteEdgesReqs := []*pb.EdgeQuery{
+		NewSpecificEdgeQuery(publishedBy),
+		NewSpecificEdgeQuery(published),
+		NewSpecificEdgeQuery(published, publishedBy),
+		pipe,
+		NewSpecificEdgeQuery(publishedBy),
+	}
+	if diff := cmp.Diff(wantDeleteEdgesReqs, fake.DeleteEdgesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("client sent delete edges req diff:\n%s\n", diff)
+	}
+}
+
+func TestValidatingClientDeleteVertices(t *testing.T) {
+	ctx := context.Background()
+	id := UUID(NewID())
+	fake := &FakeGraphClient{GetVerticesResps: [][]*pb.Vertex{{}}}
+	client := NewValidatingClient(fake, Schemas)
+
+	if err := client.CreateVertex(ctx, id, WebLinkType); err != nil {
+		t.Fatalf("client.CreateVertex() returned err: %v", err)
+	}
+	if err := client.DeleteVertices(ctx, NewRangeVertexQuery(WebLinkType)); err != nil {
+		t.Fatalf("client.DeleteVertices() returned err: %v", err)
+	}
+	// The range delete forgets the cached type, so the write looks the
+	// vertex up again, finds it gone and passes the write through.
+	q := NewSpecificVertexQuery(id)
+	if err := client.SetVertexProperties(ctx, q, "pitch", 30); err != nil {
+		t.Errorf("client.SetVertexProperties() on a deleted vertex returned err: %v", err)
+	}
+	if diff := cmp.Diff([]*pb.VertexQuery{q}, fake.GetVerticesReqs, protocmp.Transform()); diff != "" {
+		t.Errorf("client sent get vertices req diff:\n%s\n", diff)
+	}
+}
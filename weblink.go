@@ -0,0 +1,219 @@
+package citygraph
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/html"
+
+	"github.com/geomodulus/citygraph/pb"
+)
+
+// WebLinkType identifies web link vertices: pages on other sites that the
+// live feed points readers to.
+var WebLinkType = &pb.Identifier{Value: "web-link"}
+
+// trackingParams are query parameters dropped when canonicalizing a URL.
+// Parameters starting with "utm_" are dropped too.
+var trackingParams = map[string]bool{
+	"fbclid": true,
+	"gclid":  true,
+	"mc_cid": true,
+	"mc_eid": true,
+}
+
+// WebLink is a page on another site. Its ID is derived from its canonical URL,
+// so writing the same page twice, however it was linked, writes one vertex.
+type WebLink struct {
+	ID          string `json:"id"`
+	URL         string `json:"url"`
+	Title       string `json:"display_name"`
+	Description string `json:"h2,omitempty"`
+	ImageURL    string `json:"img_url,omitempty"`
+	// Source is the name of the publication the page belongs to.
+	Source string `json:"source,omitempty"`
+	// OpenGraph holds the page's og: meta properties, keyed by property
+	// name without the "og:" prefix.
+	OpenGraph map[string]string `json:"opengraph,omitempty"`
+}
+
+// NewWebLink returns a link to the page at rawURL, with its URL canonicalized
+// and its ID set.
+func NewWebLink(rawURL string) (*WebLink, error) {
+	link := &WebLink{URL: rawURL}
+	if err := link.Canonicalize(); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// CanonicalizeURL returns rawURL in a form that's the same for every way of
+// writing the same page: the scheme and host are lower case, default ports,
+// fragments and tracking parameters are dropped, the remaining query
+// parameters are sorted, and an empty path becomes "/".
+func CanonicalizeURL(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%q is not an absolute http(s) URL", rawURL)
+	}
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if port == "" || (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = host
+	} else {
+		u.Host = host + ":" + port
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.User = nil
+	u.Fragment, u.RawFragment = "", ""
+
+	query := u.Query()
+	for name := range query {
+		if trackingParams[strings.ToLower(name)] || strings.HasPrefix(strings.ToLower(name), "utm_") {
+			query.Del(name)
+		}
+	}
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	u.RawQuery = query.Encode()
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+// WebLinkID returns the ID of the web link with the given canonical URL.
+func WebLinkID(canonicalURL string) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(canonicalURL))
+}
+
+// Canonicalize canonicalizes the link's URL and sets its ID to match.
+func (w *WebLink) Canonicalize() error {
+	canonical, err := CanonicalizeURL(w.URL)
+	if err != nil {
+		return err
+	}
+	w.URL = canonical
+	w.ID = WebLinkID(canonical).String()
+	return nil
+}
+
+func (w *WebLink) UUID() (uuid.UUID, error) {
+	id, err := uuid.Parse(w.ID)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return id, nil
+}
+
+func (w *WebLink) VertexQuery() (*pb.VertexQuery, error) {
+	id, err := w.UUID()
+	if err != nil {
+		return nil, err
+	}
+	return NewSpecificVertexQuery(UUID(id)), nil
+}
+
+// ParseOpenGraph reads the page's HTML and records its OpenGraph metadata.
+// Fields that are already set are kept; empty ones are filled from og:title,
+// og:description, og:image and og:site_name, falling back to the page's
+// <title> and description meta tag. If the page declares a canonical URL,
+// with og:url or <link rel="canonical">, the link takes that URL and ID.
+func (w *WebLink) ParseOpenGraph(r io.Reader) error {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return err
+	}
+	var title, description, canonical string
+	og := map[string]string{}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "title":
+				if title == "" && n.FirstChild != nil && n.FirstChild.Type == html.TextNode {
+					title = strings.TrimSpace(n.FirstChild.Data)
+				}
+			case "meta":
+				property, name, content := attr(n, "property"), attr(n, "name"), attr(n, "content")
+				if strings.HasPrefix(property, "og:") {
+					if _, ok := og[property[3:]]; !ok {
+						og[property[3:]] = strings.TrimSpace(content)
+					}
+				} else if strings.EqualFold(name, "description") && description == "" {
+					description = strings.TrimSpace(content)
+				}
+			case "link":
+				if strings.EqualFold(attr(n, "rel"), "canonical") && canonical == "" {
+					canonical = attr(n, "href")
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	if len(og) > 0 {
+		w.OpenGraph = og
+	}
+	// Relative references are resolved against the page as fetched, before
+	// the link takes the page's canonical URL.
+	if w.ImageURL == "" && og["image"] != "" {
+		if u, err := w.resolve(og["image"]); err == nil {
+			w.ImageURL = u
+		}
+	}
+	if og["url"] != "" {
+		canonical = og["url"]
+	}
+	if canonical != "" {
+		if u, err := w.resolve(canonical); err == nil {
+			if c, err := CanonicalizeURL(u); err == nil {
+				w.URL, w.ID = c, WebLinkID(c).String()
+			}
+		}
+	}
+	fill := func(field *string, values ...string) {
+		for _, v := range values {
+			if *field == "" {
+				*field = v
+			}
+		}
+	}
+	fill(&w.Title, og["title"], title)
+	fill(&w.Description, og["description"], description)
+	fill(&w.Source, og["site_name"])
+	return nil
+}
+
+// resolve returns ref resolved against the link's URL.
+func (w *WebLink) resolve(ref string) (string, error) {
+	base, err := url.Parse(w.URL)
+	if err != nil {
+		return "", err
+	}
+	u, err := base.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}